@@ -6,6 +6,9 @@ package v8go
 
 /* #include "v8go.h" */
 import "C"
+import (
+	"runtime"
+)
 
 // Array is a JavaScript Array object, a subtype of Object.
 type Array struct {
@@ -28,3 +31,67 @@ func (c *Context) NewArray(length int) *Array {
 func (a *Array) Length() uint32 {
 	return uint32(C.ArrayLength(a.valuePtr()))
 }
+
+// Push appends the values to the end of the array, as `Array.prototype.push` does in JS.
+// The values may be Values or any Go type that can be passed to NewValue.
+func (a *Array) Push(vals ...interface{}) error {
+	args := make([]Valuer, len(vals))
+	for i, val := range vals {
+		v, err := a.ctx.NewValue(val)
+		if err != nil {
+			return err
+		}
+		args[i] = v
+	}
+	cArgs, argptr := convertArgs(args)
+	rtn := C.ArrayPush(a.valuePtr(), C.int(len(args)), argptr)
+	runtime.KeepAlive(cArgs)
+	if rtn.msg != nil {
+		return newJSError(a.ctx.iso, rtn)
+	}
+	return nil
+}
+
+// Slice returns the elements of the array from index start up to, but not including,
+// index end, which is capped at the array's length. The elements are fetched together,
+// which is faster than calling GetIdx for each.
+// error will be of type `JSError` if getting an element throws.
+func (a *Array) Slice(start, end uint32) ([]*Value, error) {
+	if length := a.Length(); end > length {
+		end = length
+	}
+	if start >= end {
+		return []*Value{}, nil
+	}
+	refs := make([]C.ValueRef, end-start)
+	rtn := C.ArrayGetValues(a.valuePtr(), C.uint32_t(start), C.uint32_t(end), &refs[0])
+	if rtn.msg != nil {
+		return nil, newJSError(a.ctx.iso, rtn)
+	}
+	vals := make([]*Value, len(refs))
+	for i, ref := range refs {
+		vals[i] = &Value{ref, a.ctx}
+	}
+	return vals, nil
+}
+
+// Values returns all the elements of the array; see Slice.
+func (a *Array) Values() ([]*Value, error) {
+	return a.Slice(0, a.Length())
+}
+
+// ToGoSlice converts the array to a Go slice, converting its elements deeply as
+// described for Value.Export.
+func (a *Array) ToGoSlice() ([]interface{}, error) {
+	gv, err := a.Value.export(nil)
+	if err != nil {
+		return nil, err
+	}
+	return gv.([]interface{}), nil
+}
+
+// values returns the elements of an array that V8 created, which can't throw.
+func (a *Array) values() []*Value {
+	vals, _ := a.Values()
+	return vals
+}
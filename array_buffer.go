@@ -0,0 +1,68 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+)
+
+// ArrayBuffer is a JavaScript ArrayBuffer, a subtype of Object.
+type ArrayBuffer struct {
+	*Object
+}
+
+// NewArrayBuffer creates an ArrayBuffer of the given size, filled with zeros.
+func (c *Context) NewArrayBuffer(byteLength int) *ArrayBuffer {
+	return &ArrayBuffer{&Object{&Value{C.NewArrayBuffer(c.ptr, C.size_t(byteLength)), c}}}
+}
+
+// AsArrayBuffer returns the value as an ArrayBuffer, or an error if it is not an ArrayBuffer.
+func (v *Value) AsArrayBuffer() (*ArrayBuffer, error) {
+	if !v.IsArrayBuffer() {
+		return nil, errors.New("v8go: value is not an ArrayBuffer")
+	}
+	return &ArrayBuffer{&Object{v}}, nil
+}
+
+// ByteLength returns the size of the buffer in bytes, which is 0 once it is detached.
+func (b *ArrayBuffer) ByteLength() int {
+	return int(C.ArrayBufferByteLength(b.valuePtr()))
+}
+
+// Bytes returns the buffer's memory; see Value.ArrayBufferBytes.
+func (b *ArrayBuffer) Bytes() []byte {
+	bytes, _ := b.ArrayBufferBytes()
+	return bytes
+}
+
+// Detach detaches the buffer, as transferring it to a worker would, so that its memory
+// can't be accessed any more and its ByteLength becomes 0. It returns an error if the buffer
+// can't be detached, e.g. because it is the memory of a WebAssembly instance.
+func (b *ArrayBuffer) Detach() error {
+	if C.ArrayBufferDetach(b.valuePtr()) == 0 {
+		return errors.New("v8go: ArrayBuffer is not detachable")
+	}
+	return nil
+}
+
+// ArrayBufferBytes returns the memory of an ArrayBuffer or SharedArrayBuffer, or the part
+// of one that an ArrayBufferView, such as a Uint8Array or DataView, views, without copying
+// it. Changes made by JavaScript are visible in the returned slice, and vice versa.
+//
+// The slice is only valid as long as the buffer is: it must not be used after the buffer is
+// detached, e.g. by ArrayBuffer.Detach or TypedArray.Release, or after the Context is closed.
+// It returns an error if the value is not an ArrayBuffer, SharedArrayBuffer or ArrayBufferView.
+func (v *Value) ArrayBufferBytes() ([]byte, error) {
+	if !v.IsArrayBuffer() && !v.IsSharedArrayBuffer() && !v.IsArrayBufferView() {
+		return nil, errors.New("v8go: value is not an ArrayBuffer or ArrayBufferView")
+	}
+	rtn := C.ArrayBufferContents(v.valuePtr())
+	if rtn.data == nil {
+		return []byte{}, nil
+	}
+	return (*[1 << 34]byte)(rtn.data)[:rtn.length:rtn.length], nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"bytes"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestArrayBuffer(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("globalThis.buf = new Uint8Array([1, 2, 3, 4]).buffer; buf", "test.js")
+	fatalIf(t, err)
+	buf, err := val.AsArrayBuffer()
+	fatalIf(t, err)
+	if n := buf.ByteLength(); n != 4 {
+		t.Errorf("unexpected byte length: %d", n)
+	}
+	data := buf.Bytes()
+	if !bytes.Equal(data, []byte{1, 2, 3, 4}) {
+		t.Errorf("unexpected bytes: %v", data)
+	}
+	data[0] = 9
+	val, err = ctx.RunScript("new Uint8Array(buf)[0]", "test.js")
+	fatalIf(t, err)
+	if val.Int32() != 9 {
+		t.Errorf("expected JS to see the change, got %v", val)
+	}
+
+	fatalIf(t, buf.Detach())
+	if n := buf.ByteLength(); n != 0 {
+		t.Errorf("expected a detached buffer to be empty, got %d bytes", n)
+	}
+	if data := buf.Bytes(); len(data) != 0 {
+		t.Errorf("expected no bytes, got %v", data)
+	}
+
+	if _, err := ctx.Global().Value.AsArrayBuffer(); err == nil {
+		t.Error("expected an error for an object that is not an ArrayBuffer")
+	}
+}
+
+func TestValueArrayBufferBytes(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		source string
+		want   []byte
+	}{
+		{"new Uint8Array([104, 105, 33])", []byte("hi!")},
+		{"new Uint8Array([1, 2, 3, 4, 5]).subarray(1, 3)", []byte{2, 3}},
+		{"new Uint16Array([0x0102])", []byte{2, 1}},
+		{"new DataView(new Uint8Array([7, 8, 9]).buffer, 2)", []byte{9}},
+		{"new ArrayBuffer(0)", []byte{}},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.source, "test.js")
+		fatalIf(t, err)
+		got, err := val.ArrayBufferBytes()
+		fatalIf(t, err)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.source, tt.want, got)
+		}
+	}
+
+	val, _ := ctx.RunScript("[1, 2]", "test.js")
+	if _, err := val.ArrayBufferBytes(); err == nil {
+		t.Error("expected an error for an Array")
+	}
+}
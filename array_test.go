@@ -0,0 +1,92 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"reflect"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestArrayPush(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	arr := ctx.NewArray(1)
+	fatalIf(t, arr.Push("a", int32(2), ctx.NewObject()))
+	fatalIf(t, arr.Push())
+	if l := arr.Length(); l != 4 {
+		t.Errorf("unexpected length: %d", l)
+	}
+	val, err := arr.GetIdx(2)
+	fatalIf(t, err)
+	if val.Int32() != 2 {
+		t.Errorf("unexpected element: %v", val)
+	}
+
+	val, _ = ctx.RunScript("Object.freeze([])", "test.js")
+	frozen, _ := val.AsArray()
+	if err := frozen.Push(1.5); err == nil {
+		t.Error("expected an error pushing to a frozen array")
+	}
+}
+
+func TestArraySlice(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("[0, 1, 'two', [3], , 5]", "test.js")
+	fatalIf(t, err)
+	arr, err := val.AsArray()
+	fatalIf(t, err)
+
+	vals, err := arr.Values()
+	fatalIf(t, err)
+	if len(vals) != 6 || vals[2].String() != "two" || !vals[3].IsArray() || !vals[4].IsUndefined() {
+		t.Errorf("unexpected values: %v", vals)
+	}
+
+	tests := [...]struct {
+		start, end uint32
+		want       []string
+	}{
+		{1, 3, []string{"1", "two"}},
+		{4, 100, []string{"undefined", "5"}},
+		{3, 3, []string{}},
+		{7, 2, []string{}},
+	}
+	for _, tt := range tests {
+		vals, err := arr.Slice(tt.start, tt.end)
+		fatalIf(t, err)
+		got := make([]string, len(vals))
+		for i, v := range vals {
+			got[i] = v.String()
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Slice(%d, %d): expected %v, got %v", tt.start, tt.end, tt.want, got)
+		}
+	}
+
+	goVals, err := arr.ToGoSlice()
+	fatalIf(t, err)
+	want := []interface{}{0.0, 1.0, "two", []interface{}{3.0}, nil, 5.0}
+	if !reflect.DeepEqual(goVals, want) {
+		t.Errorf("unexpected Go slice: %#v", goVals)
+	}
+
+	val, _ = ctx.RunScript("const a = [1]; Object.defineProperty(a, 1, {get() { throw new Error('oops') }}); a", "test.js")
+	arr, _ = val.AsArray()
+	if _, err := arr.Values(); err == nil {
+		t.Error("expected the getter's error")
+	}
+	if _, err := arr.ToGoSlice(); err == nil {
+		t.Error("expected the getter's error")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"context"
+	"fmt"
+)
+
+// AsyncFunctionCallback is the Go implementation of a function created by
+// NewAsyncFunctionTemplate. It runs in its own goroutine, so it must not use the Isolate;
+// instead its arguments are converted to Go values as by Value.Export, and its result is
+// converted to a JS value as by NewValueOf. If it returns an error, or panics, the call's
+// promise is rejected with an Error with the error's message.
+type AsyncFunctionCallback func(args []interface{}) (interface{}, error)
+
+// asyncResult is the outcome of an AsyncFunctionCallback, waiting to settle its promise.
+type asyncResult struct {
+	ctx      *Context
+	resolver *Persistent // The call's PromiseResolver
+	value    interface{}
+	err      error
+}
+
+// NewAsyncFunctionTemplate creates a FunctionTemplate for a function that returns a promise
+// and calls callback in a new goroutine, without blocking the Isolate. When the callback
+// returns, its result is queued and the promise is settled on the Isolate's thread the next
+// time Context.PerformMicrotaskCheckpoint, Context.Await, Isolate.PumpMessageLoop or
+// Isolate.AwaitAsyncCalls is called; the latter two wait for callbacks to finish. (Other
+// calls, such as Context.RunScript, don't settle them, as they can be made by a Go callback
+// while JS is running, and the promises' reactions must not run until the JS completes.)
+func NewAsyncFunctionTemplate(iso *Isolate, callback AsyncFunctionCallback) *FunctionTemplate {
+	if callback == nil {
+		panic("nil AsyncFunctionCallback argument not supported")
+	}
+	return NewFunctionTemplate(iso, func(info *FunctionCallbackInfo) *Value {
+		ctx := info.Context()
+		resolver, err := NewPromiseResolver(ctx)
+		if err != nil {
+			return iso.ThrowException(ctx.newError(C.Error_err, err.Error()).Value)
+		}
+		args := make([]interface{}, len(info.Args()))
+		for i, arg := range info.Args() {
+			if args[i], err = arg.Export(); err != nil {
+				resolver.Reject(ctx.newError(C.TypeError_err, fmt.Sprintf("argument %d: %v", i+1, err)).Value)
+				return resolver.GetPromise().Value
+			}
+		}
+
+		result := &asyncResult{ctx: ctx, resolver: NewPersistent(resolver)}
+		iso.asyncMutex.Lock()
+		iso.asyncPending++
+		iso.asyncMutex.Unlock()
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					result.err = fmt.Errorf("Go panic: %v", r)
+				}
+				iso.queueAsyncResult(result)
+			}()
+			result.value, result.err = callback(args)
+		}()
+		return resolver.GetPromise().Value
+	})
+}
+
+// RegisterAsyncFunction makes a function created by NewAsyncFunctionTemplate a property of the
+// context's global object.
+func (c *Context) RegisterAsyncFunction(name string, callback AsyncFunctionCallback) error {
+	return c.Global().Set(name, NewAsyncFunctionTemplate(c.iso, callback).GetFunction(c))
+}
+
+func (i *Isolate) queueAsyncResult(result *asyncResult) {
+	i.asyncMutex.Lock()
+	i.asyncResults = append(i.asyncResults, result)
+	i.asyncMutex.Unlock()
+	select {
+	case i.asyncReady <- struct{}{}:
+	default:
+	}
+}
+
+// settleAsyncResults settles the promises of the async calls whose callbacks have finished,
+// then runs the microtasks that reactions to them queued.
+func (i *Isolate) settleAsyncResults() {
+	i.asyncMutex.Lock()
+	results := i.asyncResults
+	i.asyncResults = nil
+	i.asyncPending -= len(results)
+	i.asyncMutex.Unlock()
+	if len(results) == 0 {
+		return
+	}
+	for _, r := range results {
+		if r.ctx.ptr != nil { // (else the Context has been closed)
+			resolver := &PromiseResolver{&Object{r.resolver.Value(r.ctx)}, nil}
+			val, err := NewValueOf(r.ctx, r.value)
+			if r.err == nil && err != nil {
+				r.err = err
+			}
+			if r.err != nil {
+				resolver.Reject(r.ctx.newError(C.Error_err, r.err.Error()).Value)
+			} else {
+				resolver.Resolve(val)
+			}
+		}
+		r.resolver.Release()
+	}
+	C.IsolatePerformMicrotaskCheckpoint(i.ptr)
+}
+
+// AwaitAsyncCalls waits for the callbacks of calls to async functions (see
+// NewAsyncFunctionTemplate) to finish, settling their promises and running the reactions to
+// them, until no calls are pending, including any made by those reactions. It returns early
+// with ctx's error if ctx is done first.
+func (i *Isolate) AwaitAsyncCalls(ctx context.Context) error {
+	for {
+		i.settleAsyncResults()
+		i.asyncMutex.Lock()
+		pending := i.asyncPending
+		i.asyncMutex.Unlock()
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-i.asyncReady:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
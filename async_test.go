@@ -0,0 +1,99 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestAsyncFunction(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	release := make(chan struct{})
+	fatalIf(t, ctx.RegisterAsyncFunction("fetchUser", func(args []interface{}) (interface{}, error) {
+		<-release
+		id := args[0].(float64)
+		if id < 0 {
+			return nil, errors.New("no such user")
+		}
+		return map[string]interface{}{"id": id, "name": fmt.Sprintf("user%v", id)}, nil
+	}))
+	fatalIf(t, ctx.RegisterAsyncFunction("explode", func(args []interface{}) (interface{}, error) {
+		panic("boom")
+	}))
+
+	val, err := ctx.RunScript(`
+		var log = [];
+		(async () => {
+			const user = await fetchUser(7);
+			log.push(user.name);
+			try { await fetchUser(-1) } catch (e) { log.push(e.message) }
+			try { await explode() } catch (e) { log.push(e.message) }
+			try { await fetchUser(() => {}) } catch (e) { log.push(e.name) }
+		})()`, "")
+	fatalIf(t, err)
+	promise, err := val.AsPromise()
+	fatalIf(t, err)
+	if promise.State() != v8.Pending {
+		t.Fatal("expected the script not to block on the callback")
+	}
+
+	close(release)
+	timeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fatalIf(t, iso.AwaitAsyncCalls(timeout))
+	if promise.State() != v8.Fulfilled {
+		t.Fatalf("expected the script to finish, got state %v", promise.State())
+	}
+	val, err = ctx.RunScript(`log.join()`, "")
+	fatalIf(t, err)
+	if val.String() != "user7,no such user,Go panic: boom,TypeError" {
+		t.Errorf("unexpected log %q", val)
+	}
+}
+
+func TestAsyncFunctionSettledByCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	fatalIf(t, ctx.RegisterAsyncFunction("work", func(args []interface{}) (interface{}, error) {
+		return "done", nil
+	}))
+	_, err := ctx.RunScript(`var result; work().then(r => result = r)`, "")
+	fatalIf(t, err)
+	iso.AwaitAsyncResult()
+
+	// RunScript doesn't settle the promise, since it could be called while JS is running.
+	val, err := ctx.RunScript(`result`, "")
+	fatalIf(t, err)
+	if !val.IsUndefined() {
+		t.Errorf("expected the promise not to be settled by RunScript, got %q", val)
+	}
+	ctx.PerformMicrotaskCheckpoint()
+	val, err = ctx.RunScript(`result`, "")
+	fatalIf(t, err)
+	if val.String() != "done" {
+		t.Errorf("expected the promise to be settled by PerformMicrotaskCheckpoint, got %q", val)
+	}
+
+	timeout, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	fatalIf(t, iso.AwaitAsyncCalls(timeout)) // (nothing pending)
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unicode"
+)
+
+// StructTemplate is an ObjectTemplate, created by BindStruct, whose instances are backed by
+// Go structs.
+type StructTemplate struct {
+	*ObjectTemplate
+	ptrType reflect.Type
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// BindStruct creates a template for JS objects backed by Go structs of the type of target,
+// which may be a struct, a pointer to one, or the reflect.Type of either. Instances are
+// created with StructTemplate.NewInstance.
+//
+// Each exported field becomes an accessor property that reads or writes the field of the
+// backing struct, converting its value as NewValueOf and Value.Unmarshal do. Properties are
+// named as NewValueOf names them, following `v8` or `json` field tags; in addition, the tag
+// option `readonly`, as in `v8:"id,readonly"`, makes a property read-only.
+//
+// Each exported method of the struct's pointer type becomes a (non-enumerable) function with
+// the same name, unless a field has that name. Its arguments are converted to the method's
+// parameter types with Value.Unmarshal, and its result with NewValueOf. A method may return
+// nothing, a value, an error, or a value and an error; a non-nil error is thrown as a JS
+// Error. Methods with other results are not bound.
+//
+// The accessors and methods throw a TypeError if their `this` is not an instance of the
+// template, or if a value can't be converted.
+func BindStruct(iso *Isolate, target interface{}) (*StructTemplate, error) {
+	var t reflect.Type
+	if rt, ok := target.(reflect.Type); ok {
+		t = rt
+	} else {
+		t = reflect.TypeOf(target)
+	}
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("v8go: BindStruct target must be a struct, a pointer to a struct, or its reflect.Type")
+	}
+
+	st := &StructTemplate{NewObjectTemplate(iso), reflect.PtrTo(t)}
+	st.SetInternalFieldCount(1)
+
+	fieldNames := map[string]bool{}
+	for _, field := range structFields(t) {
+		fieldNames[field.name] = true
+		getter := NewFunctionTemplate(iso, st.fieldGetter(field))
+		var setter *FunctionTemplate
+		if !field.readOnly {
+			setter = NewFunctionTemplate(iso, st.fieldSetter(field))
+		}
+		st.SetAccessorProperty(field.name, getter, setter)
+	}
+
+	for i := 0; i < st.ptrType.NumMethod(); i++ {
+		method := st.ptrType.Method(i)
+		if fieldNames[method.Name] || !bindableResults(method.Type) {
+			continue
+		}
+		fn := NewFunctionTemplate(iso, st.methodCallback(method))
+		if err := st.Set(method.Name, fn, DontEnum); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// NewInstance creates an object backed by target, which must be a non-nil pointer to a struct
+// of the type the template was created for. Assignments to the object's properties change
+// the fields of *target, and changes to the fields are visible through the properties.
+// target is kept alive until the object is garbage collected.
+func (st *StructTemplate) NewInstance(ctx *Context, target interface{}) (*Object, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Type() != st.ptrType || rv.IsNil() {
+		return nil, fmt.Errorf("v8go: StructTemplate.NewInstance target must be a non-nil %v", st.ptrType)
+	}
+	obj, err := st.ObjectTemplate.NewInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obj.SetInternalGoValue(0, target)
+	return obj, nil
+}
+
+// receiver returns the struct pointer backing `this`, or false if it isn't an instance.
+func (st *StructTemplate) receiver(this *Object) (reflect.Value, bool) {
+	if this.InternalFieldCount() < 1 {
+		return reflect.Value{}, false
+	}
+	rv := reflect.ValueOf(this.GetInternalGoValue(0))
+	if !rv.IsValid() || rv.Type() != st.ptrType {
+		return reflect.Value{}, false
+	}
+	return rv, true
+}
+
+func (st *StructTemplate) fieldGetter(field structField) FunctionCallback {
+	return func(info *FunctionCallbackInfo) *Value {
+		ctx := info.Context()
+		rv, ok := st.receiver(info.This())
+		if !ok {
+			return ctx.throwError(C.TypeError_err, "Illegal invocation")
+		}
+		fv, ok := fieldByIndex(rv.Elem(), field.index)
+		if !ok {
+			return nil // (in a nil embedded struct)
+		}
+		val, err := NewValueOf(ctx, fv.Interface())
+		if err != nil {
+			return ctx.throwError(C.TypeError_err, err.Error())
+		}
+		return val
+	}
+}
+
+func (st *StructTemplate) fieldSetter(field structField) FunctionCallback {
+	return func(info *FunctionCallbackInfo) *Value {
+		ctx := info.Context()
+		rv, ok := st.receiver(info.This())
+		if !ok {
+			return ctx.throwError(C.TypeError_err, "Illegal invocation")
+		}
+		fv, ok := fieldByIndex(rv.Elem(), field.index)
+		if !ok || !fv.CanSet() {
+			return ctx.throwError(C.TypeError_err, fmt.Sprintf("Cannot set property %s", field.name))
+		}
+		arg := Undefined(ctx.iso)
+		if args := info.Args(); len(args) > 0 {
+			arg = args[0]
+		}
+		nv := reflect.New(fv.Type())
+		if err := arg.Unmarshal(nv.Interface()); err != nil {
+			return ctx.throwError(C.TypeError_err, fmt.Sprintf("Cannot set property %s: %v", field.name, err))
+		}
+		fv.Set(nv.Elem())
+		return nil
+	}
+}
+
+// bindableResults returns true if a method's results are nothing, a value, an error, or a
+// value and an error.
+func bindableResults(ft reflect.Type) bool {
+	switch ft.NumOut() {
+	case 0, 1:
+		return true
+	case 2:
+		return ft.Out(1) == errorType
+	}
+	return false
+}
+
+func (st *StructTemplate) methodCallback(method reflect.Method) FunctionCallback {
+	return func(info *FunctionCallbackInfo) *Value {
+		rv, ok := st.receiver(info.This())
+		if !ok {
+			return info.Context().throwError(C.TypeError_err, "Illegal invocation")
+		}
+		return callGoFunc(info.Context(), rv.Method(method.Index), method.Name, info.Args())
+	}
+}
+
+// callGoFunc calls a Go function from a FunctionCallback, converting the arguments to its
+// parameter types with Value.Unmarshal and its result with NewValueOf. Missing arguments are
+// undefined. If the function's last result is a non-nil error, it's thrown as a JS Error.
+// If it has several other results, they're returned as an array.
+func callGoFunc(ctx *Context, fn reflect.Value, name string, args []*Value) *Value {
+	ft := fn.Type()
+	nFixed := ft.NumIn()
+	if ft.IsVariadic() {
+		nFixed--
+	}
+	in := make([]reflect.Value, 0, nFixed)
+	for i := 0; i < nFixed || (ft.IsVariadic() && i < len(args)); i++ {
+		var pt reflect.Type
+		if i < nFixed {
+			pt = ft.In(i)
+		} else {
+			pt = ft.In(nFixed).Elem()
+		}
+		arg := Undefined(ctx.iso)
+		if i < len(args) {
+			arg = args[i]
+		}
+		nv := reflect.New(pt)
+		if err := arg.Unmarshal(nv.Interface()); err != nil {
+			return ctx.throwError(C.TypeError_err, fmt.Sprintf("%s argument %d: %v", name, i+1, err))
+		}
+		in = append(in, nv.Elem())
+	}
+
+	out := fn.Call(in)
+	if n := len(out); n > 0 && ft.Out(n-1) == errorType {
+		if err, _ := out[n-1].Interface().(error); err != nil {
+			return ctx.throwError(C.Error_err, err.Error())
+		}
+		out = out[:n-1]
+	}
+	var result interface{}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		result = out[0].Interface()
+	default:
+		results := make([]interface{}, len(out))
+		for i, o := range out {
+			results[i] = o.Interface()
+		}
+		result = results
+	}
+	val, err := NewValueOf(ctx, result)
+	if err != nil {
+		return ctx.throwError(C.TypeError_err, fmt.Sprintf("%s result: %v", name, err))
+	}
+	return val
+}
+
+// BindFunc creates a FunctionTemplate for a function that calls fn, which may be any Go
+// func. Arguments are converted to fn's parameter types with Value.Unmarshal, and missing
+// ones are undefined; extra arguments are passed to a variadic parameter. If fn's last
+// result is an error, a non-nil one is thrown as a JS Error. The other results are
+// converted with NewValueOf: none is undefined, one is returned as is, and several are
+// returned as an array. It returns an error if fn isn't a func.
+func BindFunc(iso *Isolate, fn interface{}) (*FunctionTemplate, error) {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func || rv.IsNil() {
+		return nil, fmt.Errorf("v8go: BindFunc requires a non-nil func, not %T", fn)
+	}
+	name := rv.Type().String()
+	return NewFunctionTemplate(iso, func(info *FunctionCallbackInfo) *Value {
+		return callGoFunc(info.Context(), rv, name, info.Args())
+	}), nil
+}
+
+// RegisterFunction makes a function created by BindFunc a property of the context's global
+// object.
+func (c *Context) RegisterFunction(name string, fn interface{}) error {
+	tmpl, err := BindFunc(c.iso, fn)
+	if err != nil {
+		return err
+	}
+	return c.Global().Set(name, tmpl.GetFunction(c))
+}
+
+// BindMethods creates an object whose properties are functions that call the exported
+// methods of target, which may be a value of any type with methods, such as a pointer to a
+// struct. The functions' names are the methods' names passed through mapName, e.g.
+// CamelCase; if mapName is nil they're the same. Arguments, results and errors are converted
+// as described for BindStruct, and methods with other results are not bound. The functions
+// ignore `this`, so they can be detached from the object and passed around as callbacks.
+// It returns an error if mapName maps two methods to the same name.
+func BindMethods(ctx *Context, target interface{}, mapName func(string) string) (*Object, error) {
+	rv := reflect.ValueOf(target)
+	if !rv.IsValid() {
+		return nil, errors.New("v8go: BindMethods target must not be nil")
+	}
+	obj := ctx.NewObject()
+	names := map[string]string{}
+	for i := 0; i < rv.NumMethod(); i++ {
+		method := rv.Type().Method(i)
+		fn := rv.Method(i)
+		if !bindableResults(fn.Type()) {
+			continue
+		}
+		name := method.Name
+		if mapName != nil {
+			name = mapName(name)
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("v8go: BindMethods maps both %s and %s to %q", other, method.Name, name)
+		}
+		names[name] = method.Name
+		goName := method.Name
+		f := NewFunctionTemplate(ctx.iso, func(info *FunctionCallbackInfo) *Value {
+			return callGoFunc(info.Context(), fn, goName, info.Args())
+		}).GetFunction(ctx)
+		if err := obj.Set(name, f); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// CamelCase maps a Go name to a JS-style one by lower-casing its leading upper-case letters,
+// except for the last of several that is followed by a lower-case letter: "Get" becomes
+// "get", "ID" becomes "id" and "URLFor" becomes "urlFor". It can be passed to BindMethods.
+func CamelCase(name string) string {
+	runes := []rune(name)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+type bindAudit struct {
+	Created string
+}
+
+type bindRequest struct {
+	*bindAudit
+	ID      string            `v8:"id,readonly"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Retries int
+	Secret  string `v8:"-"`
+	hidden  int
+}
+
+func (r *bindRequest) Header(name string) string {
+	return r.Headers[name]
+}
+
+func (r *bindRequest) SetHeader(name, value string) {
+	if r.Headers == nil {
+		r.Headers = map[string]string{}
+	}
+	r.Headers[name] = value
+}
+
+func (r *bindRequest) Sum(base int, more ...int) int {
+	for _, n := range more {
+		base += n
+	}
+	return base
+}
+
+func (r *bindRequest) Validate() error {
+	if r.Method == "" {
+		return errors.New("missing method")
+	}
+	return nil
+}
+
+func (r *bindRequest) Pair() (int, string) { // (not bound)
+	return r.Retries, r.Method
+}
+
+func TestBindStruct(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	tmpl, err := v8.BindStruct(iso, reflect.TypeOf(bindRequest{}))
+	fatalIf(t, err)
+	req := &bindRequest{ID: "r1", Method: "GET", Secret: "shh", hidden: 1}
+	obj, err := tmpl.NewInstance(ctx, req)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("req", obj))
+
+	val, err := ctx.RunScript(`JSON.stringify(req)`, "")
+	fatalIf(t, err)
+	if got := val.String(); got != `{"id":"r1","method":"GET","headers":null,"Retries":0}` {
+		t.Errorf("unexpected JSON: %s", got)
+	}
+
+	val, err = ctx.RunScript(`
+		req.method = "POST";
+		req.Retries = 3;
+		req.id = "changed";
+		req.SetHeader("accept", "text/html");
+		[req.method, req.id, req.Header("accept"), req.Sum(1), req.Sum(1, 2, 3),
+		 typeof req.Secret, typeof req.Pair, Object.keys(req).includes("Validate")].join()`, "")
+	fatalIf(t, err)
+	if got := val.String(); got != "POST,r1,text/html,1,6,undefined,undefined,false" {
+		t.Errorf("unexpected result: %s", got)
+	}
+	if req.Method != "POST" || req.Retries != 3 || req.ID != "r1" || req.Headers["accept"] != "text/html" {
+		t.Errorf("unexpected struct: %+v", req)
+	}
+
+	req.Method = ""
+	if _, err := ctx.RunScript(`req.Validate()`, ""); err == nil || !strings.Contains(err.Error(), "missing method") {
+		t.Errorf("expected the method's error, got %v", err)
+	}
+	if _, err := ctx.RunScript(`req.Retries = "lots"`, ""); err == nil {
+		t.Error("expected an error assigning a string to an int field")
+	}
+	if _, err := ctx.RunScript(`req.Created = "today"`, ""); err == nil {
+		t.Error("expected an error setting a field of a nil embedded struct")
+	}
+	if _, err := ctx.RunScript(`req.Header.call({}, "accept")`, ""); err == nil || !strings.Contains(err.Error(), "Illegal invocation") {
+		t.Errorf("expected an illegal invocation, got %v", err)
+	}
+
+	if _, err := v8.BindStruct(iso, 42); err == nil {
+		t.Error("expected an error binding a non-struct")
+	}
+	if _, err := tmpl.NewInstance(ctx, bindRequest{}); err == nil {
+		t.Error("expected an error creating an instance from a non-pointer")
+	}
+}
+
+type bindService struct {
+	calls int
+}
+
+func (s *bindService) GetUserByID(id int) (map[string]interface{}, error) {
+	s.calls++
+	if id <= 0 {
+		return nil, fmt.Errorf("no user %d", id)
+	}
+	return map[string]interface{}{"id": id}, nil
+}
+
+func (s *bindService) URLFor(path string) string {
+	s.calls++
+	return "https://example.com/" + path
+}
+
+func (s *bindService) Calls() int {
+	return s.calls
+}
+
+func TestBindMethods(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	svc := &bindService{}
+	obj, err := v8.BindMethods(ctx, svc, v8.CamelCase)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("svc", obj))
+
+	val, err := ctx.RunScript(`
+		const urlFor = svc.urlFor; // (detached from svc)
+		[svc.getUserByID(7).id, urlFor("a/b"), svc.calls(), Object.keys(svc).sort()].join()`, "")
+	fatalIf(t, err)
+	if got := val.String(); got != "7,https://example.com/a/b,2,calls,getUserByID,urlFor" {
+		t.Errorf("unexpected result: %s", got)
+	}
+	if _, err := ctx.RunScript(`svc.getUserByID(-1)`, ""); err == nil || !strings.Contains(err.Error(), "no user -1") {
+		t.Errorf("expected the method's error, got %v", err)
+	}
+	if _, err := ctx.RunScript(`svc.getUserByID("x")`, ""); err == nil {
+		t.Error("expected an error converting the argument")
+	}
+
+	obj, err = v8.BindMethods(ctx, svc, nil)
+	fatalIf(t, err)
+	if !obj.Has("URLFor") {
+		t.Error("expected unmapped method names")
+	}
+	if _, err := v8.BindMethods(ctx, svc, func(string) string { return "same" }); err == nil {
+		t.Error("expected an error for clashing names")
+	}
+}
+
+func TestBindFunc(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	fatalIf(t, ctx.RegisterFunction("join", func(sep string, parts ...string) string {
+		return strings.Join(parts, sep)
+	}))
+	fatalIf(t, ctx.RegisterFunction("divmod", func(a, b int) (int, int, error) {
+		if b == 0 {
+			return 0, 0, errors.New("division by zero")
+		}
+		return a / b, a % b, nil
+	}))
+	fatalIf(t, ctx.RegisterFunction("describe", func(v interface{}, missing *string) string {
+		return fmt.Sprintf("%v %v", v, missing == nil)
+	}))
+	var called bool
+	fatalIf(t, ctx.RegisterFunction("notify", func() { called = true }))
+
+	val, err := ctx.RunScript(`JSON.stringify([join("-", "a", "b", "c"), join(","), divmod(7, 2),
+		describe({x: 1}), notify()])`, "")
+	fatalIf(t, err)
+	if got := val.String(); got != `["a-b-c","",[3,1],"map[x:1] true",null]` || !called {
+		t.Errorf("unexpected result: %s", got)
+	}
+	if _, err := ctx.RunScript(`divmod(1, 0)`, ""); err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Errorf("expected the func's error, got %v", err)
+	}
+	if _, err := ctx.RunScript(`divmod("x", 1)`, ""); err == nil || !strings.Contains(err.Error(), "argument 1") {
+		t.Errorf("expected an argument conversion error, got %v", err)
+	}
+
+	if _, err := v8.BindFunc(iso, 42); err == nil {
+		t.Error("expected an error binding a non-func")
+	}
+	var nilFunc func()
+	if err := ctx.RegisterFunction("nil", nilFunc); err == nil {
+		t.Error("expected an error binding a nil func")
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"Get":         "get",
+		"ID":          "id",
+		"URLFor":      "urlFor",
+		"GetUserByID": "getUserByID",
+		"X":           "x",
+		"already":     "already",
+		"ÉtéValue":    "étéValue",
+	} {
+		if got := v8.CamelCase(in); got != want {
+			t.Errorf("CamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+type point struct {
+	X, Y float64
+}
+
+func (p *point) Scale(f float64) {
+	p.X *= f
+	p.Y *= f
+}
+
+func ExampleBindStruct() {
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	tmpl, _ := v8.BindStruct(iso, &point{})
+	p := &point{X: 1, Y: 2}
+	obj, _ := tmpl.NewInstance(ctx, p)
+	ctx.Global().Set("p", obj)
+	ctx.RunScript("p.Scale(10); p.X += 5", "")
+	fmt.Println(p.X, p.Y)
+	// Output:
+	// 15 20
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// CodeGenerationFromStringsCallback is called when JavaScript in a Context that
+// disallows code generation from strings calls `eval` or `new Function`.
+// The source is the code to be compiled, and isCodeLike is true if it is an object
+// that has been marked as code-like rather than a string.
+// It returns whether to allow the code generation, and optionally a string Value
+// to compile in place of the original source; a nil modifiedSource keeps the original.
+// If code generation is not allowed, an EvalError is thrown in JavaScript.
+type CodeGenerationFromStringsCallback func(ctx *Context, source *Value, isCodeLike bool) (allow bool, modifiedSource *Value)
+
+// SetCodeGenerationFromStringsCallback sets the callback that decides whether code
+// generation from strings is allowed, for the Contexts of this Isolate that disallow it
+// by default (see Context.SetAllowCodeGenerationFromStrings.) Passing nil removes
+// the callback, so code generation is always blocked in those Contexts.
+func (i *Isolate) SetCodeGenerationFromStringsCallback(cb CodeGenerationFromStringsCallback) {
+	i.codeGenCallback = cb
+	C.IsolateSetCodeGenerationFromStringsCallback(i.ptr, boolToCBool(cb != nil))
+}
+
+// SetAllowCodeGenerationFromStrings sets whether `eval` and `new Function` may be used
+// in this Context. Contexts allow it by default. When it is disallowed, the Isolate's
+// CodeGenerationFromStringsCallback, if any, is asked whether to allow each attempt.
+func (c *Context) SetAllowCodeGenerationFromStrings(allow bool) {
+	C.ContextSetAllowCodeGenerationFromStrings(c.ptr, boolToCBool(allow))
+}
+
+// IsCodeGenerationFromStringsAllowed returns whether `eval` and `new Function`
+// may be used in this Context without consulting the Isolate's callback.
+func (c *Context) IsCodeGenerationFromStringsAllowed() bool {
+	return C.ContextIsCodeGenerationFromStringsAllowed(c.ptr) != 0
+}
+
+//export goCodeGenerationCallback
+func goCodeGenerationCallback(ctxHandle C.uintptr_t, source C.ValueRef, isCodeLike C.int) C.CodeGenerationResult {
+	ctx := contextFromHandle(ctxHandle)
+	cb := ctx.iso.codeGenCallback
+	if cb == nil {
+		return C.CodeGenerationResult{}
+	}
+	allow, modifiedSource := cb(ctx, &Value{source, ctx}, isCodeLike != 0)
+	rtn := C.CodeGenerationResult{allowed: C.int(boolToCBool(allow))}
+	if allow && modifiedSource != nil {
+		rtn.modifiedSource = modifiedSource.valuePtr()
+	}
+	return rtn
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestContextDisallowCodeGenerationFromStrings(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	if !ctx.IsCodeGenerationFromStringsAllowed() {
+		t.Error("expected code generation to be allowed by default")
+	}
+	val, err := ctx.RunScript("eval('1 + 1')", "eval.js")
+	fatalIf(t, err)
+	if val.Int32() != 2 {
+		t.Errorf("expected 2, got %v", val)
+	}
+
+	ctx.SetAllowCodeGenerationFromStrings(false)
+	if ctx.IsCodeGenerationFromStringsAllowed() {
+		t.Error("expected code generation to be disallowed")
+	}
+	for _, script := range []string{"eval('1 + 1')", "new Function('return 1')()"} {
+		_, err = ctx.RunScript(script, "eval.js")
+		if err == nil || !strings.HasPrefix(err.Error(), "EvalError") {
+			t.Errorf("%s: expected an EvalError, got %v", script, err)
+		}
+	}
+}
+
+func TestIsolateCodeGenerationFromStringsCallback(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	ctx.SetAllowCodeGenerationFromStrings(false)
+
+	var sources []string
+	iso.SetCodeGenerationFromStringsCallback(func(ctx *v8.Context, source *v8.Value, isCodeLike bool) (bool, *v8.Value) {
+		sources = append(sources, source.String())
+		switch source.String() {
+		case "1 + 1":
+			return true, nil
+		case "rewrite me":
+			replacement, _ := v8.NewValue(iso, "'rewritten'")
+			return true, replacement
+		default:
+			return false, nil
+		}
+	})
+
+	val, err := ctx.RunScript("eval('1 + 1')", "eval.js")
+	fatalIf(t, err)
+	if val.Int32() != 2 {
+		t.Errorf("expected 2, got %v", val)
+	}
+
+	val, err = ctx.RunScript("eval('rewrite me')", "eval.js")
+	fatalIf(t, err)
+	if val.String() != "rewritten" {
+		t.Errorf("expected rewritten, got %v", val)
+	}
+
+	_, err = ctx.RunScript("eval('2 + 2')", "eval.js")
+	if err == nil || !strings.HasPrefix(err.Error(), "EvalError") {
+		t.Errorf("expected an EvalError, got %v", err)
+	}
+
+	if len(sources) != 3 {
+		t.Errorf("expected the callback to be called 3 times, got %v", sources)
+	}
+
+	iso.SetCodeGenerationFromStringsCallback(nil)
+	if _, err = ctx.RunScript("eval('1 + 1')", "eval.js"); err == nil {
+		t.Error("expected an error after removing the callback")
+	}
+}
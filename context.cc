@@ -40,7 +40,7 @@ namespace v8go {
     return ref;
   }
 
-  Local<Value> V8GoContext::getValue(ValueRef ref) {
+  bool V8GoContext::isLiveValue(ValueRef ref) {
     if (ref.index < _values.size()) {
       auto scope = _curScope;
       for (auto i = _savedScopes.rbegin(); i != _savedScopes.rend(); ++i) {
@@ -50,15 +50,32 @@ namespace v8go {
         scope = i->scope;
       }
       if (ref.scope == scope) {
-        return _values[ref.index].Get(iso);
+        return !_values[ref.index].IsEmpty();
       }
     }
+    return false;
+  }
+
+  Local<Value> V8GoContext::getValue(ValueRef ref) {
+    if (isLiveValue(ref)) {
+      return _values[ref.index].Get(iso);
+    }
 
     fprintf(stderr, "***** ILLEGAL USE OF OBSOLETE v8go.Value[#%d @%d]; returning `undefined`\n",
             ref.index, ref.scope);
     return v8::Undefined(iso);
   }
 
+  // Releases a value. Its slot in the table isn't reused, so that any further use of the
+  // ValueRef is detected as illegal rather than returning some other value.
+  bool V8GoContext::releaseValue(ValueRef ref) {
+    if (!isLiveValue(ref)) {
+      return false;
+    }
+    _values[ref.index].Reset();
+    return true;
+  }
+
   uint32_t V8GoContext::pushValueScope() {
     _savedScopes.push_back(ValueRef{_curScope, uint32_t(_values.size())});
     _curScope = ++_latestScope;
@@ -81,6 +98,23 @@ namespace v8go {
     return &_unboundScripts.back();
   }
 
+  V8GoModule* V8GoContext::newModule(Local<Module> module) {
+    _modules.emplace_back(iso, module);
+    V8GoModule* m = &_modules.back();
+    _modulesByHash.emplace(module->GetIdentityHash(), m);
+    return m;
+  }
+
+  V8GoModule* V8GoContext::findModule(Local<Module> module) {
+    auto range = _modulesByHash.equal_range(module->GetIdentityHash());
+    for (auto i = range.first; i != range.second; ++i) {
+      if (i->second->ptr == module) {
+        return i->second;
+      }
+    }
+    return nullptr;
+  }
+
 }
 
 
@@ -104,6 +138,11 @@ ContextPtr NewContext(IsolatePtr iso,
 }
 
 void ContextFree(ContextPtr ctx) {
+  {
+    // Clear the back-pointer, since the V8 context may outlive this wrapper.
+    WithIsolate _with(ctx->iso);
+    ctx->context()->SetAlignedPointerInEmbedderData(1, nullptr);
+  }
   delete ctx;
 }
 
@@ -112,32 +151,142 @@ ValueRef ContextGlobal(ContextPtr ctx) {
   return ctx->addValue(_with.local_ctx->Global());
 }
 
-RtnValue RunScript(ContextPtr ctx, const char* source, int sourceLen,
-                   const char* origin, int originLen) {
+void ContextSetAllowCodeGenerationFromStrings(ContextPtr ctx, Bool allow) {
   WithContext _with(ctx);
-  auto iso = ctx->iso;
+  _with.local_ctx->AllowCodeGenerationFromStrings(allow);
+}
+
+Bool ContextIsCodeGenerationFromStringsAllowed(ContextPtr ctx) {
+  WithContext _with(ctx);
+  return _with.local_ctx->IsCodeGenerationFromStringsAllowed();
+}
+
+static RtnValue runScript(WithContext& _with, const char* source, int sourceLen,
+                          ScriptOriginData const& origin,
+                          ScriptCompiler::CompileOptions option) {
+  auto iso = _with.iso();
 
   RtnValue rtn = {};
 
-  MaybeLocal<String> maybeSrc =
-      String::NewFromUtf8(iso, source, NewStringType::kNormal, sourceLen);
-  MaybeLocal<String> maybeOgn =
-      String::NewFromUtf8(iso, origin, NewStringType::kNormal, originLen);
-  Local<String> src, ogn;
-  if (!maybeSrc.ToLocal(&src) || !maybeOgn.ToLocal(&ogn)) {
+  Local<String> src;
+  if (!String::NewFromUtf8(iso, source, NewStringType::kNormal, sourceLen).ToLocal(&src)) {
     rtn.error = _with.exceptionError();
     return rtn;
   }
 
-  ScriptOrigin script_origin(ogn);
+  ScriptCompiler::Source script_source(src, NewScriptOrigin(iso, origin));
   Local<Script> script;
-  if (!Script::Compile(_with.local_ctx, src, &script_origin).ToLocal(&script)) {
+  if (!ScriptCompiler::Compile(_with.local_ctx, &script_source, option).ToLocal(&script)) {
     rtn.error = _with.exceptionError();
     return rtn;
   }
   return _with.returnValue(script->Run(_with.local_ctx));
 }
 
+RtnValue RunScript(ContextPtr ctx, const char* source, int sourceLen,
+                   ScriptOriginData origin, int compileOption) {
+  WithContext _with(ctx);
+  return runScript(_with, source, sourceLen, origin,
+                   static_cast<ScriptCompiler::CompileOptions>(compileOption));
+}
+
+void RunScripts(ContextPtr ctx, int count, ScriptSourceData* scripts, RtnValue* results) {
+  WithContext _with(ctx);
+  for (int i = 0; i < count; i++) {
+    HandleScope handle_scope(_with.iso());
+    results[i] = runScript(_with, scripts[i].source, scripts[i].sourceLen, scripts[i].origin,
+                           ScriptCompiler::kNoCompileOptions);
+    _with.try_catch.Reset();
+  }
+}
+
+RtnValue ContextCompileFunction(ContextPtr ctx,
+                                const char* body, int bodyLen,
+                                ScriptOriginData origin,
+                                int paramCount,
+                                const char* params, const int* paramLens,
+                                int extensionCount,
+                                ValuePtr extensions[]) {
+  WithContext _with(ctx);
+  auto iso = ctx->iso;
+
+  RtnValue rtn = {};
+
+  Local<String> src;
+  if (!String::NewFromUtf8(iso, body, NewStringType::kNormal, bodyLen).ToLocal(&src)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+
+  // The parameter names are passed concatenated, along with their lengths.
+  Local<String> paramNames[paramCount + 1];  // (+1 because zero-length arrays aren't allowed)
+  for (int i = 0; i < paramCount; i++) {
+    paramNames[i] = _with.makeString(params, NewStringType::kInternalized, paramLens[i]);
+    params += paramLens[i];
+  }
+
+  Local<Object> extensionObjs[extensionCount + 1];
+  for (int i = 0; i < extensionCount; i++) {
+    extensionObjs[i] = Deref(extensions[i]).As<Object>();
+  }
+
+  ScriptCompiler::Source source(src, NewScriptOrigin(iso, origin));
+  return _with.returnValue(ScriptCompiler::CompileFunction(_with.local_ctx, &source,
+                                                           paramCount, paramNames,
+                                                           extensionCount, extensionObjs));
+}
+
+RtnValue ContextCallByPath(ContextPtr ctx,
+                           const char* path, int pathLen,
+                           Bool hasRecv, ValuePtr recv,
+                           int argc, ValuePtr args[]) {
+  WithContext _with(ctx);
+  auto iso = ctx->iso;
+  RtnValue rtn = {};
+
+  // Look up each dot-separated component of the path, starting at the global object.
+  Local<Value> holder = _with.local_ctx->Global();
+  Local<Value> value = holder;
+  const char* end = path + pathLen;
+  for (const char* start = path; start <= end;) {
+    const char* dot = static_cast<const char*>(memchr(start, '.', end - start));
+    if (dot == nullptr) {
+      dot = end;
+    }
+    std::string sofar(path, dot - path);
+    if (dot == start) {
+      iso->ThrowException(Exception::SyntaxError(String::NewFromUtf8(
+          iso, ("Invalid path '" + std::string(path, pathLen) + "'").c_str()).ToLocalChecked()));
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    if (!value->IsObject()) {
+      iso->ThrowException(Exception::TypeError(String::NewFromUtf8(
+          iso, ("Cannot read '" + sofar + "' of a non-object").c_str()).ToLocalChecked()));
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    holder = value;
+    Local<String> key = _with.makeString(start, NewStringType::kInternalized, int(dot - start));
+    if (!holder.As<Object>()->Get(_with.local_ctx, key).ToLocal(&value)) {
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    start = dot + 1;
+  }
+  if (!value->IsFunction()) {
+    iso->ThrowException(Exception::TypeError(String::NewFromUtf8(
+        iso, (std::string(path, pathLen) + " is not a function").c_str()).ToLocalChecked()));
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+
+  Local<Value> argv[argc + 1];  // (+1 because zero-length arrays aren't allowed)
+  buildCallArguments(iso, argv, argc, args);
+  Local<Value> local_recv = hasRecv ? Deref(recv) : holder;
+  return _with.returnValue(value.As<Function>()->Call(_with.local_ctx, local_recv, argc, argv));
+}
+
 /********** JSON **********/
 
 RtnValue JSONParse(ContextPtr ctx, const char* str, int len) {
@@ -177,3 +326,9 @@ Bool PopValueScope(ContextPtr ctx, ValueScope scope) {
 
   return ctx->popValueScope(scope);
 }
+
+Bool ReleaseValue(ValuePtr ptr) {
+  WithIsolate _withiso(ptr.ctx->iso);
+
+  return ptr.ctx->releaseValue(ptr.ref);
+}
@@ -4,12 +4,30 @@
 
 package v8go
 
-// #include <stdlib.h>
-// #include "v8go.h"
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static RtnValue ContextCompileFunctionGo(ContextPtr ctx, _GoString_ body, ScriptOriginData origin,
+										int paramCount, _GoString_ params, const int* paramLens,
+										int extensionCount, ValuePtr extensions[]) {
+	return ContextCompileFunction(ctx, _GoStringPtr(body), _GoStringLen(body), origin,
+								paramCount, _GoStringPtr(params), paramLens,
+								extensionCount, extensions); }
+static RtnValue ContextCallByPathGo(ContextPtr ctx, _GoString_ path, Bool hasRecv, ValuePtr recv,
+								   int argc, ValuePtr args[]) {
+	return ContextCallByPath(ctx, _GoStringPtr(path), _GoStringLen(path), hasRecv, recv, argc, args); }
+static ValueRef ContextNewErrorGo(ContextPtr ctx, ErrorKind kind, _GoString_ msg) {
+	return ContextNewError(ctx, kind, _GoStringPtr(msg), _GoStringLen(msg)); }
+*/
 import "C"
 import (
+	"context"
+	"errors"
+	"io"
 	"runtime"
 	"runtime/cgo"
+	"strings"
+	"time"
 	"unsafe"
 )
 
@@ -19,6 +37,14 @@ type Context struct {
 	ptr        C.ContextPtr // Pointer to C++ V8GoContext object
 	iso        *Isolate     // The Isolate this Context belongs to
 	selfHandle cgo.Handle   // Opaque handle pointing to the Context itself
+
+	modules       map[C.ModulePtr]*Module // Modules compiled in this Context
+	resolveModule ResolveModuleCallback   // Resolves the imports of modules
+	importMeta    ImportMetaCallback      // Initializes `import.meta` of modules
+	dynamicImport DynamicImportCallback   // Resolves `import()` expressions
+
+	templateInstances map[*template]*Persistent // Instances cached by CachedInstance, etc.
+	promiseHooks      *PromiseHooks             // Hooks for promises created in this Context
 }
 
 type contextOptions struct {
@@ -58,6 +84,17 @@ func NewContext(opt ...ContextOption) *Context {
 	return ctx
 }
 
+// newError creates an Error, or a TypeError, with the given message.
+func (c *Context) newError(kind C.ErrorKind, msg string) *Object {
+	return &Object{&Value{C.ContextNewErrorGo(c.ptr, kind, msg), c}}
+}
+
+// throwError schedules an Error, or a TypeError, with the given message to be thrown when
+// returning to JavaScript, like Isolate.ThrowException.
+func (c *Context) throwError(kind C.ErrorKind, msg string) *Value {
+	return c.iso.ThrowException(c.newError(kind, msg).Value)
+}
+
 func contextFromHandle(handle C.uintptr_t) *Context {
 	return cgo.Handle(handle).Value().(*Context)
 }
@@ -67,16 +104,236 @@ func (c *Context) Isolate() *Isolate {
 	return c.iso
 }
 
+// CheckSyntax compiles the source JavaScript without running it, so it has no side effects
+// on this context. See Isolate.CheckSyntax.
+func (c *Context) CheckSyntax(source, origin string) error {
+	return c.iso.CheckSyntax(source, origin)
+}
+
 // RunScript executes the source JavaScript; origin (a.k.a. filename) provides a
 // reference for the script and used in the stack trace if there is an error.
 // error will be of type `JSError` if not nil.
 func (c *Context) RunScript(source string, origin string) (*Value, error) {
+	return c.runScript(source, ScriptOrigin{ResourceName: origin}, CompileModeDefault)
+}
+
+func (c *Context) runScript(source string, origin ScriptOrigin, mode CompileMode) (*Value, error) {
 	cSource := C.CString(source)
-	cOrigin := C.CString(origin)
+	cOrigin := newCScriptOrigin(origin)
 	defer C.free(unsafe.Pointer(cSource))
+	defer freeCScriptOrigin(cOrigin)
+
+	rtn := C.RunScript(c.ptr, cSource, C.int(len(source)), cOrigin, C.int(mode))
+	return valueResult(c, rtn)
+}
+
+// RunScriptBytes is like RunScript, but takes the source as UTF-8 bytes, which are
+// passed to V8 without first being copied. The slice must not be modified until
+// RunScriptBytes returns.
+func (c *Context) RunScriptBytes(source []byte, origin string) (*Value, error) {
+	var cSource *C.char
+	if len(source) > 0 {
+		cSource = (*C.char)(unsafe.Pointer(&source[0]))
+	}
+	cOrigin := newCScriptOrigin(ScriptOrigin{ResourceName: origin})
+	defer freeCScriptOrigin(cOrigin)
+
+	rtn := C.RunScript(c.ptr, cSource, C.int(len(source)), cOrigin, C.int(CompileModeDefault))
+	return valueResult(c, rtn)
+}
+
+// ScriptSource is a script to be run by Context.RunScripts.
+type ScriptSource struct {
+	Source string
+	Origin string // a.k.a. filename
+}
+
+// ScriptResult is the result of running one of the scripts given to Context.RunScripts.
+// Err will be of type `JSError` if not nil.
+type ScriptResult struct {
+	Value *Value
+	Err   error
+}
+
+// RunScripts runs each of the scripts in order, like calling RunScript on each, but
+// with a single call into V8, which is faster when running many small scripts.
+// All the scripts are run even if some of them fail; the result of each script is
+// returned at the same index.
+func (c *Context) RunScripts(scripts []ScriptSource) []ScriptResult {
+	if len(scripts) == 0 {
+		return nil
+	}
+	cScripts := make([]C.ScriptSourceData, len(scripts))
+	for i, script := range scripts {
+		cScripts[i] = C.ScriptSourceData{
+			source:    C.CString(script.Source),
+			sourceLen: C.int(len(script.Source)),
+			origin:    newCScriptOrigin(ScriptOrigin{ResourceName: script.Origin}),
+		}
+	}
+	defer func() {
+		for _, cScript := range cScripts {
+			C.free(unsafe.Pointer(cScript.source))
+			freeCScriptOrigin(cScript.origin)
+		}
+	}()
+
+	rtns := make([]C.RtnValue, len(scripts))
+	C.RunScripts(c.ptr, C.int(len(scripts)), &cScripts[0], &rtns[0])
+
+	results := make([]ScriptResult, len(scripts))
+	for i, rtn := range rtns {
+		results[i].Value, results[i].Err = valueResult(c, rtn)
+	}
+	return results
+}
+
+// CompileFunction compiles body as the body of a function taking the given named
+// parameters, as if by `function (params...) { body }`, but without wrapping the
+// source, so locations in errors and stack traces match the body as given.
+// Properties of the extensions, if any, are in scope within the function, as if by
+// a `with` statement around it.
+// error will be of type `JSError` if not nil.
+func (c *Context) CompileFunction(body string, params []string, origin string, extensions ...*Object) (*Function, error) {
+	paramLens := make([]C.int, len(params)+1)
+	for i, p := range params {
+		paramLens[i] = C.int(len(p))
+	}
+	exts := make([]Valuer, len(extensions))
+	for i, ext := range extensions {
+		exts[i] = ext
+	}
+	cExts, extptr := convertArgs(exts)
+
+	cOrigin := newCScriptOrigin(ScriptOrigin{ResourceName: origin})
+	defer freeCScriptOrigin(cOrigin)
+
+	rtn := C.ContextCompileFunctionGo(c.ptr, body, cOrigin,
+		C.int(len(params)), strings.Join(params, ""), &paramLens[0],
+		C.int(len(extensions)), extptr)
+	runtime.KeepAlive(cExts)
+	val, err := valueResult(c, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &Function{val}, nil
+}
+
+// CallByPath looks up a function by a dot-separated path of property names starting at the
+// global object, such as "api.handlers.onUpdate", and calls it with the given arguments, in
+// a single call into V8. If recv is nil, `this` is the object the function was found on,
+// as in a method call; otherwise it's recv. error will be of type `JSError` if the path
+// doesn't lead to a function, or the function throws.
+func (c *Context) CallByPath(path string, recv Valuer, args ...Valuer) (*Value, error) {
+	hasRecv := recv != nil
+	var recvPtr C.ValuePtr
+	if hasRecv {
+		recvPtr = recv.value().valuePtr()
+	}
+	cArgs, argptr := convertArgs(args)
+	rtn := C.ContextCallByPathGo(c.ptr, path, boolToCBool(hasRecv), recvPtr, C.int(len(args)), argptr)
+	runtime.KeepAlive(cArgs)
+	return valueResult(c, rtn)
+}
+
+// RunScriptCtx is like RunScript, but terminates the script if the Go context is
+// cancelled or its deadline expires before the script finishes. In that case the
+// error returned is ctx.Err().
+func (c *Context) RunScriptCtx(ctx context.Context, source string, origin string) (*Value, error) {
+	return c.runTerminable(ctx, func() (*Value, error) {
+		return c.RunScript(source, origin)
+	})
+}
+
+// runTerminable calls run, terminating execution in the Isolate if ctx is done first.
+func (c *Context) runTerminable(ctx context.Context, run func() (*Value, error)) (*Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	terminated := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.iso.TerminateExecution()
+			terminated <- true
+		case <-done:
+			terminated <- false
+		}
+	}()
+
+	val, err := run()
+	close(done)
+	if <-terminated {
+		// The script may have finished before the termination took effect; either way,
+		// make sure it doesn't carry over to the next script run in this Isolate.
+		c.iso.CancelTerminateExecution()
+		if err != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return val, err
+}
+
+// RunScriptOptions are the options passed to Context.RunScriptWithOptions.
+type RunScriptOptions struct {
+	// Origin, if not nil, describes the script in more detail than the origin
+	// argument, which it replaces.
+	Origin *ScriptOrigin
+
+	// Mode controls how much of the script is compiled before it runs. By default,
+	// V8 compiles functions lazily, when they are first called; CompileModeEager
+	// compiles them all up front, which takes longer before the script starts but
+	// avoids compiling on first call.
+	Mode CompileMode
+
+	// Timeout is the maximum wall-clock time the script may run for before it is
+	// terminated. Zero means no limit.
+	Timeout time.Duration
+}
+
+// ErrExecutionTimeout is returned by Context.RunScriptWithOptions when the script
+// was terminated because it ran for longer than RunScriptOptions.Timeout.
+var ErrExecutionTimeout = errors.New("v8go: script execution timed out")
+
+// RunScriptWithOptions is like RunScript, but with the given options.
+// If the script is terminated because of a Timeout, the error is ErrExecutionTimeout;
+// otherwise error will be of type `JSError` if not nil.
+func (c *Context) RunScriptWithOptions(source string, origin string, opts RunScriptOptions) (*Value, error) {
+	scriptOrigin := ScriptOrigin{ResourceName: origin}
+	if opts.Origin != nil {
+		scriptOrigin = *opts.Origin
+	}
+	if opts.Timeout <= 0 {
+		return c.runScript(source, scriptOrigin, opts.Mode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	val, err := c.runTerminable(ctx, func() (*Value, error) {
+		return c.runScript(source, scriptOrigin, opts.Mode)
+	})
+	if err == context.DeadlineExceeded {
+		return nil, ErrExecutionTimeout
+	}
+	return val, err
+}
+
+// RunScriptStream is like RunScript, but reads the source from r. The source is
+// parsed on a background thread while it is being read, so large scripts don't
+// have to be held in memory as a Go string before compilation can start.
+// The source must be UTF-8. An error from r is returned as-is; otherwise error
+// will be of type `JSError` if not nil.
+func (c *Context) RunScriptStream(r io.Reader, origin string) (*Value, error) {
+	streamer, err := streamScript(c.iso, r)
+	if err != nil {
+		return nil, err
+	}
+	defer C.ScriptStreamerFree(streamer)
+
+	cOrigin := C.CString(origin)
 	defer C.free(unsafe.Pointer(cOrigin))
 
-	rtn := C.RunScript(c.ptr, cSource, C.int(len(source)), cOrigin, C.int(len(origin)))
+	rtn := C.ScriptStreamerRun(streamer, c.ptr, cOrigin, C.int(len(origin)))
 	return valueResult(c, rtn)
 }
 
@@ -94,8 +351,10 @@ func (c *Context) Global() *Object {
 }
 
 // PerformMicrotaskCheckpoint runs the default MicrotaskQueue until empty.
-// This is used to make progress on Promises.
+// This is used to make progress on Promises. It first settles the promises of async calls
+// whose callbacks have finished; see NewAsyncFunctionTemplate.
 func (c *Context) PerformMicrotaskCheckpoint() {
+	c.iso.settleAsyncResults()
 	C.IsolatePerformMicrotaskCheckpoint(c.iso.ptr)
 }
 
@@ -103,21 +362,27 @@ func (c *Context) PerformMicrotaskCheckpoint() {
 // You must call this yourself: the Go garbage collector will not free an unused open Context!
 // Access to any values associated with the context after calling Close may panic.
 func (c *Context) Close() {
+	for _, p := range c.templateInstances {
+		p.Release()
+	}
+	c.templateInstances = nil
+	c.SetPromiseHooks(nil)
 	C.ContextFree(c.ptr)
 	c.selfHandle.Delete()
 	c.ptr = nil
+	c.modules = nil
 }
 
 func valueResult(ctx *Context, rtn C.RtnValue) (*Value, error) {
 	if rtn.error.msg != nil {
-		return nil, newJSError(rtn.error)
+		return nil, newJSError(ctx.iso, rtn.error)
 	}
 	return &Value{rtn.value, ctx}, nil
 }
 
 func objectResult(ctx *Context, rtn C.RtnValue) (*Object, error) {
 	if rtn.error.msg != nil {
-		return nil, newJSError(rtn.error)
+		return nil, newJSError(ctx.iso, rtn.error)
 	}
 	return &Object{&Value{rtn.value, ctx}}, nil
 }
@@ -5,9 +5,14 @@
 package v8go_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	v8 "github.com/couchbasedeps/v8go"
 )
@@ -71,6 +76,302 @@ func TestJSExceptions(t *testing.T) {
 	}
 }
 
+func TestContextRunScriptCtx(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScriptCtx(context.Background(), "1 + 2", "add.js")
+	fatalIf(t, err)
+	if val.Int32() != 3 {
+		t.Errorf("expected 3, got %v", val)
+	}
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = ctx.RunScriptCtx(goCtx, "while (true) {}", "forever.js")
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = ctx.RunScriptCtx(cancelled, "1", "one.js"); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// The Isolate must still be usable after a script was terminated.
+	val, err = ctx.RunScript("'still alive'", "main.js")
+	fatalIf(t, err)
+	if val.String() != "still alive" {
+		t.Errorf("unexpected value: %v", val)
+	}
+}
+
+func TestContextRunScriptWithOptions(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	opts := v8.RunScriptOptions{Timeout: 50 * time.Millisecond}
+	val, err := ctx.RunScriptWithOptions("1 + 2", "add.js", opts)
+	fatalIf(t, err)
+	if val.Int32() != 3 {
+		t.Errorf("expected 3, got %v", val)
+	}
+
+	_, err = ctx.RunScriptWithOptions("while (true) {}", "forever.js", opts)
+	if err != v8.ErrExecutionTimeout {
+		t.Errorf("expected ErrExecutionTimeout, got %v", err)
+	}
+
+	_, err = ctx.RunScriptWithOptions("throw new Error('oops')", "throw.js", opts)
+	if _, ok := err.(*v8.JSError); !ok {
+		t.Errorf("expected a JSError, got %v", err)
+	}
+}
+
+func TestContextRunScriptWithOptions_EagerCompile(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	opts := v8.RunScriptOptions{Mode: v8.CompileModeEager}
+	val, err := ctx.RunScriptWithOptions("function square(x) { return x * x }; square(7)", "eager.js", opts)
+	fatalIf(t, err)
+	if val.Int32() != 49 {
+		t.Errorf("expected 49, got %v", val)
+	}
+
+	_, err = ctx.RunScriptWithOptions("function broken() { return ) }", "broken.js", opts)
+	if e, ok := err.(*v8.JSError); !ok || e.Location != "broken.js:1:28" {
+		t.Errorf("expected a syntax error at broken.js:1:28, got %v", err)
+	}
+}
+
+func TestContextRunScriptWithOrigin(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	origin := &v8.ScriptOrigin{
+		ResourceName: "page.html",
+		LineOffset:   10,
+		ColumnOffset: 4,
+		SourceMapURL: "page.js.map",
+	}
+	_, err := ctx.RunScriptWithOptions("throw new Error('oops'); function add(a, b) { return a + b; }", "", v8.RunScriptOptions{Origin: origin})
+	jsErr, ok := err.(*v8.JSError)
+	if !ok {
+		t.Fatalf("expected a JSError, got %v", err)
+	}
+	if jsErr.Location != "page.html:11:5" {
+		t.Errorf("unexpected error location: %q", jsErr.Location)
+	}
+
+	addValue, err := ctx.Global().Get("add")
+	fatalIf(t, err)
+	fn, _ := addValue.AsFunction()
+	if url := fn.SourceMapUrl(); url.String() != "page.js.map" {
+		t.Errorf("expected page.js.map, got %v", url)
+	}
+}
+
+func TestContextRunScriptBytes(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScriptBytes([]byte(`const add = (a, b) => a + b; add(3, 4)`), "add.js")
+	fatalIf(t, err)
+	if val.Int32() != 7 {
+		t.Errorf("expected 7, got %v", val)
+	}
+
+	val, err = ctx.RunScriptBytes(nil, "empty.js")
+	fatalIf(t, err)
+	if !val.IsUndefined() {
+		t.Errorf("expected undefined, got %v", val)
+	}
+
+	_, err = ctx.RunScriptBytes([]byte("bad js syntax"), "syntax.js")
+	if err == nil || err.Error() != "SyntaxError: Unexpected identifier" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestContextRunScripts(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	if results := ctx.RunScripts(nil); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+
+	results := ctx.RunScripts([]v8.ScriptSource{
+		{Source: "const add = (a, b) => a + b", Origin: "add.js"},
+		{Source: "bad js syntax", Origin: "syntax.js"},
+		{Source: "add(3, 4)", Origin: "main.js"},
+		{Source: "missing()", Origin: "missing.js"},
+	})
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if results[0].Err != nil || !results[0].Value.IsUndefined() {
+		t.Errorf("unexpected result 0: %v, %v", results[0].Value, results[0].Err)
+	}
+	if err := results[1].Err; err == nil || err.Error() != "SyntaxError: Unexpected identifier" {
+		t.Errorf("unexpected error 1: %v", err)
+	}
+	if results[2].Err != nil || results[2].Value.Int32() != 7 {
+		t.Errorf("unexpected result 2: %v, %v", results[2].Value, results[2].Err)
+	}
+	if err, ok := results[3].Err.(*v8.JSError); !ok || err.Location != "missing.js:1:1" {
+		t.Errorf("unexpected error 3: %#v", results[3].Err)
+	}
+}
+
+func TestContextCompileFunction(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	iso := ctx.Isolate()
+	defer iso.Dispose()
+	defer ctx.Close()
+
+	fn, err := ctx.CompileFunction("return a + b", []string{"a", "b"}, "add.js")
+	fatalIf(t, err)
+	a, _ := v8.NewValue(iso, int32(3))
+	b, _ := v8.NewValue(iso, int32(4))
+	val, err := fn.Call(v8.Undefined(iso), a, b)
+	fatalIf(t, err)
+	if val.Int32() != 7 {
+		t.Errorf("expected 7, got %v", val)
+	}
+
+	ext := ctx.NewObject()
+	ext.Set("factor", int32(10))
+	fn, err = ctx.CompileFunction("return x * factor", []string{"x"}, "scale.js", ext)
+	fatalIf(t, err)
+	val, err = fn.Call(v8.Undefined(iso), a)
+	fatalIf(t, err)
+	if val.Int32() != 30 {
+		t.Errorf("expected 30, got %v", val)
+	}
+
+	fn, err = ctx.CompileFunction("\nthrow new Error('oops')", nil, "throw.js")
+	fatalIf(t, err)
+	_, err = fn.Call(v8.Undefined(iso))
+	if jsErr, ok := err.(*v8.JSError); !ok || jsErr.Location != "throw.js:2:1" {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	if _, err = ctx.CompileFunction("return )", nil, "syntax.js"); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
+
+func TestContextCallByPath(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	_, err := ctx.RunScript(`
+		var api = {handlers: {name: "handlers", onUpdate(a, b) { return this.name + ":" + (a + b) }}};
+		function top() { return this === globalThis }`, "")
+	fatalIf(t, err)
+
+	one, _ := v8.NewValue(iso, int32(1))
+	two, _ := v8.NewValue(iso, int32(2))
+	val, err := ctx.CallByPath("api.handlers.onUpdate", nil, one, two)
+	fatalIf(t, err)
+	if val.String() != "handlers:3" {
+		t.Errorf("expected the holder as this, got %q", val)
+	}
+	recv, _ := ctx.RunScript(`({name: "other"})`, "")
+	val, err = ctx.CallByPath("api.handlers.onUpdate", recv, one, one)
+	fatalIf(t, err)
+	if val.String() != "other:2" {
+		t.Errorf("expected recv as this, got %q", val)
+	}
+	val, err = ctx.CallByPath("top", nil)
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Error("expected the global object as this for a global function")
+	}
+
+	for path, msg := range map[string]string{
+		"api.missing.onUpdate": "TypeError: Cannot read 'api.missing.onUpdate' of a non-object",
+		"api.handlers.name":    "TypeError: api.handlers.name is not a function",
+		"api..onUpdate":        "SyntaxError: Invalid path 'api..onUpdate'",
+		"":                     "SyntaxError: Invalid path ''",
+	} {
+		if _, err := ctx.CallByPath(path, nil); err == nil || err.Error() != msg {
+			t.Errorf("CallByPath(%q): expected %q, got %v", path, msg, err)
+		}
+	}
+}
+
+func TestContextRunScriptStream(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	// Feed one byte at a time, so multi-byte characters are split across chunks.
+	src := `const greet = (name) => "héllo, " + name + " 👋"; greet("wörld")`
+	val, err := ctx.RunScriptStream(iotest.OneByteReader(strings.NewReader(src)), "stream.js")
+	fatalIf(t, err)
+	if expected := "héllo, wörld 👋"; val.String() != expected {
+		t.Errorf("expected %q, got %q", expected, val.String())
+	}
+
+	big := strings.Repeat("var x = 1 + 2;\n", 100000) + "x * 2"
+	val, err = ctx.RunScriptStream(strings.NewReader(big), "big.js")
+	fatalIf(t, err)
+	if val.Int32() != 6 {
+		t.Errorf("expected 6, got %v", val)
+	}
+
+	_, err = ctx.RunScriptStream(strings.NewReader("bad js syntax"), "syntax.js")
+	if err == nil || err.Error() != "SyntaxError: Unexpected identifier" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	readErr := errors.New("read failed")
+	_, err = ctx.RunScriptStream(iotest.ErrReader(readErr), "error.js")
+	if err != readErr {
+		t.Errorf("expected reader error, got %v", err)
+	}
+}
+
+func TestContextCheckSyntax(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	fatalIf(t, ctx.CheckSyntax("var ran = true", "script.js"))
+	val, err := ctx.RunScript("typeof ran", "check.js")
+	fatalIf(t, err)
+	if val.String() != "undefined" {
+		t.Error("expected CheckSyntax not to run the script")
+	}
+
+	if err := ctx.CheckSyntax("function (", "script.js"); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestMemoryLeak(t *testing.T) {
 	t.Parallel()
 
@@ -127,6 +428,33 @@ func TestRegistryFromJSON(t *testing.T) {
 	}
 }
 
+func BenchmarkContextRunScriptBytes(b *testing.B) {
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+	source := []byte(strings.Repeat("var x = 1 + 2;\n", 10000))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		ctx.RunScriptBytes(source, "bundle.js")
+	}
+}
+
+func BenchmarkContextRunScripts(b *testing.B) {
+	ctx := v8.NewContext(nil)
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+	scripts := make([]v8.ScriptSource, 50)
+	for i := range scripts {
+		scripts[i] = v8.ScriptSource{Source: fmt.Sprintf("var x%d = %d", i, i), Origin: "polyfill.js"}
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		ctx.WithTemporaryValues(func() {
+			ctx.RunScripts(scripts)
+		})
+	}
+}
+
 func BenchmarkContext(b *testing.B) {
 	b.ReportAllocs()
 	iso := v8.NewIsolate()
@@ -183,3 +511,41 @@ func ExampleContext_globalTemplate() {
 	// Output:
 	// v1.0.0
 }
+
+func TestValueRelease(t *testing.T) {
+	fatalIf(t, v8.SetFlagsChecked("--expose-gc"))
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("({big: 'x'.repeat(1000)})", "")
+	fatalIf(t, err)
+	p := v8.NewPersistent(val)
+	collected := false
+	p.MakeWeak(func() { collected = true })
+
+	val.Release()
+	val.Release()
+	_, err = ctx.RunScript("gc()", "")
+	fatalIf(t, err)
+	if !collected {
+		t.Error("expected the released value to be garbage collected")
+	}
+
+	// Values created before and after are unaffected.
+	before, _ := ctx.RunScript("'before'", "")
+	released, _ := ctx.RunScript("'released'", "")
+	released.Release()
+	after, _ := ctx.RunScript("'after'", "")
+	if before.String() != "before" || after.String() != "after" {
+		t.Errorf("unexpected values %q, %q", before, after)
+	}
+
+	// The Isolate's shared values can't be released.
+	v8.Undefined(iso).Release()
+	v8.Null(iso).Release()
+	if !v8.Undefined(iso).IsUndefined() || !v8.Null(iso).IsNull() {
+		t.Error("expected shared values to be unaffected")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static RtnString InspectorSessionDispatchGo(InspectorSessionPtr ptr, _GoString_ msg) {
+	return InspectorSessionDispatch(ptr, _GoStringPtr(msg), _GoStringLen(msg)); }
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// CoverageCollector is used to collect precise code coverage of the JavaScript run in
+// an Isolate, i.e. how many times each function and each block within it was executed.
+type CoverageCollector struct {
+	p      C.InspectorSessionPtr
+	iso    *Isolate
+	lastID int
+}
+
+// CoverageOptions are the options passed to CoverageCollector.Start.
+type CoverageOptions struct {
+	// CallCount collects execution counts; otherwise counts are only 0 or 1,
+	// which is cheaper.
+	CallCount bool
+	// Detailed collects coverage of blocks within functions, not just of whole functions.
+	Detailed bool
+}
+
+// ScriptCoverage is the coverage of the functions in a script.
+type ScriptCoverage struct {
+	ScriptID  int                `json:"scriptId,string"`
+	URL       string             `json:"url"` // The origin the script was compiled with.
+	Functions []FunctionCoverage `json:"functions"`
+}
+
+// FunctionCoverage is the coverage of a function.
+type FunctionCoverage struct {
+	FunctionName string `json:"functionName"`
+	// Ranges are the source ranges of the function and of blocks within it. The first
+	// range is the whole function; blocks follow, nested ranges after their parent.
+	Ranges []CoverageRange `json:"ranges"`
+	// IsBlockCoverage is true if Ranges includes blocks, not just the whole function.
+	IsBlockCoverage bool `json:"isBlockCoverage"`
+}
+
+// CoverageRange is a source range and the number of times it was executed.
+// The offsets are character offsets in the script source.
+type CoverageRange struct {
+	StartOffset int `json:"startOffset"`
+	EndOffset   int `json:"endOffset"`
+	Count       int `json:"count"`
+}
+
+// NewCoverageCollector creates a CoverageCollector for the given Isolate.
+// Dispose must be called when it is no longer needed.
+func NewCoverageCollector(iso *Isolate) *CoverageCollector {
+	if iso == nil {
+		panic("nil Isolate argument not supported")
+	}
+	c := &CoverageCollector{
+		p:   C.NewInspectorSession(iso.ptr),
+		iso: iso,
+	}
+	if err := c.call("Profiler.enable", nil, nil); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Start starts collecting coverage. Only functions that are run after coverage collection
+// starts are covered accurately, so it should be started before running any scripts.
+func (c *CoverageCollector) Start(opts CoverageOptions) error {
+	params := map[string]bool{"callCount": opts.CallCount, "detailed": opts.Detailed}
+	return c.call("Profiler.startPreciseCoverage", params, nil)
+}
+
+// Take returns the coverage collected so far, for all scripts in the Isolate.
+// If CoverageOptions.CallCount was set, the counts are reset.
+func (c *CoverageCollector) Take() ([]ScriptCoverage, error) {
+	var result struct {
+		Result []ScriptCoverage `json:"result"`
+	}
+	if err := c.call("Profiler.takePreciseCoverage", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// Stop stops collecting coverage and discards the collected data.
+func (c *CoverageCollector) Stop() error {
+	return c.call("Profiler.stopPreciseCoverage", nil, nil)
+}
+
+// Dispose will dispose the coverage collector, stopping collection if it was started.
+func (c *CoverageCollector) Dispose() {
+	if c.p == nil {
+		return
+	}
+	C.InspectorSessionDispose(c.p)
+	c.p = nil
+}
+
+// call sends an inspector protocol command and decodes its result into result.
+func (c *CoverageCollector) call(method string, params interface{}, result interface{}) error {
+	if c.p == nil || c.iso.ptr == nil {
+		panic("coverage collector or isolate are nil")
+	}
+	c.lastID++
+	msg, err := json.Marshal(struct {
+		ID     int         `json:"id"`
+		Method string      `json:"method"`
+		Params interface{} `json:"params,omitempty"`
+	}{c.lastID, method, params})
+	if err != nil {
+		return err
+	}
+
+	rtn := C.InspectorSessionDispatchGo(c.p, string(msg))
+	if rtn.data == nil {
+		return fmt.Errorf("v8go: no response to %s", method)
+	}
+	data := C.GoBytes(unsafe.Pointer(rtn.data), rtn.length)
+	C.free(unsafe.Pointer(rtn.data))
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return errors.New("v8go: " + method + ": " + response.Error.Message)
+	}
+	if result != nil {
+		return json.Unmarshal(response.Result, result)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestCoverageCollector(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	cov := v8.NewCoverageCollector(iso)
+	defer cov.Dispose()
+	fatalIf(t, cov.Start(v8.CoverageOptions{CallCount: true, Detailed: true}))
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	src := `function check(x) {
+  if (x > 0) {
+    return 'positive';
+  }
+  return 'other';
+}
+for (let i = 0; i < 3; i++) check(1);`
+	_, err := ctx.RunScript(src, "check.js")
+	fatalIf(t, err)
+
+	scripts, err := cov.Take()
+	fatalIf(t, err)
+
+	var fn *v8.FunctionCoverage
+	for _, script := range scripts {
+		if script.URL != "check.js" {
+			continue
+		}
+		if script.ScriptID == 0 {
+			t.Error("expected a script ID")
+		}
+		for i := range script.Functions {
+			if script.Functions[i].FunctionName == "check" {
+				fn = &script.Functions[i]
+			}
+		}
+	}
+	if fn == nil {
+		t.Fatalf("no coverage for function check in %+v", scripts)
+	}
+	if !fn.IsBlockCoverage {
+		t.Error("expected block coverage")
+	}
+	if fn.Ranges[0].Count != 3 {
+		t.Errorf("expected check to be called 3 times, got %d", fn.Ranges[0].Count)
+	}
+	var uncovered bool
+	for _, r := range fn.Ranges[1:] {
+		if r.Count == 0 && src[r.StartOffset:r.EndOffset] != "" {
+			uncovered = true
+		}
+	}
+	if !uncovered {
+		t.Errorf("expected an uncovered block in %+v", fn.Ranges)
+	}
+
+	fatalIf(t, cov.Stop())
+}
@@ -27,6 +27,9 @@ type CPUProfile struct {
 	// since some unspecified starting point.
 	// The point is equal to the starting point used by startTimeOffset.
 	endTimeOffset time.Duration
+
+	// samplingInterval is the interval between the profile's samples.
+	samplingInterval time.Duration
 }
 
 // Returns CPU profile title.
@@ -0,0 +1,195 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+)
+
+// WritePprof writes the profile to w in the gzipped protobuf format of pprof
+// (https://github.com/google/pprof/blob/main/proto/profile.proto), so it can be viewed and
+// compared with `go tool pprof` like Go CPU profiles. Each call stack in the profile is a
+// sample, whose values are the number of times V8 sampled it and the CPU time that
+// represents at the profiler's sampling interval.
+func (c *CPUProfile) WritePprof(w io.Writer) error {
+	b := newPprofBuilder(c.samplingInterval)
+	b.addNode(c.root, nil)
+
+	var p protoBuffer
+	p.message(1, b.valueType("samples", "count"))
+	p.message(1, b.valueType("cpu", "nanoseconds"))
+	p.data = append(p.data, b.samples.data...)
+	p.data = append(p.data, b.locations.data...)
+	p.data = append(p.data, b.functions.data...)
+	periodType := b.valueType("cpu", "nanoseconds")
+	for _, s := range b.strings {
+		p.stringField(6, s)
+	}
+	p.int64Field(10, int64(c.GetDuration()))
+	p.message(11, periodType)
+	p.int64Field(12, int64(c.samplingInterval))
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(p.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// pprofFunction identifies a function in a pprof profile.
+type pprofFunction struct {
+	name, file   string
+	line, column int
+}
+
+// pprofBuilder builds the samples, locations, functions and string table of a pprof profile.
+type pprofBuilder struct {
+	samples, locations, functions protoBuffer
+	samplingInterval              time.Duration
+
+	strings     []string
+	stringIndex map[string]int64
+	functionIDs map[pprofFunction]uint64
+}
+
+func newPprofBuilder(samplingInterval time.Duration) *pprofBuilder {
+	return &pprofBuilder{
+		samplingInterval: samplingInterval,
+		strings:          []string{""},
+		stringIndex:      map[string]int64{"": 0},
+		functionIDs:      make(map[pprofFunction]uint64),
+	}
+}
+
+func (b *pprofBuilder) string(s string) int64 {
+	i, ok := b.stringIndex[s]
+	if !ok {
+		i = int64(len(b.strings))
+		b.strings = append(b.strings, s)
+		b.stringIndex[s] = i
+	}
+	return i
+}
+
+func (b *pprofBuilder) valueType(typ, unit string) func(*protoBuffer) {
+	typIndex, unitIndex := b.string(typ), b.string(unit)
+	return func(p *protoBuffer) {
+		p.int64Field(1, typIndex)
+		p.int64Field(2, unitIndex)
+	}
+}
+
+// location returns the id of the node's location, which is also the id of its function;
+// V8 only records the line where a function starts.
+func (b *pprofBuilder) location(node *CPUProfileNode) uint64 {
+	fn := pprofFunction{node.functionName, node.scriptResourceName, node.lineNumber, node.columnNumber}
+	if id, ok := b.functionIDs[fn]; ok {
+		return id
+	}
+	id := uint64(len(b.functionIDs) + 1)
+	b.functionIDs[fn] = id
+
+	name := fn.name
+	if name == "" {
+		name = "(anonymous)"
+	}
+	nameIndex, fileIndex := b.string(name), b.string(fn.file)
+	b.functions.message(5, func(p *protoBuffer) {
+		p.uint64Field(1, id)
+		p.int64Field(2, nameIndex)
+		p.int64Field(3, nameIndex)
+		p.int64Field(4, fileIndex)
+		p.int64Field(5, int64(fn.line))
+	})
+	b.locations.message(4, func(p *protoBuffer) {
+		p.uint64Field(1, id)
+		p.message(4, func(p *protoBuffer) {
+			p.uint64Field(1, id)
+			p.int64Field(2, int64(fn.line))
+		})
+	})
+	return id
+}
+
+// addNode adds a sample for the node's hits, if any, and then its children's. The stack
+// holds the locations of the node's ancestors, leaf first, as pprof wants them; the root
+// node itself isn't a location.
+func (b *pprofBuilder) addNode(node *CPUProfileNode, stack []uint64) {
+	if node.parent != nil {
+		stack = append([]uint64{b.location(node)}, stack...)
+		if node.hitCount > 0 {
+			hits := uint64(node.hitCount)
+			b.samples.message(2, func(p *protoBuffer) {
+				p.packedField(1, stack)
+				p.packedField(2, []uint64{hits, hits * uint64(b.samplingInterval)})
+			})
+		}
+	}
+	for _, child := range node.children {
+		b.addNode(child, stack)
+	}
+}
+
+// protoBuffer encodes a protocol buffer message. Fields with zero values are omitted, as
+// they're the defaults, except for strings, whose order matters in pprof's string table.
+type protoBuffer struct {
+	data []byte
+}
+
+const (
+	protoVarint          = 0
+	protoLengthDelimited = 2
+)
+
+func (p *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		p.data = append(p.data, byte(x)|0x80)
+		x >>= 7
+	}
+	p.data = append(p.data, byte(x))
+}
+
+func (p *protoBuffer) key(tag int, wireType int) {
+	p.varint(uint64(tag)<<3 | uint64(wireType))
+}
+
+func (p *protoBuffer) uint64Field(tag int, x uint64) {
+	if x != 0 {
+		p.key(tag, protoVarint)
+		p.varint(x)
+	}
+}
+
+func (p *protoBuffer) int64Field(tag int, x int64) {
+	p.uint64Field(tag, uint64(x))
+}
+
+func (p *protoBuffer) packedField(tag int, xs []uint64) {
+	var packed protoBuffer
+	for _, x := range xs {
+		packed.varint(x)
+	}
+	p.bytesField(tag, packed.data)
+}
+
+func (p *protoBuffer) stringField(tag int, s string) {
+	p.key(tag, protoLengthDelimited)
+	p.varint(uint64(len(s)))
+	p.data = append(p.data, s...)
+}
+
+func (p *protoBuffer) bytesField(tag int, b []byte) {
+	p.key(tag, protoLengthDelimited)
+	p.varint(uint64(len(b)))
+	p.data = append(p.data, b...)
+}
+
+func (p *protoBuffer) message(tag int, encode func(*protoBuffer)) {
+	var m protoBuffer
+	encode(&m)
+	p.bytesField(tag, m.data)
+}
@@ -5,7 +5,12 @@
 package v8go_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
 	"testing"
+	"time"
 
 	v8 "github.com/couchbasedeps/v8go"
 )
@@ -68,3 +73,81 @@ func TestCPUProfile_Delete(t *testing.T) {
 	// noop when called multiple times
 	cpuProfile.Delete()
 }
+
+func TestCPUProfileWritePprof(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext(nil)
+	iso := ctx.Isolate()
+	defer iso.Dispose()
+	defer ctx.Close()
+
+	cpuProfiler := v8.NewCPUProfiler(iso)
+	defer cpuProfiler.Dispose()
+	cpuProfiler.SetSamplingInterval(100 * time.Microsecond)
+
+	cpuProfiler.StartProfiling("pprof")
+	_, err := ctx.RunScript(profileScript, "script.js")
+	fatalIf(t, err)
+	_, err = ctx.RunScript(`start(50)`, "")
+	fatalIf(t, err)
+	cpuProfile := cpuProfiler.StopProfiling("pprof")
+	defer cpuProfile.Delete()
+
+	var buf bytes.Buffer
+	fatalIf(t, cpuProfile.WritePprof(&buf))
+	zr, err := gzip.NewReader(&buf)
+	fatalIf(t, err)
+	data, err := io.ReadAll(zr)
+	fatalIf(t, err)
+
+	// Decode the top-level fields of the Profile message: count its samples and functions,
+	// and check its string table and sampling period.
+	var samples, functions int
+	var period uint64
+	var stringTable []string
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		data = data[n:]
+		switch key & 7 {
+		case 0:
+			val, n := binary.Uvarint(data)
+			data = data[n:]
+			if key>>3 == 12 {
+				period = val
+			}
+		case 2:
+			size, n := binary.Uvarint(data)
+			field := data[n : n+int(size)]
+			data = data[n+int(size):]
+			switch key >> 3 {
+			case 2:
+				samples++
+			case 5:
+				functions++
+			case 6:
+				stringTable = append(stringTable, string(field))
+			}
+		default:
+			t.Fatalf("unexpected wire type in key %d", key)
+		}
+	}
+	if samples == 0 || functions == 0 {
+		t.Errorf("expected samples and functions, got %d and %d", samples, functions)
+	}
+	if period != uint64(100*time.Microsecond) {
+		t.Errorf("expected a 100µs sampling period, got %d", period)
+	}
+	if len(stringTable) == 0 || stringTable[0] != "" {
+		t.Fatalf("expected the string table to start with \"\", got %q", stringTable)
+	}
+	for _, name := range []string{"start", "foo", "delay", "script.js", "cpu", "nanoseconds"} {
+		found := false
+		for _, s := range stringTable {
+			found = found || s == name
+		}
+		if !found {
+			t.Errorf("expected %q in the string table %q", name, stringTable)
+		}
+	}
+}
@@ -15,17 +15,32 @@ import (
 )
 
 type CPUProfiler struct {
-	p   *C.CPUProfiler
-	iso *Isolate
+	p                *C.CPUProfiler
+	iso              *Isolate
+	samplingInterval time.Duration
 }
 
+// defaultSamplingInterval is V8's default CPU profiler sampling interval.
+const defaultSamplingInterval = time.Millisecond
+
 // CPUProfiler is used to control CPU profiling.
 func NewCPUProfiler(iso *Isolate) *CPUProfiler {
 	profiler := C.NewCPUProfiler(iso.ptr)
 	return &CPUProfiler{
-		p:   profiler,
-		iso: iso,
+		p:                profiler,
+		iso:              iso,
+		samplingInterval: defaultSamplingInterval,
+	}
+}
+
+// SetSamplingInterval changes the interval between the samples the profiler takes (1ms
+// by default.) It must be called before StartProfiling.
+func (c *CPUProfiler) SetSamplingInterval(interval time.Duration) {
+	if c.p == nil {
+		panic("profiler is nil")
 	}
+	C.CPUProfilerSetSamplingInterval(c.p, C.int(interval/time.Microsecond))
+	c.samplingInterval = interval
 }
 
 // Dispose will dispose the profiler.
@@ -65,11 +80,12 @@ func (c *CPUProfiler) StopProfiling(title string) *CPUProfile {
 	profile := C.CPUProfilerStopProfiling(c.p, tstr)
 
 	return &CPUProfile{
-		p:               profile,
-		title:           C.GoString(profile.title),
-		root:            newCPUProfileNode(profile.root, nil),
-		startTimeOffset: time.Duration(profile.startTime) * time.Millisecond,
-		endTimeOffset:   time.Duration(profile.endTime) * time.Millisecond,
+		p:                profile,
+		title:            C.GoString(profile.title),
+		root:             newCPUProfileNode(profile.root, nil),
+		startTimeOffset:  time.Duration(profile.startTime) * time.Microsecond,
+		endTimeOffset:    time.Duration(profile.endTime) * time.Microsecond,
+		samplingInterval: c.samplingInterval,
 	}
 }
 
@@ -0,0 +1,210 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DataView is a JavaScript DataView, a subtype of Object, which reads and writes numbers
+// of various types and byte orders in an ArrayBuffer. Like their JavaScript equivalents,
+// its Get and Set methods take a byte offset within the view, and a littleEndian flag for
+// multi-byte types. Each call asks V8 where the buffer's memory is, so that a detached
+// buffer is never accessed, and then reads or writes that memory directly from Go.
+type DataView struct {
+	*Object
+}
+
+// NewDataView creates a DataView of byteLength bytes of the buffer, starting at byteOffset.
+// It returns an error if that range is not within the buffer.
+func NewDataView(buf *ArrayBuffer, byteOffset, byteLength int) (*DataView, error) {
+	if byteOffset < 0 || byteLength < 0 || byteOffset+byteLength > buf.ByteLength() {
+		return nil, errors.New("v8go: DataView range is outside the ArrayBuffer")
+	}
+	ref := C.NewDataView(buf.valuePtr(), C.size_t(byteOffset), C.size_t(byteLength))
+	return &DataView{&Object{&Value{ref, buf.ctx}}}, nil
+}
+
+// AsDataView returns the value as a DataView, or an error if it is not a DataView.
+func (v *Value) AsDataView() (*DataView, error) {
+	if !v.IsDataView() {
+		return nil, errors.New("v8go: value is not a DataView")
+	}
+	return &DataView{&Object{v}}, nil
+}
+
+// ByteLength returns the size of the view in bytes, which is 0 once its buffer is detached.
+func (d *DataView) ByteLength() int {
+	return len(d.Bytes())
+}
+
+// Bytes returns the memory the view covers; see Value.ArrayBufferBytes.
+func (d *DataView) Bytes() []byte {
+	bytes, _ := d.ArrayBufferBytes()
+	return bytes
+}
+
+// bytes returns the n bytes of the view at offset.
+func (d *DataView) bytes(offset, n int) ([]byte, error) {
+	b := d.Bytes()
+	if offset < 0 || offset+n > len(b) {
+		return nil, fmt.Errorf("v8go: offset %d is outside the bounds of the DataView", offset)
+	}
+	return b[offset : offset+n], nil
+}
+
+func byteOrder(littleEndian bool) binary.ByteOrder {
+	if littleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// GetInt8 returns the signed byte at offset.
+func (d *DataView) GetInt8(offset int) (int8, error) {
+	u, err := d.GetUint8(offset)
+	return int8(u), err
+}
+
+// GetUint8 returns the byte at offset.
+func (d *DataView) GetUint8(offset int) (uint8, error) {
+	b, err := d.bytes(offset, 1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// GetInt16 returns the signed 16-bit integer at offset.
+func (d *DataView) GetInt16(offset int, littleEndian bool) (int16, error) {
+	u, err := d.GetUint16(offset, littleEndian)
+	return int16(u), err
+}
+
+// GetUint16 returns the unsigned 16-bit integer at offset.
+func (d *DataView) GetUint16(offset int, littleEndian bool) (uint16, error) {
+	b, err := d.bytes(offset, 2)
+	if err != nil {
+		return 0, err
+	}
+	return byteOrder(littleEndian).Uint16(b), nil
+}
+
+// GetInt32 returns the signed 32-bit integer at offset.
+func (d *DataView) GetInt32(offset int, littleEndian bool) (int32, error) {
+	u, err := d.GetUint32(offset, littleEndian)
+	return int32(u), err
+}
+
+// GetUint32 returns the unsigned 32-bit integer at offset.
+func (d *DataView) GetUint32(offset int, littleEndian bool) (uint32, error) {
+	b, err := d.bytes(offset, 4)
+	if err != nil {
+		return 0, err
+	}
+	return byteOrder(littleEndian).Uint32(b), nil
+}
+
+// GetBigInt64 returns the signed 64-bit integer at offset.
+func (d *DataView) GetBigInt64(offset int, littleEndian bool) (int64, error) {
+	u, err := d.GetBigUint64(offset, littleEndian)
+	return int64(u), err
+}
+
+// GetBigUint64 returns the unsigned 64-bit integer at offset.
+func (d *DataView) GetBigUint64(offset int, littleEndian bool) (uint64, error) {
+	b, err := d.bytes(offset, 8)
+	if err != nil {
+		return 0, err
+	}
+	return byteOrder(littleEndian).Uint64(b), nil
+}
+
+// GetFloat32 returns the 32-bit floating point number at offset.
+func (d *DataView) GetFloat32(offset int, littleEndian bool) (float32, error) {
+	u, err := d.GetUint32(offset, littleEndian)
+	return math.Float32frombits(u), err
+}
+
+// GetFloat64 returns the 64-bit floating point number at offset.
+func (d *DataView) GetFloat64(offset int, littleEndian bool) (float64, error) {
+	u, err := d.GetBigUint64(offset, littleEndian)
+	return math.Float64frombits(u), err
+}
+
+// SetInt8 stores the signed byte at offset.
+func (d *DataView) SetInt8(offset int, v int8) error {
+	return d.SetUint8(offset, uint8(v))
+}
+
+// SetUint8 stores the byte at offset.
+func (d *DataView) SetUint8(offset int, v uint8) error {
+	b, err := d.bytes(offset, 1)
+	if err != nil {
+		return err
+	}
+	b[0] = v
+	return nil
+}
+
+// SetInt16 stores the signed 16-bit integer at offset.
+func (d *DataView) SetInt16(offset int, v int16, littleEndian bool) error {
+	return d.SetUint16(offset, uint16(v), littleEndian)
+}
+
+// SetUint16 stores the unsigned 16-bit integer at offset.
+func (d *DataView) SetUint16(offset int, v uint16, littleEndian bool) error {
+	b, err := d.bytes(offset, 2)
+	if err != nil {
+		return err
+	}
+	byteOrder(littleEndian).PutUint16(b, v)
+	return nil
+}
+
+// SetInt32 stores the signed 32-bit integer at offset.
+func (d *DataView) SetInt32(offset int, v int32, littleEndian bool) error {
+	return d.SetUint32(offset, uint32(v), littleEndian)
+}
+
+// SetUint32 stores the unsigned 32-bit integer at offset.
+func (d *DataView) SetUint32(offset int, v uint32, littleEndian bool) error {
+	b, err := d.bytes(offset, 4)
+	if err != nil {
+		return err
+	}
+	byteOrder(littleEndian).PutUint32(b, v)
+	return nil
+}
+
+// SetBigInt64 stores the signed 64-bit integer at offset.
+func (d *DataView) SetBigInt64(offset int, v int64, littleEndian bool) error {
+	return d.SetBigUint64(offset, uint64(v), littleEndian)
+}
+
+// SetBigUint64 stores the unsigned 64-bit integer at offset.
+func (d *DataView) SetBigUint64(offset int, v uint64, littleEndian bool) error {
+	b, err := d.bytes(offset, 8)
+	if err != nil {
+		return err
+	}
+	byteOrder(littleEndian).PutUint64(b, v)
+	return nil
+}
+
+// SetFloat32 stores the 32-bit floating point number at offset.
+func (d *DataView) SetFloat32(offset int, v float32, littleEndian bool) error {
+	return d.SetUint32(offset, math.Float32bits(v), littleEndian)
+}
+
+// SetFloat64 stores the 64-bit floating point number at offset.
+func (d *DataView) SetFloat64(offset int, v float64, littleEndian bool) error {
+	return d.SetBigUint64(offset, math.Float64bits(v), littleEndian)
+}
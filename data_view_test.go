@@ -0,0 +1,75 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestDataView(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	buf := ctx.NewArrayBuffer(16)
+	view, err := v8.NewDataView(buf, 2, 14)
+	fatalIf(t, err)
+	if n := view.ByteLength(); n != 14 {
+		t.Errorf("unexpected byte length: %d", n)
+	}
+
+	fatalIf(t, view.SetUint16(0, 0xCAFE, false))
+	fatalIf(t, view.SetInt32(2, -2, true))
+	fatalIf(t, view.SetFloat64(6, 1.25, false))
+	if err := view.SetUint32(12, 1, true); err == nil {
+		t.Error("expected an error writing past the end of the view")
+	}
+
+	fatalIf(t, ctx.Global().Set("view", view))
+	val, err := ctx.RunScript(`view.getUint16(0) === 0xCAFE && view.getInt32(2, true) === -2 &&
+		view.getFloat64(6) === 1.25 && new Uint8Array(view.buffer)[2] === 0xCA`, "test.js")
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Error("expected JS to read the values written from Go", val)
+	}
+
+	_, err = ctx.RunScript("view.setBigUint64(0, 0x0102030405060708n, true); view.setFloat32(8, -0.5)", "test.js")
+	fatalIf(t, err)
+	if u, _ := view.GetBigUint64(0, true); u != 0x0102030405060708 {
+		t.Errorf("unexpected uint64: %x", u)
+	}
+	if u, _ := view.GetUint8(0); u != 8 {
+		t.Errorf("unexpected byte: %d", u)
+	}
+	if i, _ := view.GetInt16(6, false); i != 0x0201 {
+		t.Errorf("unexpected int16: %x", i)
+	}
+	if f, _ := view.GetFloat32(8, false); f != -0.5 {
+		t.Errorf("unexpected float32: %v", f)
+	}
+	if _, err := view.GetUint8(14); err == nil {
+		t.Error("expected an error reading past the end of the view")
+	}
+	if _, err := view.GetInt8(-1); err == nil {
+		t.Error("expected an error reading before the start of the view")
+	}
+
+	if _, err := v8.NewDataView(buf, 8, 9); err == nil {
+		t.Error("expected an error for a view outside the buffer")
+	}
+
+	val, _ = ctx.RunScript("new DataView(new ArrayBuffer(4), 1)", "test.js")
+	view, err = val.AsDataView()
+	fatalIf(t, err)
+	if n := view.ByteLength(); n != 3 {
+		t.Errorf("unexpected byte length: %d", n)
+	}
+	if _, err := buf.Value.AsDataView(); err == nil {
+		t.Error("expected an error for an ArrayBuffer")
+	}
+}
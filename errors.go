@@ -4,12 +4,19 @@
 
 package v8go
 
-// #include <stdlib.h>
-// #include "v8go.h"
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static ValueRef IsolateNewErrorGo(IsolatePtr iso, ErrorKind kind, _GoString_ msg, uintptr_t* ctx_ref) {
+	return IsolateNewError(iso, kind, _GoStringPtr(msg), _GoStringLen(msg), ctx_ref); }
+*/
 import "C"
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -22,7 +29,39 @@ type JSError struct {
 	StackTrace string
 }
 
-func newJSError(rtnErr C.RtnError) error {
+// SyntaxError describes a syntax error found by CheckSyntax.
+type SyntaxError struct {
+	Message      string // e.g. "SyntaxError: Unexpected identifier"
+	ResourceName string // The origin the source was checked with
+	Line         int    // 1-based line number, 0 if unknown
+	StartColumn  int    // 1-based column the error starts at, 0 if unknown
+	EndColumn    int    // 1-based column just past the end of the error, 0 if unknown
+	SourceLine   string // The line of source containing the error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+// Location returns the position of the error as "origin:line:column",
+// in the same format as JSError.Location.
+func (e *SyntaxError) Location() string {
+	return fmt.Sprintf("%s:%d:%d", e.ResourceName, e.Line, e.StartColumn)
+}
+
+// SourceMapResolver maps a position in a script, as it appears in a JSError, to the
+// corresponding position in the original source, e.g. by looking it up in the script's
+// source map. Lines and columns are 1-based. If ok is false the position is left as-is.
+type SourceMapResolver func(file string, line, column int) (origFile string, origLine, origColumn int, ok bool)
+
+// SetSourceMapResolver sets a resolver that the Location and StackTrace of every JSError
+// returned from this isolate are rewritten through, so that errors in transpiled or bundled
+// code point at the original files and lines. Passing nil removes the resolver.
+func (i *Isolate) SetSourceMapResolver(resolver SourceMapResolver) {
+	i.sourceMapResolver = resolver
+}
+
+func newJSError(iso *Isolate, rtnErr C.RtnError) error {
 	err := &JSError{
 		Message:    C.GoString(rtnErr.msg),
 		Location:   C.GoString(rtnErr.location),
@@ -31,9 +70,37 @@ func newJSError(rtnErr C.RtnError) error {
 	C.free(unsafe.Pointer(rtnErr.msg))
 	C.free(unsafe.Pointer(rtnErr.location))
 	C.free(unsafe.Pointer(rtnErr.stack))
+	if iso != nil && iso.sourceMapResolver != nil {
+		err.Location = resolveSourcePositions(err.Location, iso.sourceMapResolver)
+		err.StackTrace = resolveSourcePositions(err.StackTrace, iso.sourceMapResolver)
+	}
 	return err
 }
 
+// sourcePosition matches the "file:line:column" positions in locations and stack frames.
+var sourcePosition = regexp.MustCompile(`[^\s()]+:\d+:\d+`)
+
+func resolveSourcePositions(s string, resolver SourceMapResolver) string {
+	return sourcePosition.ReplaceAllStringFunc(s, func(pos string) string {
+		file, line, column, ok := splitSourcePosition(pos)
+		if !ok {
+			return pos
+		}
+		if file, line, column, ok = resolver(file, line, column); !ok {
+			return pos
+		}
+		return file + ":" + strconv.Itoa(line) + ":" + strconv.Itoa(column)
+	})
+}
+
+func splitSourcePosition(pos string) (file string, line, column int, ok bool) {
+	i := strings.LastIndexByte(pos, ':')
+	j := strings.LastIndexByte(pos[:i], ':')
+	line, err1 := strconv.Atoi(pos[j+1 : i])
+	column, err2 := strconv.Atoi(pos[i+1:])
+	return pos[:j], line, column, err1 == nil && err2 == nil
+}
+
 func (e *JSError) Error() string {
 	return e.Message
 }
@@ -62,3 +129,46 @@ func (e *JSError) Format(s fmt.State, verb rune) {
 		fmt.Fprintf(s, "%q", e.Message)
 	}
 }
+
+// NewError creates a JS Error whose message is formatted as by fmt.Sprintf. It's created in
+// the Context that is currently executing, e.g. the one calling a FunctionCallback, so that
+// scripts can check it with `instanceof Error`; outside a callback it's created in the
+// Isolate's internal Context. A FunctionCallback can throw it with ThrowException:
+//
+//	return iso.ThrowException(iso.NewTypeError("expected a string, got %v", arg))
+func (i *Isolate) NewError(format string, args ...interface{}) *Value {
+	return i.newError(C.Error_err, format, args)
+}
+
+// NewTypeError creates a JS TypeError, as NewError creates an Error.
+func (i *Isolate) NewTypeError(format string, args ...interface{}) *Value {
+	return i.newError(C.TypeError_err, format, args)
+}
+
+// NewRangeError creates a JS RangeError, as NewError creates an Error.
+func (i *Isolate) NewRangeError(format string, args ...interface{}) *Value {
+	return i.newError(C.RangeError_err, format, args)
+}
+
+// NewSyntaxError creates a JS SyntaxError, as NewError creates an Error.
+func (i *Isolate) NewSyntaxError(format string, args ...interface{}) *Value {
+	return i.newError(C.SyntaxError_err, format, args)
+}
+
+// NewReferenceError creates a JS ReferenceError, as NewError creates an Error.
+func (i *Isolate) NewReferenceError(format string, args ...interface{}) *Value {
+	return i.newError(C.ReferenceError_err, format, args)
+}
+
+func (i *Isolate) newError(kind C.ErrorKind, format string, args []interface{}) *Value {
+	if i.ptr == nil {
+		panic("Isolate has been disposed")
+	}
+	var ctxRef C.uintptr_t
+	ref := C.IsolateNewErrorGo(i.ptr, kind, fmt.Sprintf(format, args...), &ctxRef)
+	ctx := i.internalContext
+	if ctxRef != 0 {
+		ctx = contextFromHandle(ctxRef)
+	}
+	return &Value{ref, ctx}
+}
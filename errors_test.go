@@ -117,3 +117,85 @@ func TestJSErrorFormat_forSyntaxError(t *testing.T) {
 		t.Errorf("unexpected verbose error message: %q", msg)
 	}
 }
+
+func TestJSErrorSourceMapResolver(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	iso.SetSourceMapResolver(func(file string, line, column int) (string, int, int, bool) {
+		if file != "bundle.js" {
+			return "", 0, 0, false
+		}
+		return "src/app.ts", line + 100, column + 1, true
+	})
+
+	_, err := ctx.RunScript("function fail() {\n  throw new Error('oops');\n}\nfail();", "bundle.js")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	e := err.(*v8.JSError)
+	if e.Location != "src/app.ts:102:4" {
+		t.Errorf("unexpected location: %q", e.Location)
+	}
+	expected := "Error: oops\n    at fail (src/app.ts:102:10)\n    at src/app.ts:104:2"
+	if e.StackTrace != expected {
+		t.Errorf("unexpected stack trace: %q", e.StackTrace)
+	}
+
+	_, err = ctx.RunScript("null.x", "other.js")
+	if e := err.(*v8.JSError); e.Location != "other.js:1:6" {
+		t.Errorf("expected unresolved location to be unchanged, got %q", e.Location)
+	}
+}
+
+func TestIsolateNewError(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	if s := iso.NewError("code %d", 42).String(); s != "Error: code 42" {
+		t.Errorf("unexpected error %q", s)
+	}
+
+	global := v8.NewObjectTemplate(iso)
+	fatalIf(t, global.Set("check", v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		kind := info.Args()[0].String()
+		var err *v8.Value
+		switch kind {
+		case "TypeError":
+			err = iso.NewTypeError("bad type")
+		case "RangeError":
+			err = iso.NewRangeError("out of range: %d", 7)
+		case "SyntaxError":
+			err = iso.NewSyntaxError("bad syntax")
+		case "ReferenceError":
+			err = iso.NewReferenceError("no such thing")
+		default:
+			err = iso.NewError("plain")
+		}
+		return iso.ThrowException(err)
+	})))
+	ctx1 := v8.NewContext(iso, global)
+	defer ctx1.Close()
+	ctx2 := v8.NewContext(iso, global)
+	defer ctx2.Close()
+
+	for _, ctx := range []*v8.Context{ctx1, ctx2} {
+		val, err := ctx.RunScript(`
+			["TypeError", "RangeError", "SyntaxError", "ReferenceError", "Error"].map(kind => {
+				try { check(kind) } catch (e) { return e instanceof globalThis[kind] && e.name === kind }
+			}).join()`, "")
+		fatalIf(t, err)
+		if val.String() != "true,true,true,true,true" {
+			t.Errorf("expected errors of this context's types, got %s", val)
+		}
+	}
+	_, err := ctx1.RunScript(`check("RangeError")`, "")
+	if err == nil || err.Error() != "RangeError: out of range: 7" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
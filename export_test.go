@@ -13,3 +13,9 @@ func (i *Isolate) RegisterCallback(cb FunctionCallback) int {
 func (i *Isolate) GetCallback(ref int) FunctionCallback {
 	return i.getCallback(ref)
 }
+
+// AwaitAsyncResult is exported for testing only. It waits until the result of an async
+// call has been queued, without settling its promise.
+func (i *Isolate) AwaitAsyncResult() {
+	<-i.asyncReady
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"unicode/utf16"
+	"unsafe"
+)
+
+// NewExternalString creates a JS string whose characters are read directly from Go memory,
+// rather than being copied into the V8 heap. This saves memory and time for large strings
+// that are used in many Contexts, such as templates or schemas. If s is ASCII, the string
+// uses its bytes directly; otherwise it uses a UTF-16 copy of it, as NewExternalTwoByteString
+// does. The memory is released when V8 garbage collects the string, or the Isolate is disposed.
+func NewExternalString(iso *Isolate, s string) (*Value, error) {
+	if len(s) == 0 {
+		return NewValue(iso, "")
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return NewExternalTwoByteString(iso, utf16.Encode([]rune(s)))
+		}
+	}
+	data := unsafe.Pointer(unsafe.StringData(s))
+	return newExternalString(iso, false, data, len(s))
+}
+
+// NewExternalOneByteString creates a JS string whose characters are the bytes of data,
+// interpreted as Latin-1, without copying them. V8 keeps using the slice's memory until it
+// garbage collects the string, or the Isolate is disposed; data must not be modified.
+func NewExternalOneByteString(iso *Isolate, data []byte) (*Value, error) {
+	if len(data) == 0 {
+		return NewValue(iso, "")
+	}
+	return newExternalString(iso, false, unsafe.Pointer(&data[0]), len(data))
+}
+
+// NewExternalTwoByteString creates a JS string whose characters are the UTF-16 code units
+// of data, without copying them; see NewExternalOneByteString.
+func NewExternalTwoByteString(iso *Isolate, data []uint16) (*Value, error) {
+	if len(data) == 0 {
+		return NewValue(iso, "")
+	}
+	return newExternalString(iso, true, unsafe.Pointer(&data[0]), len(data))
+}
+
+func newExternalString(iso *Isolate, twoByte bool, data unsafe.Pointer, length int) (*Value, error) {
+	var cTwoByte C.int
+	if twoByte {
+		cTwoByte = 1
+	}
+	handle := pinExternalMemory(data)
+	rtn := C.NewExternalString(iso.internalContext.ptr, cTwoByte, data, C.size_t(length), C.uintptr_t(handle))
+	return valueResult(iso.internalContext, rtn)
+}
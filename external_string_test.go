@@ -0,0 +1,64 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestExternalString(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	large := strings.Repeat("abcdefghij", 10000)
+	tests := [...]struct {
+		name string
+		make func() (*v8.Value, error)
+		want string
+	}{
+		{"ascii", func() (*v8.Value, error) { return v8.NewExternalString(iso, large) }, large},
+		{"non-ascii", func() (*v8.Value, error) { return v8.NewExternalString(iso, "héllo, 世界 🌍") }, "héllo, 世界 🌍"},
+		{"empty", func() (*v8.Value, error) { return v8.NewExternalString(iso, "") }, ""},
+		{"one-byte", func() (*v8.Value, error) { return v8.NewExternalOneByteString(iso, []byte("caf\xe9")) }, "café"},
+		{"two-byte", func() (*v8.Value, error) {
+			return v8.NewExternalTwoByteString(iso, utf16.Encode([]rune("naïve 🙂")))
+		}, "naïve 🙂"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := tt.make()
+			fatalIf(t, err)
+			if !val.IsString() {
+				t.Fatalf("expected a string, got %v", val.DetailString())
+			}
+			if s := val.String(); s != tt.want {
+				t.Errorf("unexpected string %.40q", s)
+			}
+		})
+	}
+
+	// The strings can be used by scripts in any Context, and survive garbage collection.
+	str, err := v8.NewExternalString(iso, large)
+	fatalIf(t, err)
+	for i := 0; i < 3; i++ {
+		ctx := v8.NewContext(iso)
+		fatalIf(t, ctx.Global().Set("s", str))
+		runtime.GC()
+		val, err := ctx.RunScript("s.length + ':' + s.slice(-3)", "")
+		fatalIf(t, err)
+		if val.String() != "100000:hij" {
+			t.Errorf("unexpected result %q", val.String())
+		}
+		ctx.Close()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// FastType is the type of an argument or the result of a FastFunction.
+type FastType int
+
+const (
+	FastVoid    FastType = iota // No value; only valid as a result
+	FastBool                    // C bool
+	FastInt32                   // C int32_t
+	FastUint32                  // C uint32_t
+	FastFloat32                 // C float
+	FastFloat64                 // C double
+)
+
+// FastFunction describes a C function that V8's optimizing compiler can call directly from
+// JS code, bypassing the usual callback machinery. Its first parameter is the receiver, a
+// v8::Local<v8::Object> that C code can declare as `void*` and ignore, followed by Args.
+// It must not call into V8, allocate JS values or run JS, so it can't throw; and since
+// Go code can only be called from C through cgo, it is only worthwhile if it is written in
+// C, e.g. to update counters kept in C memory.
+type FastFunction struct {
+	Address unsafe.Pointer // The C function, e.g. `unsafe.Pointer(C.my_function)`
+	Result  FastType
+	Args    []FastType
+}
+
+// NewFastFunctionTemplate is like NewFunctionTemplate, but calls from JS code optimized by
+// V8 may call fast instead of callback. callback is still used for calls from code that
+// hasn't been optimized and whose arguments don't have the types of fast.Args, so it must
+// do the same as fast. V8 only makes fast calls if the --turbo-fast-api-calls flag is set
+// (see SetFlags.) The functions created from the template can't be used as constructors.
+func NewFastFunctionTemplate(iso *Isolate, callback FunctionCallback, fast FastFunction) (*FunctionTemplate, error) {
+	if iso == nil {
+		panic("nil Isolate argument not supported")
+	}
+	if callback == nil {
+		panic("nil FunctionCallback argument not supported")
+	}
+	if fast.Address == nil {
+		return nil, errors.New("v8go: FastFunction.Address is required")
+	}
+	if fast.Result < FastVoid || fast.Result > FastFloat64 {
+		return nil, fmt.Errorf("v8go: invalid FastFunction.Result %d", fast.Result)
+	}
+	args := make([]C.FastType, len(fast.Args)+1)
+	for i, arg := range fast.Args {
+		if arg <= FastVoid || arg > FastFloat64 {
+			return nil, fmt.Errorf("v8go: invalid FastFunction.Args[%d] %d", i, arg)
+		}
+		args[i] = C.FastType(arg)
+	}
+
+	cbref := iso.registerCallback(callback)
+	tmpl := &template{
+		ptr: C.NewFastFunctionTemplate(iso.ptr, C.int(cbref), fast.Address, C.FastType(fast.Result),
+			C.int(len(fast.Args)), &args[0]),
+		iso: iso,
+	}
+	runtime.SetFinalizer(tmpl, (*template).finalizer)
+	return &FunctionTemplate{tmpl}, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+	"github.com/couchbasedeps/v8go/internal/fastapitest"
+)
+
+func TestFastFunctionTemplate(t *testing.T) {
+	fatalIf(t, v8.SetFlagsChecked("--turbo-fast-api-calls", "--allow-natives-syntax"))
+	defer v8.SetFlags("--no-turbo-fast-api-calls", "--no-allow-natives-syntax")
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	slowCalls := 0
+	tmpl, err := v8.NewFastFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		slowCalls++
+		args := info.Args()
+		sum, _ := v8.NewValue(iso, args[0].Int32()+args[1].Int32())
+		return sum
+	}, v8.FastFunction{
+		Address: fastapitest.AddInt32,
+		Result:  v8.FastInt32,
+		Args:    []v8.FastType{v8.FastInt32, v8.FastInt32},
+	})
+	fatalIf(t, err)
+	metrics := ctx.NewObject()
+	fatalIf(t, metrics.Set("add", tmpl.GetFunction(ctx)))
+	fatalIf(t, ctx.Global().Set("metrics", metrics))
+
+	fastBefore := fastapitest.Calls()
+	val, err := ctx.RunScript(`
+		function sum(a, b) { return metrics.add(a, b) }
+		%PrepareFunctionForOptimization(sum);
+		const results = [sum(1, 2), sum(3, 4)];
+		%OptimizeFunctionOnNextCall(sum);
+		results.push(sum(5, 6), sum(7, 8));
+		results.join()`, "")
+	fatalIf(t, err)
+	if val.String() != "3,7,11,15" {
+		t.Errorf("unexpected results %q", val)
+	}
+	if fast := fastapitest.Calls() - fastBefore; fast == 0 || slowCalls+fast != 4 {
+		t.Errorf("expected the optimized calls to be fast, got %d slow and %d fast", slowCalls, fast)
+	}
+
+	if _, err := v8.NewFastFunctionTemplate(iso, func(*v8.FunctionCallbackInfo) *v8.Value { return nil },
+		v8.FastFunction{Address: fastapitest.AddInt32, Args: []v8.FastType{v8.FastVoid}}); err == nil {
+		t.Error("expected an error for a void argument")
+	}
+	if _, err := v8.NewFastFunctionTemplate(iso, func(*v8.FunctionCallbackInfo) *v8.Value { return nil },
+		v8.FastFunction{}); err == nil {
+		t.Error("expected an error for a missing address")
+	}
+}
@@ -4,6 +4,7 @@
 
 package v8go
 
+// #include <stdlib.h>
 // #include "v8go.h"
 import "C"
 import (
@@ -35,7 +36,44 @@ func (fn *Function) Call(recv Valuer, args ...Valuer) (*Value, error) {
 	return valueResult(fn.ctx, rtn)
 }
 
-// Invoke a constructor function to create an object instance.
+// CallResult is the result of one of the calls made by Function.CallBatch.
+// Err will be of type `JSError` if not nil.
+type CallResult struct {
+	Value *Value
+	Err   error
+}
+
+// CallBatch calls this function once for each of the argument sets, in order, like calling
+// Call with each, but with a single call into V8, which is faster when making many calls.
+// All the calls are made even if some of them throw; the result of each call is returned
+// at the same index.
+func (fn *Function) CallBatch(recv Valuer, argSets [][]Valuer) []CallResult {
+	if len(argSets) == 0 {
+		return nil
+	}
+	argCounts := make([]C.int, len(argSets))
+	var all []Valuer
+	for i, args := range argSets {
+		argCounts[i] = C.int(len(args))
+		all = append(all, args...)
+	}
+	cArgs, argptr := convertArgs(all)
+
+	rtns := make([]C.RtnValue, len(argSets))
+	C.FunctionCallBatch(fn.valuePtr(), recv.value().valuePtr(), C.int(len(argSets)), &argCounts[0],
+		argptr, &rtns[0])
+	runtime.KeepAlive(cArgs)
+
+	results := make([]CallResult, len(argSets))
+	for i, rtn := range rtns {
+		results[i].Value, results[i].Err = valueResult(fn.ctx, rtn)
+	}
+	return results
+}
+
+// NewInstance calls the function as a constructor, as `new` does in JS, e.g. to create an
+// instance of a class defined by a script. error will be of type `JSError` if the
+// constructor throws, or if the function isn't a constructor (see IsConstructor).
 func (fn *Function) NewInstance(args ...Valuer) (*Object, error) {
 	cArgs, argptr := convertArgs(args)
 	rtn := C.FunctionNewInstance(fn.valuePtr(), C.int(len(args)), argptr)
@@ -43,6 +81,41 @@ func (fn *Function) NewInstance(args ...Valuer) (*Object, error) {
 	return objectResult(fn.ctx, rtn)
 }
 
+// IsConstructor returns true if the function can be called with NewInstance. Classes and
+// ordinary functions are constructors; arrow functions, methods and async functions aren't.
+func (fn *Function) IsConstructor() bool {
+	return C.FunctionIsConstructor(fn.valuePtr()) != 0
+}
+
+// Name returns the function's name or, for an anonymous function, the name V8 inferred from
+// its context, e.g. "obj.handler" for `obj.handler = function() {}`. It returns "" if the
+// function has neither.
+func (fn *Function) Name() string {
+	rtn := C.FunctionName(fn.valuePtr())
+	defer C.free(unsafe.Pointer(rtn.data))
+	return C.GoStringN(rtn.data, rtn.length)
+}
+
+// ScriptID returns the ID of the script that defined the function, which is the same for
+// all the functions compiled from one script, or 0 if it wasn't defined by a script, as for
+// built-in functions and those created from FunctionTemplates.
+func (fn *Function) ScriptID() int {
+	return int(C.FunctionScriptID(fn.valuePtr()))
+}
+
+// Location returns the name of the script (its origin) that defined the function and the
+// position of the function's definition in it. line and column are 1-based, like those of
+// SyntaxError, and are 0 if unknown.
+func (fn *Function) Location() (resourceName string, line, column int) {
+	var cLine, cColumn C.int
+	rtn := C.FunctionLocation(fn.valuePtr(), &cLine, &cColumn)
+	if rtn.data != nil {
+		defer C.free(unsafe.Pointer(rtn.data))
+		resourceName = C.GoStringN(rtn.data, rtn.length)
+	}
+	return resourceName, int(cLine) + 1, int(cColumn) + 1
+}
+
 // Return the source map url for a function.
 func (fn *Function) SourceMapUrl() *Value {
 	ptr := C.FunctionSourceMapUrl(fn.valuePtr())
@@ -8,7 +8,9 @@ package v8go
 // #include "v8go.h"
 import "C"
 import (
+	"fmt"
 	"runtime"
+	"runtime/debug"
 	"unsafe"
 )
 
@@ -17,9 +19,13 @@ type FunctionCallback func(info *FunctionCallbackInfo) *Value
 
 // FunctionCallbackInfo is the argument that is passed to a FunctionCallback.
 type FunctionCallbackInfo struct {
-	ctx  *Context
-	args []*Value
-	this *Object
+	ctx       *Context
+	args      []*Value
+	this      *Object
+	holder    *Object
+	newTarget *Value // nil unless construct
+	construct bool
+	rtn       C.CallbackResult // The result set by ReturnInt32, etc.
 }
 
 // Context is the current context that the callback is being executed in.
@@ -37,6 +43,70 @@ func (i *FunctionCallbackInfo) Args() []*Value {
 	return i.args
 }
 
+// Holder returns the object that passed the function's receiver signature check (see
+// NewFunctionTemplateWithSignature), which is usually the same as This. It differs for
+// methods of a global object: This is then the global proxy that scripts see as
+// `globalThis`, and Holder the global object itself, which is the one with the internal
+// fields set up by the global template.
+func (i *FunctionCallbackInfo) Holder() *Object {
+	return i.holder
+}
+
+// NewTarget returns the value of `new.target`: the constructor that `new` was applied to,
+// when IsConstructCall is true, which differs from the function being called when a
+// subclass's constructor calls `super()`. Otherwise it's undefined.
+func (i *FunctionCallbackInfo) NewTarget() *Value {
+	if i.newTarget == nil {
+		return Undefined(i.ctx.iso)
+	}
+	return i.newTarget
+}
+
+// IsConstructCall returns true if the function was called as a constructor, with `new` in
+// JS or Function.NewInstance in Go. Then This is the new instance, created from the
+// FunctionTemplate's InstanceTemplate, and the result of the callback is ignored unless it
+// is an object, which is used in place of the instance.
+func (i *FunctionCallbackInfo) IsConstructCall() bool {
+	return i.construct
+}
+
+// ReturnInt32 sets the result of the function to n, which is faster than creating a Value
+// for it when the callback returns nil. A non-nil Value returned by the callback takes
+// precedence. The same goes for ReturnUint32, ReturnFloat64, ReturnBool, ReturnNull and
+// ReturnUndefined.
+func (i *FunctionCallbackInfo) ReturnInt32(n int32) {
+	i.rtn = C.CallbackResult{kind: C.ReturnInt32_kind, number: C.double(n)}
+}
+
+// ReturnUint32 sets the result of the function to n; see ReturnInt32.
+func (i *FunctionCallbackInfo) ReturnUint32(n uint32) {
+	i.rtn = C.CallbackResult{kind: C.ReturnUint32_kind, number: C.double(n)}
+}
+
+// ReturnFloat64 sets the result of the function to f; see ReturnInt32.
+func (i *FunctionCallbackInfo) ReturnFloat64(f float64) {
+	i.rtn = C.CallbackResult{kind: C.ReturnDouble_kind, number: C.double(f)}
+}
+
+// ReturnBool sets the result of the function to b; see ReturnInt32.
+func (i *FunctionCallbackInfo) ReturnBool(b bool) {
+	i.rtn = C.CallbackResult{kind: C.ReturnBool_kind}
+	if b {
+		i.rtn.number = 1
+	}
+}
+
+// ReturnNull sets the result of the function to null; see ReturnInt32.
+func (i *FunctionCallbackInfo) ReturnNull() {
+	i.rtn = C.CallbackResult{kind: C.ReturnNull_kind}
+}
+
+// ReturnUndefined sets the result of the function to undefined, undoing an earlier call to
+// ReturnInt32, etc; see ReturnInt32.
+func (i *FunctionCallbackInfo) ReturnUndefined() {
+	i.rtn = C.CallbackResult{}
+}
+
 // FunctionTemplate is used to create functions at runtime.
 // There can only be one function created from a FunctionTemplate in a context.
 // The lifetime of the created function is equal to the lifetime of the context.
@@ -46,6 +116,22 @@ type FunctionTemplate struct {
 
 // NewFunctionTemplate creates a FunctionTemplate for a given callback.
 func NewFunctionTemplate(iso *Isolate, callback FunctionCallback) *FunctionTemplate {
+	return newFunctionTemplate(iso, callback, nil)
+}
+
+// NewFunctionTemplateWithSignature creates a FunctionTemplate for a method of the class
+// created from receiver, typically to be set on receiver's PrototypeTemplate. Calling the
+// method with a `this` that is not an instance of receiver, or of a template that inherits
+// from it, throws a TypeError instead of calling callback, so callback can rely on the
+// receiver being one of its instances, e.g. to use its internal fields.
+func NewFunctionTemplateWithSignature(iso *Isolate, callback FunctionCallback, receiver *FunctionTemplate) *FunctionTemplate {
+	if receiver == nil {
+		panic("nil receiver FunctionTemplate argument not supported")
+	}
+	return newFunctionTemplate(iso, callback, receiver)
+}
+
+func newFunctionTemplate(iso *Isolate, callback FunctionCallback, receiver *FunctionTemplate) *FunctionTemplate {
 	if iso == nil {
 		panic("nil Isolate argument not supported")
 	}
@@ -54,11 +140,16 @@ func NewFunctionTemplate(iso *Isolate, callback FunctionCallback) *FunctionTempl
 	}
 
 	cbref := iso.registerCallback(callback)
+	var receiverPtr C.TemplatePtr
+	if receiver != nil {
+		receiverPtr = receiver.ptr
+	}
 
 	tmpl := &template{
-		ptr: C.NewFunctionTemplate(iso.ptr, C.int(cbref)),
+		ptr: C.NewFunctionTemplate(iso.ptr, C.int(cbref), receiverPtr),
 		iso: iso,
 	}
+	runtime.KeepAlive(receiver)
 	runtime.SetFinalizer(tmpl, (*template).finalizer)
 	return &FunctionTemplate{tmpl}
 }
@@ -74,15 +165,88 @@ func (tmpl *FunctionTemplate) GetFunction(ctx *Context) *Function {
 	return &Function{val}
 }
 
+// CachedFunction is like GetFunction, but the function is created only the first time it's
+// called for a Context and returned again by later calls for the same Context; see
+// ObjectTemplate.CachedInstance.
+func (tmpl *FunctionTemplate) CachedFunction(ctx *Context) *Function {
+	val, _ := ctx.cachedInstance(tmpl.template, func() (*Value, error) {
+		return tmpl.GetFunction(ctx).Value, nil
+	})
+	return &Function{val}
+}
+
+// Inherit makes the functions created from this template inherit from those created from
+// parent, like a JS class that `extends` another: the prototype of the function's
+// `prototype` is the parent's `prototype`, so that instances have the parent's methods and
+// are `instanceof` the parent. It must be called before any function has been created from
+// this template.
+func (tmpl *FunctionTemplate) Inherit(parent *FunctionTemplate) {
+	C.FunctionTemplateInherit(tmpl.ptr, parent.ptr)
+	runtime.KeepAlive(tmpl)
+	runtime.KeepAlive(parent)
+}
+
+// PrototypeTemplate returns the template of the `prototype` object of the functions
+// created from this template. The properties set on it, such as methods, are shared by
+// all the instances created by calling the function with `new`.
+func (tmpl *FunctionTemplate) PrototypeTemplate() *ObjectTemplate {
+	return tmpl.objectTemplate(C.FunctionTemplatePrototypeTemplate(tmpl.ptr))
+}
+
+// InstanceTemplate returns the template of the objects created by calling the functions
+// created from this template with `new`. The callback is called with the new instance as
+// its receiver, after it has been created from this template.
+func (tmpl *FunctionTemplate) InstanceTemplate() *ObjectTemplate {
+	return tmpl.objectTemplate(C.FunctionTemplateInstanceTemplate(tmpl.ptr))
+}
+
+func (tmpl *FunctionTemplate) objectTemplate(ptr C.TemplatePtr) *ObjectTemplate {
+	runtime.KeepAlive(tmpl)
+	ot := &template{
+		ptr: ptr,
+		iso: tmpl.iso,
+	}
+	runtime.SetFinalizer(ot, (*template).finalizer)
+	return &ObjectTemplate{ot}
+}
+
+// SetClassName sets the `name` of the functions created from this template, which is also
+// the name of their instances' constructor in debugging output.
+func (tmpl *FunctionTemplate) SetClassName(name string) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	C.FunctionTemplateSetClassName(tmpl.ptr, cName, C.int(len(name)))
+	runtime.KeepAlive(tmpl)
+}
+
+// throwPanic throws an Error describing a recovered panic; see SetCrashOnCallbackPanic.
+func (c *Context) throwPanic(r interface{}) *Value {
+	err := c.newError(C.Error_err, fmt.Sprintf("Go panic: %v", r))
+	err.Set("goStack", string(debug.Stack()))
+	return c.iso.ThrowException(err.Value)
+}
+
 // Note that ideally `thisAndArgs` would be split into two separate arguments, but they were combined
 // to workaround an ERROR_COMMITMENT_LIMIT error on windows that was detected in CI.
 //export goFunctionCallback
-func goFunctionCallback(ctxHandle C.uintptr_t, cbref int, thisAndArgs *C.ValueRef, argsCount int) C.ValuePtr {
+func goFunctionCallback(ctxHandle C.uintptr_t, cbref int, thisAndArgs *C.ValueRef, argsCount int, isConstructCall C.Bool, holder, newTarget C.ValueRef) (result C.CallbackResult) {
 	ctx := contextFromHandle(ctxHandle)
+	if !ctx.iso.crashOnPanic {
+		defer func() {
+			if r := recover(); r != nil {
+				result = C.CallbackResult{value: ctx.throwPanic(r).valuePtr()}
+			}
+		}()
+	}
 	this := *thisAndArgs
 	info := &FunctionCallbackInfo{
-		ctx:  ctx,
-		this: &Object{&Value{this, ctx}},
+		ctx:       ctx,
+		this:      &Object{&Value{this, ctx}},
+		holder:    &Object{&Value{holder, ctx}},
+		construct: isConstructCall != 0,
+	}
+	if info.construct {
+		info.newTarget = &Value{newTarget, ctx}
 	}
 
 	if argsCount > 0 {
@@ -96,7 +260,7 @@ func goFunctionCallback(ctxHandle C.uintptr_t, cbref int, thisAndArgs *C.ValueRe
 
 	callbackFunc := ctx.iso.getCallback(cbref)
 	if val := callbackFunc(info); val != nil {
-		return val.valuePtr()
+		return C.CallbackResult{value: val.valuePtr()}
 	}
-	return C.ValuePtr{}
+	return info.rtn
 }
@@ -6,6 +6,7 @@ package v8go_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	v8 "github.com/couchbasedeps/v8go"
@@ -109,6 +110,313 @@ func TestFunctionCallbackInfoThis(t *testing.T) {
 	}
 }
 
+func TestFunctionTemplateInherit(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	construct := func(info *v8.FunctionCallbackInfo) *v8.Value {
+		info.This().Set("path", info.Args()[0])
+		return nil
+	}
+	resource := v8.NewFunctionTemplate(iso, construct)
+	document := v8.NewFunctionTemplate(iso, construct)
+	document.Inherit(resource)
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fatalIf(t, ctx.Global().Set("Resource", resource.GetFunction(ctx)))
+	fatalIf(t, ctx.Global().Set("Document", document.GetFunction(ctx)))
+	_, err := ctx.RunScript(`
+		Resource.prototype.describe = function() { return 'resource ' + this.path; };
+		Document.prototype.kind = 'document';
+	`, "")
+	fatalIf(t, err)
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"new Document('/a') instanceof Document", "true"},
+		{"new Document('/a') instanceof Resource", "true"},
+		{"new Resource('/a') instanceof Document", "false"},
+		{"new Document('/a').describe()", "resource /a"},
+		{"new Document('/a').kind", "document"},
+		{"new Resource('/a').kind", "undefined"},
+		{"Object.getPrototypeOf(Document.prototype) === Resource.prototype", "true"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+}
+
+func TestFunctionTemplatePrototypeTemplate(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	counter := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value { return nil })
+	counter.SetClassName("Counter")
+	fatalIf(t, counter.PrototypeTemplate().Set("increment",
+		v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+			count, _ := info.This().Get("count")
+			info.This().Set("count", count.Int32()+1)
+			return nil
+		})))
+	// Each call returns the same template, so properties accumulate.
+	fatalIf(t, counter.PrototypeTemplate().Set("step", int32(1)))
+	fatalIf(t, counter.InstanceTemplate().Set("count", int32(0)))
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fatalIf(t, ctx.Global().Set("Counter", counter.GetFunction(ctx)))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"const a = new Counter(), b = new Counter(); a.increment(); a.increment(); b.increment(); a.count + ',' + b.count", "2,1"},
+		{"a.increment === b.increment", "true"},
+		{"Object.keys(a).join()", "count"},
+		{"a.hasOwnProperty('increment') || a.hasOwnProperty('step')", "false"},
+		{"a.step", "1"},
+		{"Counter.name + ' ' + a.constructor.name", "Counter Counter"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+}
+
+func ExampleFunctionTemplate_PrototypeTemplate() {
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	// A host class whose method is shared by all instances, on the prototype,
+	// and whose field is set on each instance.
+	point := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		if args := info.Args(); len(args) == 2 {
+			info.This().Set("x", args[0])
+			info.This().Set("y", args[1])
+		}
+		return nil
+	})
+	point.SetClassName("Point")
+	point.InstanceTemplate().Set("x", int32(0))
+	point.InstanceTemplate().Set("y", int32(0))
+	point.PrototypeTemplate().Set("toString", v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		x, _ := info.This().Get("x")
+		y, _ := info.This().Get("y")
+		val, _ := v8.NewValue(iso, fmt.Sprintf("(%v, %v)", x, y))
+		return val
+	}))
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	ctx.Global().Set("Point", point.GetFunction(ctx))
+	val, _ := ctx.RunScript("`${new Point()} ${new Point(3, 4)}`", "")
+	fmt.Println(val)
+	// Output:
+	// (0, 0) (3, 4)
+}
+
+func TestFunctionTemplateConstruct(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	var calls []bool
+	doc := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		calls = append(calls, info.IsConstructCall())
+		if !info.IsConstructCall() {
+			msg, _ := v8.NewValue(iso, "Document must be called with new")
+			return iso.ThrowException(msg)
+		}
+		for i, name := range []string{"id", "rev"} {
+			if i < len(info.Args()) {
+				info.This().Set(name, info.Args()[i])
+			}
+		}
+		return nil
+	})
+	doc.SetClassName("Document")
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fn := doc.GetFunction(ctx)
+	fatalIf(t, ctx.Global().Set("Document", fn))
+
+	id, _ := v8.NewValue(iso, "doc1")
+	rev, _ := v8.NewValue(iso, int32(3))
+	obj, err := fn.NewInstance(id, rev)
+	fatalIf(t, err)
+	if s, _ := v8.JSONStringify(ctx, obj); s != `{"id":"doc1","rev":3}` {
+		t.Errorf("unexpected instance %s", s)
+	}
+	fatalIf(t, ctx.Global().Set("doc", obj))
+	if val, _ := ctx.RunScript("doc instanceof Document && new Document('x').id", ""); val.String() != "x" {
+		t.Errorf("unexpected result %v", val)
+	}
+
+	if _, err := fn.Call(v8.Undefined(iso), id); err == nil {
+		t.Error("expected an error calling the constructor without new")
+	}
+	if len(calls) != 3 || !calls[0] || !calls[1] || calls[2] {
+		t.Errorf("unexpected construct calls %v", calls)
+	}
+}
+
+func TestFunctionTemplateWithSignature(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	type document struct{ id string }
+	doc := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		info.This().SetInternalGoValue(0, &document{info.Args()[0].String()})
+		return nil
+	})
+	doc.InstanceTemplate().SetInternalFieldCount(1)
+	getID := v8.NewFunctionTemplateWithSignature(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		val, _ := v8.NewValue(iso, info.This().GetInternalGoValue(0).(*document).id)
+		return val
+	}, doc)
+	fatalIf(t, doc.PrototypeTemplate().Set("getID", getID))
+	special := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		info.This().SetInternalGoValue(0, &document{"special"})
+		return nil
+	})
+	special.InstanceTemplate().SetInternalFieldCount(1)
+	special.Inherit(doc)
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fatalIf(t, ctx.Global().Set("Document", doc.GetFunction(ctx)))
+	fatalIf(t, ctx.Global().Set("Special", special.GetFunction(ctx)))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"new Document('doc1').getID()", "doc1"},
+		{"new Special().getID()", "special"},
+		{"try { Document.prototype.getID.call({}) } catch (e) { e.name }", "TypeError"},
+		{"try { const {getID} = new Document('x'); getID() } catch (e) { e.name }", "TypeError"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+	if recoverPanic(func() {
+		v8.NewFunctionTemplateWithSignature(iso, func(*v8.FunctionCallbackInfo) *v8.Value { return nil }, nil)
+	}) == nil {
+		t.Error("expected panic for a nil receiver")
+	}
+}
+
+func TestFunctionCallbackInfoNewTargetAndHolder(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	var newTargets []string
+	base := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		nt := info.NewTarget()
+		if nt.IsFunction() {
+			name, _ := nt.Object().Get("name")
+			newTargets = append(newTargets, name.String())
+		} else {
+			newTargets = append(newTargets, nt.String())
+		}
+		return nil
+	})
+	base.SetClassName("Base")
+	base.InstanceTemplate().SetInternalFieldCount(1)
+	var holderIsThis []bool
+	method := v8.NewFunctionTemplateWithSignature(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		holderIsThis = append(holderIsThis, info.Holder().SameValue(info.This().Value))
+		return nil
+	}, base)
+	fatalIf(t, base.PrototypeTemplate().Set("method", method))
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fatalIf(t, ctx.Global().Set("Base", base.GetFunction(ctx)))
+	_, err := ctx.RunScript(`
+		class Sub extends Base { constructor() { super() } }
+		new Base();
+		new Sub().method();
+		Base();
+		const b = new Base();
+		b.method();
+		try { Object.create(b).method() } catch (e) {}`, "")
+	fatalIf(t, err)
+	if strings.Join(newTargets, ",") != "Base,Sub,undefined,Base" {
+		t.Errorf("unexpected new.targets %v", newTargets)
+	}
+	if len(holderIsThis) != 2 || !holderIsThis[0] || !holderIsThis[1] {
+		t.Errorf("unexpected holders %v", holderIsThis)
+	}
+
+	// A method of the global object is called on the global proxy, but held by the global.
+	window := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value { return nil })
+	var this, holder *v8.Object
+	globalMethod := v8.NewFunctionTemplateWithSignature(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		this, holder = info.This(), info.Holder()
+		return nil
+	}, window)
+	fatalIf(t, window.InstanceTemplate().Set("globalMethod", globalMethod))
+	ctx2 := v8.NewContext(iso, window.InstanceTemplate())
+	defer ctx2.Close()
+	_, err = ctx2.RunScript(`globalThis.globalMethod()`, "")
+	fatalIf(t, err)
+	if !ctx2.Global().SameValue(this.Value) || holder.SameValue(this.Value) {
+		t.Error("expected this to be the global proxy and the holder the global object")
+	}
+}
+
+func TestFunctionTemplateCallbackPanic(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	global := v8.NewObjectTemplate(iso)
+	fatalIf(t, global.Set("explode", v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		var m map[string]int
+		m["boom"]++ // (assignment to entry in nil map)
+		return nil
+	})))
+	ctx := v8.NewContext(iso, global)
+	defer ctx.Close()
+
+	_, err := ctx.RunScript("explode()", "")
+	if err == nil || !strings.Contains(err.Error(), "Go panic: assignment to entry in nil map") {
+		t.Errorf("expected the panic as an error, got %v", err)
+	}
+	val, err := ctx.RunScript("try { explode() } catch (e) { e.goStack }", "")
+	fatalIf(t, err)
+	if !strings.Contains(val.String(), "TestFunctionTemplateCallbackPanic") {
+		t.Errorf("expected a Go stack trace, got %q", val)
+	}
+
+	// The isolate is still usable.
+	val, err = ctx.RunScript("1 + 1", "")
+	fatalIf(t, err)
+	if val.Int32() != 2 {
+		t.Errorf("unexpected result %v", val)
+	}
+}
+
 func ExampleFunctionTemplate() {
 	iso := v8.NewIsolate()
 	defer iso.Dispose()
@@ -124,3 +432,41 @@ func ExampleFunctionTemplate() {
 	// Output:
 	// [foo bar 0 1]
 }
+
+func TestFunctionCallbackInfoReturn(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	returns := map[string]func(info *v8.FunctionCallbackInfo){
+		"int32":     func(info *v8.FunctionCallbackInfo) { info.ReturnInt32(-7) },
+		"uint32":    func(info *v8.FunctionCallbackInfo) { info.ReturnUint32(4000000000) },
+		"float64":   func(info *v8.FunctionCallbackInfo) { info.ReturnFloat64(1.5) },
+		"bool":      func(info *v8.FunctionCallbackInfo) { info.ReturnBool(true) },
+		"null":      func(info *v8.FunctionCallbackInfo) { info.ReturnNull() },
+		"undefined": func(info *v8.FunctionCallbackInfo) { info.ReturnInt32(1); info.ReturnUndefined() },
+	}
+	for name, ret := range returns {
+		ret := ret
+		fn := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+			ret(info)
+			return nil
+		}).GetFunction(ctx)
+		fatalIf(t, ctx.Global().Set("return_"+name, fn))
+	}
+	fatalIf(t, ctx.Global().Set("overridden", v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		info.ReturnInt32(1)
+		val, _ := v8.NewValue(iso, "value")
+		return val
+	}).GetFunction(ctx)))
+
+	val, err := ctx.RunScript(`JSON.stringify([return_int32(), return_uint32(), return_float64(),
+		return_bool(), return_null(), typeof return_undefined(), overridden()])`, "")
+	fatalIf(t, err)
+	if got := val.String(); got != `[-7,4000000000,1.5,true,null,"undefined","value"]` {
+		t.Errorf("unexpected results %s", got)
+	}
+}
@@ -5,6 +5,7 @@
 package v8go_test
 
 import (
+	"strings"
 	"testing"
 
 	v8 "github.com/couchbasedeps/v8go"
@@ -119,6 +120,53 @@ func TestFunctionCallError(t *testing.T) {
 	}
 }
 
+func TestFunctionCallBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	iso := ctx.Isolate()
+	defer iso.Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`(function(a, b) {
+		if (a < 0) throw new RangeError("negative");
+		return this.prefix + (b === undefined ? a : a + b);
+	})`, "batch.js")
+	fatalIf(t, err)
+	fn, _ := val.AsFunction()
+	recv, _ := ctx.RunScript(`({prefix: "="})`, "")
+
+	num := func(n int32) v8.Valuer {
+		v, _ := v8.NewValue(iso, n)
+		return v
+	}
+	results := fn.CallBatch(recv, [][]v8.Valuer{
+		{num(1), num(2)},
+		{num(-1)},
+		{num(5)},
+		{},
+	})
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if results[0].Err != nil || results[0].Value.String() != "=3" {
+		t.Errorf("unexpected first result %+v", results[0])
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "RangeError: negative") {
+		t.Errorf("expected the second call to throw, got %+v", results[1])
+	}
+	if results[2].Err != nil || results[2].Value.String() != "=5" {
+		t.Errorf("expected calls after a failed one to run, got %+v", results[2])
+	}
+	if results[3].Err != nil || results[3].Value.String() != "=undefined" {
+		t.Errorf("unexpected result with no arguments %+v", results[3])
+	}
+
+	if results := fn.CallBatch(recv, nil); results != nil {
+		t.Errorf("expected no results for no calls, got %v", results)
+	}
+}
+
 func TestFunctionSourceMapUrl(t *testing.T) {
 	t.Parallel()
 
@@ -179,6 +227,89 @@ func TestFunctionNewInstance(t *testing.T) {
 	}
 }
 
+func TestFunctionNewInstanceClass(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		class Counter {
+			#count;
+			constructor(start) { this.#count = start }
+			increment() { return ++this.#count }
+		}
+		Counter`, "")
+	fatalIf(t, err)
+	class, _ := val.AsFunction()
+	if !class.IsConstructor() {
+		t.Error("expected a class to be a constructor")
+	}
+	if _, err := class.Call(v8.Undefined(ctx.Isolate())); err == nil {
+		t.Error("expected an error calling a class without new")
+	}
+	start, _ := v8.NewValue(ctx.Isolate(), int32(41))
+	counter, err := class.NewInstance(start)
+	fatalIf(t, err)
+	n, err := counter.MethodCall("increment")
+	fatalIf(t, err)
+	if n.Int32() != 42 {
+		t.Errorf("expected 42, got %v", n)
+	}
+
+	val, err = ctx.RunScript(`() => {}`, "")
+	fatalIf(t, err)
+	arrow, _ := val.AsFunction()
+	if arrow.IsConstructor() {
+		t.Error("expected an arrow function not to be a constructor")
+	}
+	if _, err := arrow.NewInstance(); err == nil {
+		t.Error("expected an error constructing an arrow function")
+	}
+}
+
+func TestFunctionMetadata(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	_, err := ctx.RunScript("function named() {}\nconst obj = {};\nobj.handler = function() {};", "handlers.js")
+	fatalIf(t, err)
+	val, err := ctx.RunScript("[named, obj.handler]", "other.js")
+	fatalIf(t, err)
+	named, _ := val.Object().GetIdx(0)
+	handler, _ := val.Object().GetIdx(1)
+	namedFn, _ := named.AsFunction()
+	handlerFn, _ := handler.AsFunction()
+
+	if name := namedFn.Name(); name != "named" {
+		t.Errorf("unexpected name %q", name)
+	}
+	if name := handlerFn.Name(); name != "obj.handler" {
+		t.Errorf("unexpected inferred name %q", name)
+	}
+	if namedFn.ScriptID() == 0 || namedFn.ScriptID() != handlerFn.ScriptID() {
+		t.Errorf("expected both functions to have the same script ID, got %d and %d",
+			namedFn.ScriptID(), handlerFn.ScriptID())
+	}
+	if file, line, col := handlerFn.Location(); file != "handlers.js" || line != 3 || col != 23 {
+		t.Errorf("unexpected location %s:%d:%d", file, line, col)
+	}
+
+	builtin, _ := ctx.Global().Get("parseInt")
+	builtinFn, _ := builtin.AsFunction()
+	if builtinFn.Name() != "parseInt" || builtinFn.ScriptID() != 0 {
+		t.Errorf("unexpected built-in function metadata %q %d", builtinFn.Name(), builtinFn.ScriptID())
+	}
+	if file, line, col := builtinFn.Location(); file != "" || line != 0 || col != 0 {
+		t.Errorf("unexpected location %q:%d:%d", file, line, col)
+	}
+}
+
 func TestFunctionNewInstanceError(t *testing.T) {
 	t.Parallel()
 
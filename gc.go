@@ -0,0 +1,108 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"strings"
+	"time"
+)
+
+// GCType is the kind of a garbage collection.
+type GCType int
+
+const (
+	// GCTypeScavenge is a minor collection of the young generation.
+	GCTypeScavenge GCType = 1 << iota
+	// GCTypeMarkSweepCompact is a full collection.
+	GCTypeMarkSweepCompact
+	// GCTypeIncrementalMarking is a step of the incremental marking of a full collection.
+	GCTypeIncrementalMarking
+	// GCTypeProcessWeakCallbacks is the processing of weak handles' callbacks.
+	GCTypeProcessWeakCallbacks
+)
+
+func (t GCType) String() string {
+	switch t {
+	case GCTypeScavenge:
+		return "scavenge"
+	case GCTypeMarkSweepCompact:
+		return "mark-sweep-compact"
+	case GCTypeIncrementalMarking:
+		return "incremental-marking"
+	case GCTypeProcessWeakCallbacks:
+		return "process-weak-callbacks"
+	}
+	return "unknown"
+}
+
+// GCCallbackFlags give more information about a garbage collection.
+type GCCallbackFlags int
+
+const (
+	GCCallbackFlagConstructRetainedObjectInfos GCCallbackFlags = 1 << (iota + 1)
+	// GCCallbackFlagForced: the collection was requested, e.g. by `gc()`.
+	GCCallbackFlagForced
+	GCCallbackFlagSynchronousPhantomCallbackProcessing
+	// GCCallbackFlagCollectAllAvailableGarbage: the collection is repeated until no more
+	// memory can be freed, e.g. under memory pressure.
+	GCCallbackFlagCollectAllAvailableGarbage
+	GCCallbackFlagCollectAllExternalMemory
+	GCCallbackScheduleIdleGarbageCollection
+)
+
+func (f GCCallbackFlags) String() string {
+	names := []string{"construct-retained-object-infos", "forced", "synchronous-phantom-callback-processing",
+		"collect-all-available-garbage", "collect-all-external-memory", "schedule-idle-garbage-collection"}
+	var set []string
+	for i, name := range names {
+		if f&(2<<i) != 0 {
+			set = append(set, name)
+		}
+	}
+	return strings.Join(set, "|")
+}
+
+// GCInfo describes a garbage collection reported to a GCCallback.
+type GCInfo struct {
+	Type  GCType
+	Flags GCCallbackFlags
+	// Duration is how long the collection paused the Isolate; it's only set after the
+	// collection, for the epilogue callback.
+	Duration time.Duration
+}
+
+// GCCallback is called synchronously before or after a garbage collection, on the thread
+// running the Isolate, so it must not use the Isolate or any of its Values.
+type GCCallback func(info GCInfo)
+
+// SetGCCallbacks sets the callbacks called before (the prologue) and after (the epilogue)
+// each garbage collection in this Isolate, e.g. to export metrics of GC pauses. Either may
+// be nil; passing nil for both removes them.
+func (i *Isolate) SetGCCallbacks(prologue, epilogue GCCallback) {
+	enabled := i.gcPrologue != nil || i.gcEpilogue != nil
+	i.gcPrologue, i.gcEpilogue = prologue, epilogue
+	if enable := prologue != nil || epilogue != nil; enable != enabled {
+		C.IsolateSetGCCallbacks(i.ptr, i.cHandle(), boolToCBool(enable))
+	}
+}
+
+//export goGCCallback
+func goGCCallback(handle C.uintptr_t, epilogue C.Bool, gcType C.int, flags C.int) {
+	iso := isolateFromHandle(handle)
+	info := GCInfo{Type: GCType(gcType), Flags: GCCallbackFlags(flags)}
+	if epilogue == 0 {
+		iso.gcStart = time.Now()
+		if iso.gcPrologue != nil {
+			iso.gcPrologue(info)
+		}
+	} else {
+		info.Duration = time.Since(iso.gcStart)
+		if iso.gcEpilogue != nil {
+			iso.gcEpilogue(info)
+		}
+	}
+}
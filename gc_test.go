@@ -0,0 +1,61 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestIsolateSetGCCallbacks(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	var prologues, epilogues []v8.GCInfo
+	iso.SetGCCallbacks(
+		func(info v8.GCInfo) { prologues = append(prologues, info) },
+		func(info v8.GCInfo) { epilogues = append(epilogues, info) },
+	)
+	iso.CollectGarbage()
+	if len(prologues) == 0 || len(prologues) != len(epilogues) {
+		t.Fatalf("expected matching prologues and epilogues, got %d and %d", len(prologues), len(epilogues))
+	}
+	full := false
+	for _, info := range epilogues {
+		if info.Type == v8.GCTypeMarkSweepCompact {
+			full = true
+			if info.Duration <= 0 {
+				t.Errorf("expected a positive duration, got %v", info.Duration)
+			}
+		}
+	}
+	if !full {
+		t.Errorf("expected a %v collection, got %v", v8.GCTypeMarkSweepCompact, epilogues)
+	}
+
+	// Scavenges happen on their own as a script allocates.
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	epilogues = nil
+	_, err := ctx.RunScript(`for (let i = 0; i < 1e6; i++) { [i, {i}] }`, "")
+	fatalIf(t, err)
+	scavenged := false
+	for _, info := range epilogues {
+		scavenged = scavenged || info.Type == v8.GCTypeScavenge
+	}
+	if !scavenged {
+		t.Errorf("expected a %v collection, got %v", v8.GCTypeScavenge, epilogues)
+	}
+
+	iso.SetGCCallbacks(nil, nil)
+	epilogues = nil
+	iso.CollectGarbage()
+	if len(epilogues) != 0 {
+		t.Errorf("expected no callbacks once removed, got %v", epilogues)
+	}
+}
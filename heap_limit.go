@@ -0,0 +1,36 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// NearHeapLimitCallback is called when an Isolate's heap is about to reach its limit,
+// after garbage collection failed to free enough memory. It's given the current limit and
+// the initial one, and returns the new limit.
+//
+// If it returns a limit that's no higher than the current one, V8 crashes the process
+// with an out-of-memory error. To stop the script instead, call TerminateExecution and
+// raise the limit by enough for the script to unwind (a few MB); the Isolate restores the
+// initial limit once the heap shrinks. The callback must not otherwise use the Isolate, as
+// it's called in the middle of an allocation.
+type NearHeapLimitCallback func(currentLimit, initialLimit uint64) uint64
+
+// SetNearHeapLimitCallback sets the callback called when the Isolate's heap nears its
+// limit. It replaces the default handling of Isolates created by NewIsolateWith, which
+// raises the limit by 2MB, and then terminates the script. Passing nil removes the callback.
+func (i *Isolate) SetNearHeapLimitCallback(cb NearHeapLimitCallback) {
+	enabled := i.heapLimitCallback != nil
+	i.heapLimitCallback = cb
+	if enable := cb != nil; enable != enabled {
+		C.IsolateSetNearHeapLimitCallback(i.ptr, i.cHandle(), boolToCBool(enable))
+	}
+}
+
+//export goNearHeapLimitCallback
+func goNearHeapLimitCallback(handle C.uintptr_t, currentLimit, initialLimit C.size_t) C.size_t {
+	iso := isolateFromHandle(handle)
+	return C.size_t(iso.heapLimitCallback(uint64(currentLimit), uint64(initialLimit)))
+}
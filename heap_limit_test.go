@@ -0,0 +1,43 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestIsolateSetNearHeapLimitCallback(t *testing.T) {
+	t.Parallel()
+
+	const MB = 1024 * 1024
+	iso := v8.NewIsolateWith(4*MB, 32*MB)
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	var calls int
+	iso.SetNearHeapLimitCallback(func(currentLimit, initialLimit uint64) uint64 {
+		calls++
+		if currentLimit < initialLimit {
+			t.Errorf("expected the current limit %d to be at least the initial %d", currentLimit, initialLimit)
+		}
+		if calls == 1 {
+			iso.TerminateExecution()
+		}
+		return currentLimit + 16*MB
+	})
+	_, err := ctx.RunScript(`const a = []; while (true) { a.push(new Array(1000).fill(a.length)) }`, "")
+	if err == nil || !strings.HasPrefix(err.Error(), "ExecutionTerminated") {
+		t.Fatalf("expected the script to be terminated, got %v", err)
+	}
+	if calls == 0 {
+		t.Error("expected the callback to be called")
+	}
+
+	iso.SetNearHeapLimitCallback(nil)
+}
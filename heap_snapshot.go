@@ -0,0 +1,43 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"io"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// heapSnapshotWriter is the Go side of a heap snapshot being serialized.
+type heapSnapshotWriter struct {
+	w   io.Writer
+	err error
+}
+
+// TakeHeapSnapshot takes a snapshot of the isolate's heap and writes it to w in the JSON
+// format of Chrome DevTools, which can load it (as a `.heapsnapshot` file) in its Memory
+// tab to find what's keeping objects alive. It does a full garbage collection first, and
+// blocks the isolate while the snapshot is taken and written.
+// It returns the first error w returns, which stops the writing.
+func (i *Isolate) TakeHeapSnapshot(w io.Writer) error {
+	writer := &heapSnapshotWriter{w: w}
+	handle := cgo.NewHandle(writer)
+	defer handle.Delete()
+	C.IsolateTakeHeapSnapshot(i.ptr, C.uintptr_t(handle))
+	return writer.err
+}
+
+//export goHeapSnapshotWrite
+func goHeapSnapshotWrite(handle C.uintptr_t, data *C.char, size C.int) C.Bool {
+	writer := cgo.Handle(handle).Value().(*heapSnapshotWriter)
+	chunk := (*[1 << 30]byte)(unsafe.Pointer(data))[:size:size]
+	if _, err := writer.w.Write(chunk); err != nil {
+		writer.err = err
+		return 0
+	}
+	return 1
+}
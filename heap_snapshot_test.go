@@ -0,0 +1,72 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestIsolateTakeHeapSnapshot(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	_, err := ctx.RunScript(`class LeakyThing {}; var leaks = [new LeakyThing(), new LeakyThing()]`, "")
+	fatalIf(t, err)
+
+	var buf bytes.Buffer
+	fatalIf(t, iso.TakeHeapSnapshot(&buf))
+	var snapshot struct {
+		Snapshot struct {
+			NodeCount int `json:"node_count"`
+		} `json:"snapshot"`
+		Nodes   []int    `json:"nodes"`
+		Strings []string `json:"strings"`
+	}
+	fatalIf(t, json.Unmarshal(buf.Bytes(), &snapshot))
+	if snapshot.Snapshot.NodeCount == 0 || len(snapshot.Nodes) == 0 {
+		t.Errorf("expected nodes, got %d (%d)", snapshot.Snapshot.NodeCount, len(snapshot.Nodes))
+	}
+	found := false
+	for _, str := range snapshot.Strings {
+		if str == "LeakyThing" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("expected the snapshot to include LeakyThing")
+	}
+}
+
+type failingWriter struct{ writes int }
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errWriteFailed
+}
+
+func TestIsolateTakeHeapSnapshotWriteError(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	var w failingWriter
+	if err := iso.TakeHeapSnapshot(&w); err != errWriteFailed {
+		t.Errorf("expected the writer's error, got %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("expected writing to stop after the error, got %d writes", w.writes)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// ImportMetaCallback is called the first time a module accesses `import.meta`, to
+// populate it, e.g. by setting meta's "url" property to the URL the module was loaded from.
+type ImportMetaCallback func(ctx *Context, module *Module, meta *Object)
+
+// SetImportMetaCallback sets the callback that initializes the `import.meta` object of
+// the modules in this Context. Passing nil removes the callback, so `import.meta`
+// is left empty.
+func (c *Context) SetImportMetaCallback(cb ImportMetaCallback) {
+	c.importMeta = cb
+}
+
+//export goInitializeImportMeta
+func goInitializeImportMeta(ctxHandle C.uintptr_t, module C.ModulePtr, meta C.ValueRef) {
+	ctx := contextFromHandle(ctxHandle)
+	if ctx.importMeta == nil {
+		return
+	}
+	if m := ctx.modules[module]; m != nil {
+		ctx.importMeta(ctx, m, &Object{&Value{meta, ctx}})
+	}
+}
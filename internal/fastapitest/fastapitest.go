@@ -0,0 +1,32 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package fastapitest provides C functions for testing v8go.NewFastFunctionTemplate,
+// since tests can't use cgo directly.
+package fastapitest
+
+/*
+#include <stdint.h>
+
+static int32_t fastCalls;
+
+// (Not static, so that Go code can take its address.)
+int32_t fastapitestAddInt32(void* receiver, int32_t a, int32_t b) {
+	fastCalls++;
+	return a + b;
+}
+
+static int32_t getFastCalls() { return fastCalls; }
+*/
+import "C"
+import "unsafe"
+
+// AddInt32 is a C function `int32_t (void* receiver, int32_t a, int32_t b)` that returns
+// a + b and counts the calls made to it.
+var AddInt32 = unsafe.Pointer(C.fastapitestAddInt32)
+
+// Calls returns the number of calls made to AddInt32.
+func Calls() int {
+	return int(C.getFastCalls())
+}
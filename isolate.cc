@@ -9,6 +9,27 @@
 
 static constexpr size_t MB = 1024 * 1024;
 static constexpr size_t kGrowHeapBy  =  1 * MB; // Amount to grow by on every callback
+static constexpr int kMinSnapshotSize = 1024;     // Smaller than any valid startup snapshot
+
+// The callback of the Go functions defined by CreateSnapshot. They can't be called while
+// the snapshot is being created, as there's no V8GoContext (or Locker) yet.
+static void snapshotFunctionCallback(const FunctionCallbackInfo<Value>& info) {
+  Isolate* iso = info.GetIsolate();
+  if (V8GoContext::fromContext(iso->GetCurrentContext()) == nullptr) {
+    iso->ThrowException(Exception::Error(String::NewFromUtf8Literal(
+        iso, "Go functions can't be called while creating a snapshot")));
+    return;
+  }
+  FunctionTemplateCallback(info);
+}
+
+// The C++ functions that snapshots may refer to, e.g. as the callbacks of functions created
+// from FunctionTemplates; V8 needs them to serialize and deserialize such references.
+static intptr_t externalReferences[] = {
+  reinterpret_cast<intptr_t>(FunctionTemplateCallback),
+  reinterpret_cast<intptr_t>(snapshotFunctionCallback),
+  0
+};
 
 static auto default_platform = platform::NewDefaultPlatform();
 static auto default_allocator = ArrayBuffer::Allocator::NewDefaultAllocator();
@@ -17,6 +38,8 @@ void Init() {
 #ifdef _WIN32
   V8::InitializeExternalStartupData(".");
 #endif
+  // Import assertions are needed for JSON modules, `import x from "./x.json" assert {type: "json"}`
+  V8::SetFlagsFromString("--harmony-import-assertions");
   V8::InitializePlatform(default_platform.get());
   V8::Initialize();
   return;
@@ -46,16 +69,45 @@ static size_t nearHeapLimitCallback(void* data, size_t cur, size_t initialLimit)
 }
 
 
-NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit) {
+NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit,
+                            const char* snapshot, int snapshotLen) {
   Isolate::CreateParams params;
   if (initialHeap > 0 && heapLimit > 0) {
     params.constraints.ConfigureDefaultsFromHeapSize(initialHeap, heapLimit - 2 * kGrowHeapBy);
   }
   params.array_buffer_allocator = default_allocator;
+
+  // V8 reads the snapshot whenever a Context is created, so the isolate keeps a copy of it,
+  // which IsolateDispose frees.
+  StartupData* blob = nullptr;
+  if (snapshot != nullptr) {
+    // (IsValid crashes if the data is too short to hold the snapshot's header.)
+    if (snapshotLen < kMinSnapshotSize || !StartupData{snapshot, snapshotLen}.IsValid()) {
+      return NewIsolateResult{};
+    }
+    char* data = static_cast<char*>(malloc(snapshotLen));
+    memcpy(data, snapshot, snapshotLen);
+    blob = new StartupData{data, snapshotLen};
+    params.snapshot_blob = blob;
+    params.external_references = externalReferences;
+  }
+
   Isolate* iso = Isolate::New(params);
   WithIsolate _with(iso);
+  iso->SetData(1, blob);
+
+  // CreateSnapshot stores the number of Go functions it defined as the snapshot's data.
+  int snapshotFunctions = 0;
+  if (blob != nullptr) {
+    Local<Integer> count;
+    if (iso->GetDataFromSnapshotOnce<Integer>(0).ToLocal(&count)) {
+      snapshotFunctions = int(count->Value());
+    }
+  }
 
   iso->SetCaptureStackTraceForUncaughtExceptions(true);
+  iso->SetHostInitializeImportMetaObjectCallback(InitializeImportMetaObject);
+  iso->SetHostImportModuleDynamicallyCallback(ImportModuleDynamically);
   if (initialHeap > 0 && heapLimit > 0) {
     iso->AddNearHeapLimitCallback(nearHeapLimitCallback, iso);
     iso->AutomaticallyRestoreInitialHeapLimit();
@@ -72,6 +124,7 @@ NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit) {
   result.nullVal = ctx->addValue(Null(iso));
   result.falseVal = ctx->addValue(Boolean::New(iso, false));
   result.trueVal = ctx->addValue(Boolean::New(iso, true));
+  result.snapshotFunctions = snapshotFunctions;
   return result;
 }
 
@@ -92,19 +145,108 @@ void IsolatePerformMicrotaskCheckpoint(IsolatePtr iso) {
   iso->PerformMicrotaskCheckpoint();
 }
 
+void IsolateClearKeptObjects(IsolatePtr iso) {
+  WithIsolate _withiso(iso);
+  iso->ClearKeptObjects();
+}
+
+void IsolateCollectGarbage(IsolatePtr iso) {
+  WithIsolate _withiso(iso);
+  // Performs full collections until no more memory can be freed.
+  iso->LowMemoryNotification();
+}
+
+void IsolateMemoryPressureNotification(IsolatePtr iso, int level) {
+  // (Thread-safe; from other threads, V8 posts a task to handle it.)
+  iso->MemoryPressureNotification(static_cast<MemoryPressureLevel>(level));
+}
+
+Bool IsolatePumpMessageLoop(IsolatePtr iso) {
+  WithIsolate _withiso(iso);
+  bool ran = false;
+  while (platform::PumpMessageLoop(default_platform.get(), iso)) {
+    ran = true;
+  }
+  return ran;
+}
+
 void IsolateDispose(IsolatePtr iso) {
   if (iso == nullptr) {
     return;
   }
   ContextFree(isolateInternalContext(iso));
 
+  auto blob = static_cast<StartupData*>(iso->GetData(1));
   iso->Dispose();
+  if (blob != nullptr) {
+    free(const_cast<char*>(blob->data));
+    delete blob;
+  }
+}
+
+RtnString CreateSnapshot(int count, ScriptSourceData* scripts, Bool keepCode,
+                         int functionCount, const char* functionNames, const int* nameLens) {
+  RtnString rtn = {};
+  SnapshotCreator creator(externalReferences);
+  Isolate* iso = creator.GetIsolate();
+  {
+    HandleScope handle_scope(iso);
+    Local<Context> ctx = Context::New(iso);
+    Context::Scope context_scope(ctx);
+    TryCatch try_catch(iso);
+    // (There's no V8GoContext, which snapshotFunctionCallback checks for.)
+    ctx->SetAlignedPointerInEmbedderData(1, nullptr);
+
+    // The functions' callback refs are -1, -2, ..., which are reserved for the callbacks
+    // given to the Isolates created from the snapshot.
+    creator.AddData(Integer::New(iso, functionCount));
+    for (int i = 0; i < functionCount; i++) {
+      Local<String> name = String::NewFromUtf8(iso, functionNames, NewStringType::kInternalized,
+                                               nameLens[i]).ToLocalChecked();
+      functionNames += nameLens[i];
+      Local<Function> fn = FunctionTemplate::New(iso, snapshotFunctionCallback,
+                                                 Integer::New(iso, -(i + 1)))
+                               ->GetFunction(ctx).ToLocalChecked();
+      fn->SetName(name);
+      ctx->Global()->Set(ctx, name, fn).Check();
+    }
+    for (int i = 0; i < count && rtn.error.msg == nullptr; i++) {
+      Local<String> src;
+      Local<Script> script;
+      if (!String::NewFromUtf8(iso, scripts[i].source, NewStringType::kNormal,
+                               scripts[i].sourceLen).ToLocal(&src)) {
+        rtn.error = ExceptionError(try_catch, iso, ctx);
+        break;
+      }
+      ScriptOrigin origin = NewScriptOrigin(iso, scripts[i].origin);
+      if (!Script::Compile(ctx, src, &origin).ToLocal(&script) || script->Run(ctx).IsEmpty()) {
+        rtn.error = ExceptionError(try_catch, iso, ctx);
+      }
+    }
+    // (A default context must be set even if there was an error, to create the blob.)
+    creator.SetDefaultContext(ctx);
+  }
+  StartupData blob = creator.CreateBlob(keepCode ? SnapshotCreator::FunctionCodeHandling::kKeep
+                                                 : SnapshotCreator::FunctionCodeHandling::kClear);
+  if (rtn.error.msg == nullptr) {
+    // (Copied so that Go can free it like other RtnStrings.)
+    char* data = static_cast<char*>(malloc(blob.raw_size));
+    memcpy(data, blob.data, blob.raw_size);
+    rtn.data = data;
+    rtn.length = blob.raw_size;
+  }
+  delete[] blob.data;
+  return rtn;
 }
 
 void IsolateTerminateExecution(IsolatePtr iso) {
   iso->TerminateExecution();
 }
 
+void IsolateCancelTerminateExecution(IsolatePtr iso) {
+  iso->CancelTerminateExecution();
+}
+
 int IsolateIsExecutionTerminating(IsolatePtr iso) {
   return iso->IsExecutionTerminating();
 }
@@ -129,12 +271,235 @@ IsolateHStatistics IsolationGetHeapStatistics(IsolatePtr iso) {
                             hs.number_of_detached_contexts()};
 }
 
+HeapSpaceStatisticsData* IsolateGetHeapSpaceStatistics(IsolatePtr iso, int* count) {
+  if (iso == nullptr) {
+    *count = 0;
+    return nullptr;
+  }
+  *count = int(iso->NumberOfHeapSpaces());
+  if (*count == 0) {
+    return nullptr;
+  }
+  auto spaces = static_cast<HeapSpaceStatisticsData*>(calloc(*count, sizeof(HeapSpaceStatisticsData)));
+  for (int i = 0; i < *count; i++) {
+    v8::HeapSpaceStatistics hs;
+    iso->GetHeapSpaceStatistics(&hs, i);
+    spaces[i] = HeapSpaceStatisticsData{hs.space_name(),
+                                        hs.space_size(),
+                                        hs.space_used_size(),
+                                        hs.space_available_size(),
+                                        hs.physical_space_size()};
+  }
+  return spaces;
+}
+
+/**
+ * Streams a serialized heap snapshot to the Go writer with the given handle.
+ */
+class HeapSnapshotWriter : public OutputStream {
+ public:
+  explicit HeapSnapshotWriter(uintptr_t handle) : _handle(handle) {}
+  void EndOfStream() override {}
+  int GetChunkSize() override { return 64 * 1024; }
+  WriteResult WriteAsciiChunk(char* data, int size) override {
+    return goHeapSnapshotWrite(_handle, data, size) ? kContinue : kAbort;
+  }
+
+ private:
+  uintptr_t _handle;
+};
+
+void IsolateTakeHeapSnapshot(IsolatePtr iso, uintptr_t writerHandle) {
+  WithIsolate _withiso(iso);
+  const HeapSnapshot* snapshot = iso->GetHeapProfiler()->TakeHeapSnapshot();
+  HeapSnapshotWriter writer(writerHandle);
+  snapshot->Serialize(&writer, HeapSnapshot::kJSON);
+  const_cast<HeapSnapshot*>(snapshot)->Delete();
+}
+
+/**
+ * Called by V8 when a Context that disallows code generation from strings calls
+ * `eval` or `new Function`; forwards the decision to the Go callback.
+ */
+static ModifyCodeGenerationFromStringsResult codeGenerationCallback(Local<Context> context,
+                                                                     Local<Value> source,
+                                                                     bool isCodeLike) {
+  ModifyCodeGenerationFromStringsResult result;
+  V8GoContext* ctx = V8GoContext::fromContext(context);
+  if (ctx->goRef == 0) {
+    return result;  // (the internal context never runs scripts)
+  }
+  CodeGenerationResult rtn = goCodeGenerationCallback(ctx->goRef, ctx->addValue(source),
+                                                      isCodeLike);
+  result.codegen_allowed = rtn.allowed;
+  if (rtn.allowed && rtn.modifiedSource.ctx != nullptr) {
+    Local<Value> modified = Deref(rtn.modifiedSource);
+    if (modified->IsString()) {
+      result.modified_source = modified.As<String>();
+    }
+  }
+  return result;
+}
+
+void IsolateSetCodeGenerationFromStringsCallback(IsolatePtr iso, Bool enable) {
+  WithIsolate _withiso(iso);
+  iso->SetModifyCodeGenerationFromStringsCallback(enable ? codeGenerationCallback : nullptr);
+}
+
+/**
+ * Called by V8 when a promise is rejected with no handler, gets a handler after being
+ * rejected, or is resolved or rejected again; forwards the event to the Go callback.
+ */
+static void promiseRejectCallback(PromiseRejectMessage message) {
+  Local<Promise> promise = message.GetPromise();
+  Local<Context> creation;
+  if (!promise->GetCreationContext().ToLocal(&creation)) {
+    return;
+  }
+  V8GoContext* ctx = V8GoContext::fromContext(creation);
+  if (ctx == nullptr || ctx->goRef == 0) {
+    return;
+  }
+  Local<Value> value = message.GetValue();
+  ValueRef reason = value.IsEmpty() ? ValueRef{} : ctx->addValue(value);
+  goPromiseRejectCallback(ctx->goRef, message.GetEvent(), ctx->addValue(promise), reason);
+}
+
+void IsolateSetPromiseRejectCallback(IsolatePtr iso, Bool enable) {
+  WithIsolate _withiso(iso);
+  iso->SetPromiseRejectCallback(enable ? promiseRejectCallback : nullptr);
+}
+
+/**
+ * Called by V8 on each step in the life of a promise; forwards it to the Go hooks of the
+ * promise's creation context.
+ */
+static void promiseHook(PromiseHookType type, Local<Promise> promise, Local<Value> parent) {
+  Local<Context> creation;
+  if (!promise->GetCreationContext().ToLocal(&creation)) {
+    return;
+  }
+  V8GoContext* ctx = V8GoContext::fromContext(creation);
+  if (ctx == nullptr || ctx->goRef == 0) {
+    return;
+  }
+  bool hasParent = parent->IsPromise();
+  goPromiseHook(ctx->goRef, PromiseHookKind(type), ctx->addValue(promise),
+                hasParent ? ctx->addValue(parent) : ValueRef{}, hasParent);
+}
+
+void IsolateSetPromiseHook(IsolatePtr iso, Bool enable) {
+  WithIsolate _withiso(iso);
+  iso->SetPromiseHook(enable ? promiseHook : nullptr);
+}
+
+/**
+ * Called by V8 before and after each garbage collection; forwards it to the Go Isolate
+ * whose handle is the data.
+ */
+static void gcPrologueCallback(Isolate* iso, GCType type, GCCallbackFlags flags, void* data) {
+  goGCCallback(reinterpret_cast<uintptr_t>(data), false, type, flags);
+}
+
+static void gcEpilogueCallback(Isolate* iso, GCType type, GCCallbackFlags flags, void* data) {
+  goGCCallback(reinterpret_cast<uintptr_t>(data), true, type, flags);
+}
+
+void IsolateSetGCCallbacks(IsolatePtr iso, uintptr_t handle, Bool enable) {
+  void* data = reinterpret_cast<void*>(handle);
+  if (enable) {
+    iso->AddGCPrologueCallback(gcPrologueCallback, data);
+    iso->AddGCEpilogueCallback(gcEpilogueCallback, data);
+  } else {
+    iso->RemoveGCPrologueCallback(gcPrologueCallback, data);
+    iso->RemoveGCEpilogueCallback(gcEpilogueCallback, data);
+  }
+}
+
+/**
+ * Called by V8 when the heap nears its limit, if a Go callback is set; it replaces
+ * nearHeapLimitCallback, as V8 only calls the last callback added.
+ */
+static size_t goNearHeapLimit(void* data, size_t cur, size_t initialLimit) {
+  return goNearHeapLimitCallback(reinterpret_cast<uintptr_t>(data), cur, initialLimit);
+}
+
+void IsolateSetNearHeapLimitCallback(IsolatePtr iso, uintptr_t handle, Bool enable) {
+  WithIsolate _withiso(iso);
+  if (enable) {
+    iso->AddNearHeapLimitCallback(goNearHeapLimit, reinterpret_cast<void*>(handle));
+  } else {
+    iso->RemoveNearHeapLimitCallback(goNearHeapLimit, 0);
+  }
+}
+
+StackFrameData* IsolateCurrentStackTrace(IsolatePtr iso, int limit, int* count) {
+  WithIsolate _withiso(iso);
+  Local<StackTrace> trace = StackTrace::CurrentStackTrace(iso, limit, StackTrace::kDetailed);
+  *count = trace->GetFrameCount();
+  if (*count == 0) {
+    return nullptr;
+  }
+  auto frames = static_cast<StackFrameData*>(calloc(*count, sizeof(StackFrameData)));
+  for (int i = 0; i < *count; i++) {
+    Local<StackFrame> frame = trace->GetFrame(iso, i);
+    Local<String> name = frame->GetFunctionName();
+    if (!name.IsEmpty()) {
+      frames[i].functionName = CopyString(iso, name);
+    }
+    Local<String> script = frame->GetScriptNameOrSourceURL();
+    if (!script.IsEmpty()) {
+      frames[i].resourceName = CopyString(iso, script);
+    }
+    frames[i].line = frame->GetLineNumber();
+    frames[i].column = frame->GetColumn();
+    frames[i].scriptID = frame->GetScriptId();
+    frames[i].isEval = frame->IsEval();
+    frames[i].isConstructor = frame->IsConstructor();
+  }
+  return frames;
+}
+
 ValueRef IsolateThrowException(IsolatePtr iso, ValuePtr value) {
   WithIsolate _withiso(iso);
   Local<Value> throw_ret_val = iso->ThrowException(Deref(value));
   return value.ctx->addValue(throw_ret_val);
 }
 
+static Local<Value> newError(ErrorKind kind, Local<String> message) {
+  switch (kind) {
+    case TypeError_err:      return Exception::TypeError(message);
+    case RangeError_err:     return Exception::RangeError(message);
+    case SyntaxError_err:    return Exception::SyntaxError(message);
+    case ReferenceError_err: return Exception::ReferenceError(message);
+    default:                 return Exception::Error(message);
+  }
+}
+
+ValueRef ContextNewError(ContextPtr ctx, ErrorKind kind, const char* msg, int msgLen) {
+  WithContext _with(ctx);
+  return ctx->addValue(newError(kind, _with.makeString(msg, NewStringType::kNormal, msgLen)));
+}
+
+// Creates an error in the Context currently executing, if any, else the internal Context,
+// whose Go handle is stored in `*ctx_ref`.
+ValueRef IsolateNewError(IsolatePtr iso, ErrorKind kind, const char* msg, int msgLen,
+                         uintptr_t* ctx_ref) {
+  WithIsolate _withiso(iso);
+  V8GoContext* ctx = nullptr;
+  if (iso->InContext()) {
+    ctx = V8GoContext::fromContext(iso->GetCurrentContext());
+  }
+  if (ctx == nullptr) {
+    ctx = isolateInternalContext(iso);
+  }
+  Context::Scope context_scope(ctx->context());
+  Local<String> message =
+      String::NewFromUtf8(iso, msg, NewStringType::kNormal, msgLen).ToLocalChecked();
+  *ctx_ref = ctx->goRef;
+  return ctx->addValue(newError(kind, message));
+}
+
 
 /********** UnboundScript **********/
 
@@ -144,7 +509,7 @@ const int ScriptCompilerEagerCompile = ScriptCompiler::kEagerCompile;
 
 RtnUnboundScript IsolateCompileUnboundScript(IsolatePtr iso,
                                              const char* s, int sLen,
-                                             const char* o, int oLen,
+                                             ScriptOriginData o,
                                              CompileOptions opts) {
   V8GoContext *ctx = isolateInternalContext(iso);
   WithContext _with(ctx);
@@ -153,8 +518,6 @@ RtnUnboundScript IsolateCompileUnboundScript(IsolatePtr iso,
 
   Local<String> src =
       String::NewFromUtf8(iso, s, NewStringType::kNormal, sLen).ToLocalChecked();
-  Local<String> ogn =
-      String::NewFromUtf8(iso, o, NewStringType::kNormal, oLen).ToLocalChecked();
 
   ScriptCompiler::CompileOptions option =
       static_cast<ScriptCompiler::CompileOptions>(opts.compileOption);
@@ -166,7 +529,7 @@ RtnUnboundScript IsolateCompileUnboundScript(IsolatePtr iso,
                                                  opts.cachedData.length);
   }
 
-  ScriptOrigin script_origin(ogn);
+  ScriptOrigin script_origin = NewScriptOrigin(iso, o);
 
   ScriptCompiler::Source source(src, script_origin, cached_data);
 
@@ -185,6 +548,42 @@ RtnUnboundScript IsolateCompileUnboundScript(IsolatePtr iso,
   return rtn;
 }
 
+RtnSyntaxError IsolateCheckSyntax(IsolatePtr iso,
+                                  const char* s, int sLen,
+                                  ScriptOriginData o) {
+  V8GoContext *ctx = isolateInternalContext(iso);
+  WithContext _with(ctx);
+
+  RtnSyntaxError rtn = {};
+
+  Local<String> src =
+      String::NewFromUtf8(iso, s, NewStringType::kNormal, sLen).ToLocalChecked();
+  ScriptCompiler::Source source(src, NewScriptOrigin(iso, o));
+  if (!ScriptCompiler::CompileUnboundScript(iso, &source).IsEmpty()) {
+    return rtn;
+  }
+
+  TryCatch& try_catch = _with.try_catch;
+  if (try_catch.HasTerminated()) {
+    rtn.msg = strdup("ExecutionTerminated: script execution has been terminated");
+    return rtn;
+  }
+  rtn.msg = CopyString(iso, try_catch.Exception()).data;
+
+  Local<Message> msg = try_catch.Message();
+  if (!msg.IsEmpty()) {
+    Local<Context> local_ctx = _with.local_ctx;
+    rtn.line = msg->GetLineNumber(local_ctx).FromMaybe(0);
+    rtn.startColumn = msg->GetStartColumn(local_ctx).FromMaybe(-1) + 1;
+    rtn.endColumn = msg->GetEndColumn(local_ctx).FromMaybe(-1) + 1;
+    Local<String> line;
+    if (msg->GetSourceLine(local_ctx).ToLocal(&line)) {
+      rtn.sourceLine = CopyString(iso, line).data;
+    }
+  }
+  return rtn;
+}
+
 ScriptCompilerCachedData* UnboundScriptCreateCodeCache(
     IsolatePtr iso,
     UnboundScriptPtr us_ptr) {
@@ -227,6 +626,260 @@ RtnValue UnboundScriptRun(ContextPtr ctx, UnboundScriptPtr us_ptr) {
   return rtn;
 }
 
+/********** ScriptStreamer **********/
+
+namespace v8go {
+
+  // Feeds chunks pushed from Go to V8's streaming parser, which pulls them
+  // on a background thread.
+  class GoSourceStream : public ScriptCompiler::ExternalSourceStream {
+  public:
+    // Called by V8 on the background thread; blocks until a chunk is pushed
+    // or the stream is closed. V8 takes ownership of the returned data.
+    size_t GetMoreData(const uint8_t** src) override {
+      std::unique_lock<std::mutex> lock(_mutex);
+      _cond.wait(lock, [this] { return !_chunks.empty() || _closed; });
+      if (_chunks.empty()) {
+        return 0;
+      }
+      auto chunk = _chunks.front();
+      _chunks.pop_front();
+      *src = chunk.first;
+      return chunk.second;
+    }
+
+    void push(const char* data, size_t length) {
+      uint8_t* chunk = new uint8_t[length];
+      memcpy(chunk, data, length);
+      std::lock_guard<std::mutex> lock(_mutex);
+      _chunks.emplace_back(chunk, length);
+      _cond.notify_one();
+    }
+
+    void close() {
+      std::lock_guard<std::mutex> lock(_mutex);
+      _closed = true;
+      _cond.notify_one();
+    }
+
+    ~GoSourceStream() {
+      for (auto& chunk : _chunks) {
+        delete[] chunk.first;
+      }
+    }
+
+  private:
+    std::mutex _mutex;
+    std::condition_variable _cond;
+    std::deque<std::pair<uint8_t*, size_t>> _chunks;
+    bool _closed = false;
+  };
+
+
+  struct V8GoScriptStreamer {
+    V8GoScriptStreamer(Isolate* iso)
+    :iso(iso)
+    ,stream(new GoSourceStream)
+    ,source(std::unique_ptr<ScriptCompiler::ExternalSourceStream>(stream),
+            ScriptCompiler::StreamedSource::UTF8)
+    { }
+
+    ~V8GoScriptStreamer() {
+      finish();
+    }
+
+    // Appends UTF-8 data to the script. A multi-byte character split at the
+    // end of the data is held back until the rest of it arrives, since V8
+    // can't reassemble a character split across more than two chunks.
+    void push(const char* data, size_t length) {
+      fullSource.append(data, length);
+      pending.append(data, length);
+      size_t tail = incompleteUtf8Tail(pending);
+      if (pending.size() > tail) {
+        stream->push(pending.data(), pending.size() - tail);
+        pending.erase(0, pending.size() - tail);
+      }
+    }
+
+    // Ends the stream and waits for the background parse to complete.
+    void finish() {
+      if (finished) {
+        return;
+      }
+      finished = true;
+      if (!pending.empty()) {
+        stream->push(pending.data(), pending.size());
+        pending.clear();
+      }
+      stream->close();
+      if (thread.joinable()) {
+        thread.join();
+      }
+    }
+
+    // Compiles the streamed script, bound to the current context.
+    MaybeLocal<Script> compile(Local<Context> ctx, Local<String> origin) {
+      finish();
+      Local<String> src;
+      if (!String::NewFromUtf8(iso, fullSource.data(), NewStringType::kNormal,
+                               int(fullSource.size())).ToLocal(&src)) {
+        return MaybeLocal<Script>();
+      }
+      ScriptOrigin script_origin(origin);
+      if (!task) {
+        // V8 declined to stream this script, so compile it the normal way:
+        return Script::Compile(ctx, src, &script_origin);
+      }
+      return ScriptCompiler::Compile(ctx, &source, src, script_origin);
+    }
+
+    Isolate* const iso;
+    GoSourceStream* const stream;   // owned by `source`
+    ScriptCompiler::StreamedSource source;
+    std::unique_ptr<ScriptCompiler::ScriptStreamingTask> task;
+    std::thread thread;
+    std::string fullSource, pending;
+    bool finished = false;
+
+  private:
+    static size_t incompleteUtf8Tail(const std::string& str) {
+      size_t len = str.size();
+      for (size_t i = 1; i <= 3 && i <= len; ++i) {
+        uint8_t c = str[len - i];
+        if ((c & 0xC0) == 0x80) {
+          continue;   // continuation byte; keep looking for the lead byte
+        }
+        size_t need = (c >= 0xF0) ? 4 : (c >= 0xE0) ? 3 : (c >= 0xC0) ? 2 : 1;
+        return (need > i) ? i : 0;
+      }
+      return 0;
+    }
+  };
+
+}
+
+ScriptStreamerPtr NewScriptStreamer(IsolatePtr iso) {
+  WithIsolate _withiso(iso);
+
+  V8GoScriptStreamer* streamer = new V8GoScriptStreamer(iso);
+  streamer->task.reset(ScriptCompiler::StartStreaming(iso, &streamer->source));
+  if (streamer->task) {
+    ScriptCompiler::ScriptStreamingTask* task = streamer->task.get();
+    streamer->thread = std::thread([task] { task->Run(); });
+  }
+  return streamer;
+}
+
+void ScriptStreamerPush(ScriptStreamerPtr streamer, const char* data, int length) {
+  streamer->push(data, length);
+}
+
+RtnValue ScriptStreamerRun(ScriptStreamerPtr streamer,
+                           ContextPtr ctx,
+                           const char* o, int oLen) {
+  WithContext _with(ctx);
+
+  RtnValue rtn = {};
+
+  Local<String> ogn =
+      String::NewFromUtf8(_with.iso(), o, NewStringType::kNormal, oLen).ToLocalChecked();
+
+  Local<Script> script;
+  if (!streamer->compile(_with.local_ctx, ogn).ToLocal(&script)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  return _with.returnValue(script->Run(_with.local_ctx));
+}
+
+RtnUnboundScript ScriptStreamerCompileUnboundScript(ScriptStreamerPtr streamer,
+                                                    const char* o, int oLen) {
+  V8GoContext *ctx = isolateInternalContext(streamer->iso);
+  WithContext _with(ctx);
+
+  RtnUnboundScript rtn = {};
+
+  Local<String> ogn =
+      String::NewFromUtf8(_with.iso(), o, NewStringType::kNormal, oLen).ToLocalChecked();
+
+  Local<Script> script;
+  if (!streamer->compile(_with.local_ctx, ogn).ToLocal(&script)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+
+  rtn.ptr = ctx->newUnboundScript(script->GetUnboundScript());
+  return rtn;
+}
+
+void ScriptStreamerFree(ScriptStreamerPtr streamer) {
+  WithIsolate _withiso(streamer->iso);
+  delete streamer;
+}
+
+/********** InspectorSession **********/
+
+namespace v8go {
+
+  // A private session with V8's inspector, used to drive features such as precise
+  // coverage that are only exposed through the inspector protocol.
+  struct V8GoInspectorSession : public v8_inspector::V8InspectorClient,
+                                public v8_inspector::V8Inspector::Channel {
+    V8GoInspectorSession(Isolate* iso)
+    :iso(iso)
+    ,inspector(v8_inspector::V8Inspector::create(iso, this))
+    ,session(inspector->connect(kContextGroupId, this, v8_inspector::StringView()))
+    { }
+
+    void sendResponse(int callId, std::unique_ptr<v8_inspector::StringBuffer> message) override {
+      response = std::move(message);
+    }
+    void sendNotification(std::unique_ptr<v8_inspector::StringBuffer> message) override { }
+    void flushProtocolNotifications() override { }
+
+    static constexpr int kContextGroupId = 1;
+
+    Isolate* const iso;
+    std::unique_ptr<v8_inspector::V8Inspector> inspector;
+    std::unique_ptr<v8_inspector::V8InspectorSession> session;
+    std::unique_ptr<v8_inspector::StringBuffer> response;
+  };
+
+}
+
+InspectorSessionPtr NewInspectorSession(IsolatePtr iso) {
+  WithIsolate _withiso(iso);
+  return new V8GoInspectorSession(iso);
+}
+
+RtnString InspectorSessionDispatch(InspectorSessionPtr ptr, const char* message, int messageLen) {
+  Isolate* iso = ptr->iso;
+  WithIsolate _withiso(iso);
+
+  ptr->response.reset();
+  ptr->session->dispatchProtocolMessage(
+      v8_inspector::StringView((const uint8_t*)message, messageLen));
+  if (!ptr->response) {
+    return {};
+  }
+  v8_inspector::StringView view = ptr->response->string();
+  Local<String> str;
+  if (view.is8Bit()) {
+    str = String::NewFromOneByte(iso, view.characters8(), NewStringType::kNormal,
+                                 int(view.length())).ToLocalChecked();
+  } else {
+    str = String::NewFromTwoByte(iso, view.characters16(), NewStringType::kNormal,
+                                 int(view.length())).ToLocalChecked();
+  }
+  ptr->response.reset();
+  return CopyString(iso, str);
+}
+
+void InspectorSessionDispose(InspectorSessionPtr ptr) {
+  WithIsolate _withiso(ptr->iso);
+  delete ptr;
+}
+
 /********** CpuProfiler **********/
 
 CPUProfiler* NewCPUProfiler(IsolatePtr iso) {
@@ -247,6 +900,10 @@ void CPUProfilerDispose(CPUProfiler* profiler) {
   delete profiler;
 }
 
+void CPUProfilerSetSamplingInterval(CPUProfiler* profiler, int us) {
+  profiler->ptr->SetSamplingInterval(us);
+}
+
 void CPUProfilerStartProfiling(CPUProfiler* profiler, const char* title) {
   if (profiler->iso == nullptr) {
     return;
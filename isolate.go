@@ -7,16 +7,23 @@ package v8go
 /*
 #include <stdlib.h>
 #include "v8go.h"
+static RtnSyntaxError IsolateCheckSyntaxGo(IsolatePtr iso, _GoString_ src, ScriptOriginData org) {
+	return IsolateCheckSyntax(iso, _GoStringPtr(src), _GoStringLen(src), org); }
 static RtnUnboundScript IsolateCompileUnboundScriptGo(IsolatePtr iso,
-								_GoString_ src, _GoString_ org, CompileOptions options) {
+								_GoString_ src, ScriptOriginData org, CompileOptions options) {
 	return IsolateCompileUnboundScript(iso, _GoStringPtr(src), _GoStringLen(src),
-									_GoStringPtr(org), _GoStringLen(org), options); }
+									org, options); }
 */
 import "C"
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"runtime"
+	"runtime/cgo"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -32,9 +39,32 @@ type Isolate struct {
 	v8Mutex sync.Mutex       // Mutex for Lock() and Unlock() methods
 	v8Lock  C.WithIsolatePtr // Holds native lock state between Lock() and Unlock()
 
-	cbMutex sync.RWMutex             // Mutex for accessing `cbs`
-	cbSeq   int                      // Latest ID assigned to a callback
-	cbs     map[int]FunctionCallback // Array of registered callbacks
+	cbMutex  sync.RWMutex                  // Mutex for accessing `cbs` and `handlers`
+	cbSeq    int                           // Latest ID assigned to a callback or handler
+	cbs      map[int]FunctionCallback      // Array of registered callbacks
+	snapCbs  []FunctionCallback            // Callbacks of the snapshot's functions (refs -1, -2...)
+	handlers map[int]*NamedPropertyHandler // Registered interceptors of ObjectTemplates
+
+	codeGenCallback   CodeGenerationFromStringsCallback // Callback for eval() and new Function()
+	rejectCallback    PromiseRejectCallback             // Callback for unhandled promise rejections
+	promiseHookCount  int                               // Number of Contexts with PromiseHooks
+	sourceMapResolver SourceMapResolver                 // Maps JSError locations to original sources
+	int64Conversion   Int64Conversion                   // How Go 64-bit integers become JS values
+	crashOnPanic      bool                              // Don't recover panics in FunctionCallbacks
+
+	handle            cgo.Handle            // Handle to this Isolate given to C callbacks, if any
+	gcPrologue        GCCallback            // Called before each garbage collection
+	gcEpilogue        GCCallback            // Called after each garbage collection
+	gcStart           time.Time             // When the current garbage collection started
+	heapLimitCallback NearHeapLimitCallback // Called when the heap nears its limit
+
+	asyncMutex   sync.Mutex     // Mutex for accessing `asyncResults` and `asyncPending`
+	asyncResults []*asyncResult // Finished async calls whose promises aren't yet settled
+	asyncPending int            // Async calls whose promises aren't yet settled
+	asyncReady   chan struct{}  // Signaled when an async call finishes
+
+	persistentsMutex sync.Mutex               // Mutex for accessing `persistents`
+	persistents      map[*Persistent]struct{} // Persistent handles not yet released
 
 	stringBuffer []byte // Temporary scratch space for cgo to copy strings to
 
@@ -59,6 +89,16 @@ type HeapStatistics struct {
 	NumberOfDetachedContexts uint64
 }
 
+// HeapSpaceStatistics represents the statistics of one of the spaces V8's heap is divided
+// into, such as "new_space", "old_space", "code_space" or "large_object_space".
+type HeapSpaceStatistics struct {
+	SpaceName          string
+	SpaceSize          uint64
+	SpaceUsedSize      uint64
+	SpaceAvailableSize uint64
+	PhysicalSpaceSize  uint64
+}
+
 const kIsolateStringBufferSize = 1024
 
 // NewIsolate creates a new V8 isolate. Only one thread may access
@@ -68,8 +108,44 @@ const kIsolateStringBufferSize = 1024
 // by calling iso.Dispose().
 // An *Isolate can be used as a v8go.ContextOption to create a new
 // Context, rather than creating a new default Isolate.
-func NewIsolate() *Isolate {
-	return NewIsolateWith(0, 0)
+// NewIsolate panics if an option is not valid, such as startup data that
+// is not a valid snapshot, or that defines a different number of functions
+// than the callbacks given with it.
+func NewIsolate(opts ...IsolateOption) *Isolate {
+	var o isolateOptions
+	for _, opt := range opts {
+		opt.applyIsolate(&o)
+	}
+	iso, err := newIsolate(0, 0, o.startupData, o.callbacks)
+	if err != nil {
+		panic(err)
+	}
+	return iso
+}
+
+// IsolateOption sets an option for NewIsolate.
+type IsolateOption interface {
+	applyIsolate(*isolateOptions)
+}
+
+type isolateOptions struct {
+	startupData []byte
+	callbacks   []FunctionCallback
+}
+
+type isolateOptionFunc func(*isolateOptions)
+
+func (f isolateOptionFunc) applyIsolate(o *isolateOptions) { f(o) }
+
+// WithStartupData creates the Isolate from a snapshot returned by
+// CreateSnapshot or CreateSnapshotWithFunctions. If the snapshot was
+// created with functions, the callbacks implement them: callbacks[i] is
+// called for the function named functions[i].
+func WithStartupData(blob []byte, callbacks ...FunctionCallback) IsolateOption {
+	return isolateOptionFunc(func(o *isolateOptions) {
+		o.startupData = blob
+		o.callbacks = callbacks
+	})
 }
 
 // NewIsolateWith creates a new V8 isolate with control over the
@@ -79,13 +155,35 @@ func NewIsolate() *Isolate {
 // The heap sizes are given in bytes. If both are zero, the default
 // heap settings are used.
 func NewIsolateWith(initialHeap uint64, maxHeap uint64) *Isolate {
+	iso, _ := newIsolate(initialHeap, maxHeap, nil, nil)
+	return iso
+}
+
+// newIsolate creates an Isolate, from a snapshot if it's not nil, with the callbacks of
+// the snapshot's functions. It returns an error if the snapshot is not valid or defines a
+// different number of functions.
+func newIsolate(initialHeap uint64, maxHeap uint64, snapshot []byte, callbacks []FunctionCallback) (*Isolate, error) {
 	v8once.Do(func() {
 		C.Init()
 	})
-	result := C.NewIsolate(C.size_t(initialHeap), C.size_t(maxHeap))
+	var cSnapshot *C.char
+	if len(snapshot) > 0 {
+		cSnapshot = (*C.char)(unsafe.Pointer(&snapshot[0]))
+	}
+	result := C.NewIsolate(C.size_t(initialHeap), C.size_t(maxHeap), cSnapshot, C.int(len(snapshot)))
+	if result.isolate == nil {
+		return nil, errors.New("v8go: invalid snapshot")
+	}
+	if n := int(result.snapshotFunctions); n != len(callbacks) {
+		C.IsolateDispose(result.isolate)
+		return nil, fmt.Errorf("v8go: snapshot defines %d functions but %d callbacks were given", n, len(callbacks))
+	}
 	iso := &Isolate{
 		ptr:          result.isolate,
 		cbs:          make(map[int]FunctionCallback),
+		snapCbs:      callbacks,
+		handlers:     make(map[int]*NamedPropertyHandler),
+		asyncReady:   make(chan struct{}, 1),
 		stringBuffer: make([]byte, kIsolateStringBufferSize),
 	}
 	iso.internalContext = &Context{
@@ -96,7 +194,7 @@ func NewIsolateWith(initialHeap uint64, maxHeap uint64) *Isolate {
 	iso.undefined = &Value{result.undefinedVal, iso.internalContext}
 	iso.falseVal = &Value{result.falseVal, iso.internalContext}
 	iso.trueVal = &Value{result.trueVal, iso.internalContext}
-	return iso
+	return iso, nil
 }
 
 // TerminateExecution terminates forcefully the current thread
@@ -105,6 +203,12 @@ func (i *Isolate) TerminateExecution() {
 	C.IsolateTerminateExecution(i.ptr)
 }
 
+// CancelTerminateExecution resumes execution capability, if it was
+// previously terminated by calling TerminateExecution.
+func (i *Isolate) CancelTerminateExecution() {
+	C.IsolateCancelTerminateExecution(i.ptr)
+}
+
 // IsExecutionTerminating returns whether V8 is currently terminating
 // Javascript execution. If true, there are still JavaScript frames
 // on the stack and the termination exception is still active.
@@ -112,16 +216,25 @@ func (i *Isolate) IsExecutionTerminating() bool {
 	return C.IsolateIsExecutionTerminating(i.ptr) == 1
 }
 
+// CompileOptions are the options passed to Isolate.CompileUnboundScript
+// and Context.CompileModuleWithOptions.
 type CompileOptions struct {
 	CachedData *CompilerCachedData
 
+	// Mode controls how much of the script is compiled up front; see CompileMode.
+	// It can't be combined with CachedData.
 	Mode CompileMode
+
+	// Origin, if not nil, describes the script in more detail than the origin
+	// argument, which it replaces.
+	Origin *ScriptOrigin
 }
 
 // CompileUnboundScript will create an UnboundScript (i.e. context-indepdent)
 // using the provided source JavaScript, origin (a.k.a. filename), and options.
 // If options contain a non-null CachedData, compilation of the script will use
-// that code cache.
+// that code cache. A CachedData with no Bytes is always rejected, so a missing
+// or empty cache file can be passed through without checking it first.
 // error will be of type `JSError` if not nil.
 func (i *Isolate) CompileUnboundScript(source, origin string, opts CompileOptions) (*UnboundScript, error) {
 	var cOptions C.CompileOptions
@@ -129,21 +242,79 @@ func (i *Isolate) CompileUnboundScript(source, origin string, opts CompileOption
 		if opts.Mode != 0 {
 			panic("On CompileOptions, Mode and CachedData can't both be set")
 		}
-		cOptions.compileOption = C.ScriptCompilerConsumeCodeCache
-		cOptions.cachedData = C.ScriptCompilerCachedData{
-			data:   (*C.uchar)(unsafe.Pointer(&opts.CachedData.Bytes[0])),
-			length: C.int(len(opts.CachedData.Bytes)),
+		if len(opts.CachedData.Bytes) > 0 {
+			cOptions.compileOption = C.ScriptCompilerConsumeCodeCache
+			cOptions.cachedData = C.ScriptCompilerCachedData{
+				data:   (*C.uchar)(unsafe.Pointer(&opts.CachedData.Bytes[0])),
+				length: C.int(len(opts.CachedData.Bytes)),
+			}
 		}
 	} else {
 		cOptions.compileOption = C.int(opts.Mode)
 	}
 
-	rtn := C.IsolateCompileUnboundScriptGo(i.ptr, source, origin, cOptions)
+	scriptOrigin := ScriptOrigin{ResourceName: origin}
+	if opts.Origin != nil {
+		scriptOrigin = *opts.Origin
+	}
+	cOrigin := newCScriptOrigin(scriptOrigin)
+	defer freeCScriptOrigin(cOrigin)
+
+	rtn := C.IsolateCompileUnboundScriptGo(i.ptr, source, cOrigin, cOptions)
 	if rtn.ptr == nil {
-		return nil, newJSError(rtn.error)
+		return nil, newJSError(i, rtn.error)
 	}
 	if opts.CachedData != nil {
-		opts.CachedData.Rejected = int(rtn.cachedDataRejected) == 1
+		opts.CachedData.Rejected = cOptions.cachedData.data == nil || int(rtn.cachedDataRejected) == 1
+	}
+	return &UnboundScript{
+		ptr: rtn.ptr,
+		iso: i,
+	}, nil
+}
+
+// CheckSyntax compiles the source JavaScript without running it, to find out whether it
+// is syntactically valid; origin (a.k.a. filename) is used in the result.
+// Like CompileUnboundScript, the bodies of functions are only pre-parsed, which detects
+// syntax errors but not every early error. error will be of type `*SyntaxError` if not nil.
+func (i *Isolate) CheckSyntax(source, origin string) error {
+	cOrigin := newCScriptOrigin(ScriptOrigin{ResourceName: origin})
+	defer freeCScriptOrigin(cOrigin)
+
+	rtn := C.IsolateCheckSyntaxGo(i.ptr, source, cOrigin)
+	if rtn.msg == nil {
+		return nil
+	}
+	err := &SyntaxError{
+		Message:      C.GoString(rtn.msg),
+		ResourceName: origin,
+		Line:         int(rtn.line),
+		StartColumn:  int(rtn.startColumn),
+		EndColumn:    int(rtn.endColumn),
+		SourceLine:   C.GoString(rtn.sourceLine),
+	}
+	C.free(unsafe.Pointer(rtn.msg))
+	C.free(unsafe.Pointer(rtn.sourceLine))
+	return err
+}
+
+// CompileUnboundScriptStream is like CompileUnboundScript, but reads the source from r.
+// The source is parsed on a background thread while it is being read.
+// The source must be UTF-8. An error from r is returned as-is; otherwise error
+// will be of type `JSError` if not nil.
+func (i *Isolate) CompileUnboundScriptStream(r io.Reader, origin string) (*UnboundScript, error) {
+	streamer, err := streamScript(i, r)
+	if err != nil {
+		return nil, err
+	}
+	defer C.ScriptStreamerFree(streamer)
+
+	cOrigin := C.CString(origin)
+	defer C.free(unsafe.Pointer(cOrigin))
+
+	rtn := C.ScriptStreamerCompileUnboundScript(streamer, cOrigin, C.int(len(origin)))
+	if rtn.ptr == nil {
+		return nil, newJSError(i, rtn.error)
 	}
 	return &UnboundScript{
 		ptr: rtn.ptr,
@@ -170,6 +341,81 @@ func (i *Isolate) GetHeapStatistics() HeapStatistics {
 	}
 }
 
+// GetHeapSpaceStatistics returns the statistics of each space of the isolate's heap.
+// Their totals are in GetHeapStatistics, along with external and malloc'd memory.
+// It returns nil if the isolate has been disposed.
+func (i *Isolate) GetHeapSpaceStatistics() []HeapSpaceStatistics {
+	if i.ptr == nil {
+		return nil
+	}
+	var count C.int
+	cSpaces := C.IsolateGetHeapSpaceStatistics(i.ptr, &count)
+	if cSpaces == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(cSpaces))
+	spaces := make([]HeapSpaceStatistics, int(count))
+	for n, hs := range (*[1 << 10]C.HeapSpaceStatisticsData)(unsafe.Pointer(cSpaces))[:count:count] {
+		spaces[n] = HeapSpaceStatistics{
+			SpaceName:          C.GoString(hs.space_name),
+			SpaceSize:          uint64(hs.space_size),
+			SpaceUsedSize:      uint64(hs.space_used_size),
+			SpaceAvailableSize: uint64(hs.space_available_size),
+			PhysicalSpaceSize:  uint64(hs.physical_space_size),
+		}
+	}
+	return spaces
+}
+
+// CollectGarbage performs full garbage collections until no more memory can be freed.
+// It is much slower than letting V8 collect garbage when it decides to, but makes the
+// collection of unreachable objects deterministic: afterwards, weak Persistent callbacks
+// have run, WeakRefs to unreachable objects are cleared (unless kept alive by a WeakRef
+// dereference in the current job; see ClearKeptObjects), and FinalizationRegistry
+// cleanup callbacks are scheduled, to run at the next PumpMessageLoop.
+// Using SetFlags("--expose-gc") instead exposes a `gc()` function to JavaScript.
+func (i *Isolate) CollectGarbage() {
+	C.IsolateCollectGarbage(i.ptr)
+}
+
+// MemoryPressureLevel is how much memory pressure the process is under.
+type MemoryPressureLevel int
+
+const (
+	MemoryPressureNone MemoryPressureLevel = iota
+	MemoryPressureModerate
+	MemoryPressureCritical
+)
+
+// MemoryPressureNotification tells V8 how much memory pressure the process is under, e.g.
+// as it nears a cgroup's memory limit. V8 then collects garbage and shrinks the heap more
+// (or, for MemoryPressureNone, less) aggressively. Unlike most Isolate methods it may be
+// called from any goroutine, even while the Isolate is running a script. V8 responds
+// right away only if the calling goroutine holds the Isolate's Lock; otherwise it posts a
+// task, which runs on the next PumpMessageLoop (or possibly sooner, if a script is
+// running.)
+func (i *Isolate) MemoryPressureNotification(level MemoryPressureLevel) {
+	C.IsolateMemoryPressureNotification(i.ptr, C.int(level))
+}
+
+// ClearKeptObjects releases the objects that WeakRefs have kept alive since they were
+// created or dereferenced, so that they can be collected. V8 does this automatically
+// whenever it runs microtasks, which is when a call into JavaScript returns; it only needs
+// to be called explicitly within a job, e.g. in a Go callback between two `deref()` calls.
+func (i *Isolate) ClearKeptObjects() {
+	C.IsolateClearKeptObjects(i.ptr)
+}
+
+// PumpMessageLoop runs the tasks V8 has scheduled for this isolate, such as the cleanup
+// callbacks of FinalizationRegistries whose targets have been garbage collected, until
+// none are left. It returns true if any tasks were run. v8go does not run an event loop,
+// so these tasks are only run when this is called. It also settles the promises of async
+// calls whose callbacks have finished; see NewAsyncFunctionTemplate.
+func (i *Isolate) PumpMessageLoop() bool {
+	i.settleAsyncResults()
+	return C.IsolatePumpMessageLoop(i.ptr) != 0
+}
+
 // Dispose will dispose the Isolate VM; subsequent calls will panic.
 func (i *Isolate) Dispose() {
 	if i.ptr == nil {
@@ -178,8 +424,26 @@ func (i *Isolate) Dispose() {
 	if i.v8Lock != nil {
 		i.Unlock()
 	}
+	i.releasePersistents()
 	C.IsolateDispose(i.ptr)
 	i.ptr = nil
+	if i.handle != 0 {
+		i.handle.Delete()
+		i.handle = 0
+	}
+}
+
+// cHandle returns a handle to the Isolate for C callbacks, which find it with
+// isolateFromHandle. The handle is deleted by Dispose.
+func (i *Isolate) cHandle() C.uintptr_t {
+	if i.handle == 0 {
+		i.handle = cgo.NewHandle(i)
+	}
+	return C.uintptr_t(i.handle)
+}
+
+func isolateFromHandle(handle C.uintptr_t) *Isolate {
+	return cgo.Handle(handle).Value().(*Isolate)
 }
 
 // Acquires a V8 lock on the Isolate for this thread. This speeds up subsequent calls involving
@@ -224,6 +488,15 @@ func (i *Isolate) ThrowException(value *Value) *Value {
 	}
 }
 
+// SetCrashOnCallbackPanic sets whether a panic in a FunctionCallback of this Isolate crashes
+// the process. By default it doesn't: the panic is recovered and thrown to JS as an Error
+// whose message is "Go panic: " followed by the panic value, and whose `goStack` property
+// is the stack trace of the panicking goroutine. Letting panics crash can be useful when
+// debugging.
+func (i *Isolate) SetCrashOnCallbackPanic(crash bool) {
+	i.crashOnPanic = crash
+}
+
 // Deprecated: use `iso.Dispose()`.
 func (i *Isolate) Close() {
 	i.Dispose()
@@ -243,7 +516,25 @@ func (i *Isolate) registerCallback(cb FunctionCallback) int {
 }
 
 func (i *Isolate) getCallback(ref int) FunctionCallback {
+	if ref < 0 {
+		return i.snapCbs[-ref-1]
+	}
 	i.cbMutex.RLock()
 	defer i.cbMutex.RUnlock()
 	return i.cbs[ref]
 }
+
+func (i *Isolate) registerHandler(h *NamedPropertyHandler) int {
+	i.cbMutex.Lock()
+	i.cbSeq++
+	ref := i.cbSeq
+	i.handlers[ref] = h
+	i.cbMutex.Unlock()
+	return ref
+}
+
+func (i *Isolate) getHandler(ref int) *NamedPropertyHandler {
+	i.cbMutex.RLock()
+	defer i.cbMutex.RUnlock()
+	return i.handlers[ref]
+}
@@ -159,6 +159,69 @@ func TestIsolateGetHeapStatistics(t *testing.T) {
 	}
 }
 
+func TestIsolateGetHeapSpaceStatistics(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	_, err := ctx.RunScript(`var big = new Array(1e6).fill(1)`, "")
+	fatalIf(t, err)
+
+	spaces := iso.GetHeapSpaceStatistics()
+	var used uint64
+	names := map[string]bool{}
+	for _, space := range spaces {
+		names[space.SpaceName] = true
+		used += space.SpaceUsedSize
+		if space.SpaceUsedSize > space.SpaceSize {
+			t.Errorf("%s: used size %d exceeds size %d", space.SpaceName, space.SpaceUsedSize, space.SpaceSize)
+		}
+	}
+	for _, name := range []string{"new_space", "old_space", "code_space", "large_object_space"} {
+		if !names[name] {
+			t.Errorf("missing heap space %q in %v", name, names)
+		}
+	}
+	if total := iso.GetHeapStatistics().UsedHeapSize; used != total {
+		t.Errorf("expected spaces' used sizes to add up to %d, got %d", total, used)
+	}
+}
+
+func TestIsolateMemoryPressureNotification(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	var fullGCs int
+	iso.SetGCCallbacks(nil, func(info v8.GCInfo) {
+		if info.Type == v8.GCTypeMarkSweepCompact {
+			fullGCs++
+		}
+	})
+	_, err := ctx.RunScript(`var garbage = new Array(1e5).fill({}); garbage = null`, "")
+	fatalIf(t, err)
+	fullGCs = 0
+	iso.MemoryPressureNotification(v8.MemoryPressureCritical)
+	iso.PumpMessageLoop()
+	if fullGCs == 0 {
+		t.Error("expected critical memory pressure to collect garbage")
+	}
+
+	// With the Isolate locked, V8 collects garbage right away.
+	iso.MemoryPressureNotification(v8.MemoryPressureNone)
+	fullGCs = 0
+	iso.Lock()
+	iso.MemoryPressureNotification(v8.MemoryPressureCritical)
+	iso.Unlock()
+	if fullGCs == 0 {
+		t.Error("expected critical memory pressure to collect garbage while locked")
+	}
+	iso.MemoryPressureNotification(v8.MemoryPressureNone)
+}
+
 func TestCallbackRegistry(t *testing.T) {
 	t.Parallel()
 
@@ -197,6 +260,9 @@ func TestIsolateDispose(t *testing.T) {
 	if iso.GetHeapStatistics().TotalHeapSize != 0 {
 		t.Error("Isolate not disposed correctly")
 	}
+	if iso.GetHeapSpaceStatistics() != nil {
+		t.Error("expected no heap space statistics after Dispose")
+	}
 }
 
 func TestIsolateThrowException(t *testing.T) {
@@ -296,3 +362,128 @@ func makeObject() interface{} {
 		"b": "AAAABBBBAAAABBBBAAAABBBBAAAABBBBAAAABBBB",
 	}
 }
+
+func TestIsolateCompileUnboundScriptStream(t *testing.T) {
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	_, err := iso.CompileUnboundScriptStream(strings.NewReader("invalid js"), "filename")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	us, err := iso.CompileUnboundScriptStream(strings.NewReader("function foo() { return 'bar'; }; foo()"), "script.js")
+	fatalIf(t, err)
+
+	for i := 0; i < 2; i++ {
+		ctx := v8.NewContext(iso)
+		val, err := us.Run(ctx)
+		fatalIf(t, err)
+		if val.String() != "bar" {
+			t.Errorf("invalid value returned, expected bar got %v", val)
+		}
+		ctx.Close()
+	}
+}
+
+func TestIsolateCompileUnboundScript_EmptyCachedData(t *testing.T) {
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	opts := v8.CompileOptions{CachedData: &v8.CompilerCachedData{}}
+	us, err := iso.CompileUnboundScript("6 * 7", "script.js", opts)
+	fatalIf(t, err)
+	if !opts.CachedData.Rejected {
+		t.Error("expected empty cached data to be rejected")
+	}
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	val, err := us.Run(ctx)
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("invalid value returned, expected 42 got %v", val)
+	}
+}
+
+func TestIsolateCheckSyntax(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	fatalIf(t, iso.CheckSyntax("throw new Error('not run')", "valid.js"))
+
+	err := iso.CheckSyntax("let a = 1;\nlet b = a +* 2;", "invalid.js")
+	e, ok := err.(*v8.SyntaxError)
+	if !ok {
+		t.Fatalf("expected a SyntaxError, got %v", err)
+	}
+	if e.Message != "SyntaxError: Unexpected token '*'" {
+		t.Errorf("unexpected message: %q", e.Message)
+	}
+	if e.Line != 2 || e.StartColumn != 12 || e.EndColumn != 13 {
+		t.Errorf("unexpected position: %d:%d-%d", e.Line, e.StartColumn, e.EndColumn)
+	}
+	if e.SourceLine != "let b = a +* 2;" {
+		t.Errorf("unexpected source line: %q", e.SourceLine)
+	}
+	if e.Location() != "invalid.js:2:12" {
+		t.Errorf("unexpected location: %q", e.Location())
+	}
+}
+
+func TestIsolateFinalization(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	_, err := ctx.RunScript(`
+		var cleaned = [];
+		var registry = new FinalizationRegistry(held => cleaned.push(held));
+		var ref = (() => {
+			let target = {};
+			registry.register(target, "target");
+			return new WeakRef(target);
+		})();`, "finalization.js")
+	fatalIf(t, err)
+
+	iso.CollectGarbage()
+	val, err := ctx.RunScript("ref.deref() === undefined", "")
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Error("expected the WeakRef to be cleared")
+	}
+
+	if !iso.PumpMessageLoop() {
+		t.Error("expected the cleanup task to run")
+	}
+	val, err = ctx.RunScript("cleaned.join()", "")
+	fatalIf(t, err)
+	if s := val.String(); s != "target" {
+		t.Errorf("expected the cleanup callback to run, got %q", s)
+	}
+	if iso.PumpMessageLoop() {
+		t.Error("expected no more tasks")
+	}
+
+	// Within a job, a new WeakRef keeps its target alive until ClearKeptObjects.
+	for _, clear := range []bool{false, true} {
+		clear := clear
+		collect := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+			if clear {
+				iso.ClearKeptObjects()
+			}
+			iso.CollectGarbage()
+			return nil
+		})
+		fatalIf(t, ctx.Global().Set("collect", collect.GetFunction(ctx)))
+		val, err = ctx.RunScript("var ref2 = (() => new WeakRef({}))(); collect(); ref2.deref() === undefined", "")
+		fatalIf(t, err)
+		if val.Boolean() != clear {
+			t.Errorf("clear=%v: expected cleared=%v", clear, clear)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// Iterator iterates over a JS iterable, such as an Array, Map, Set or generator, using the
+// JS iteration protocol. A typical loop is:
+//
+//	it, err := val.Iterator()
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		item := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	iter  *Object
+	value *Value
+	err   error
+	done  bool
+}
+
+// Iterator returns an Iterator over the value, as a JS `for...of` loop would iterate it.
+// error will be of type `JSError` if the value is not iterable.
+func (v *Value) Iterator() (*Iterator, error) {
+	rtn := C.ValueGetIterator(v.valuePtr())
+	iter, err := objectResult(v.ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{iter: iter}, nil
+}
+
+// Next advances to the next item, which Value then returns. It returns false when there
+// are no more items, or if the iterator threw an exception, which Err then returns.
+func (it *Iterator) Next() bool {
+	if it.done {
+		return false
+	}
+	var done C.Bool
+	rtn := C.IteratorNext(it.iter.valuePtr(), &done)
+	it.value, it.err = valueResult(it.iter.ctx, rtn)
+	it.done = done != 0
+	if it.done {
+		it.value = nil
+	}
+	return !it.done
+}
+
+// Value returns the current item, or nil if Next hasn't been called or returned false.
+func (it *Iterator) Value() *Value {
+	return it.value
+}
+
+// Done returns true once the iteration is over, because Next returned false.
+func (it *Iterator) Done() bool {
+	return it.done
+}
+
+// Err returns the error, of type `JSError`, if the iterator threw an exception.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close ends the iteration early, calling the iterator's `return` method as a `break` out
+// of a `for...of` loop does, so that generators can run their `finally` blocks. It does
+// nothing if the iteration is already over.
+func (it *Iterator) Close() error {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	it.value = nil
+	rtn := C.IteratorReturn(it.iter.valuePtr())
+	if rtn.msg != nil {
+		return newJSError(it.iter.ctx.iso, rtn)
+	}
+	return nil
+}
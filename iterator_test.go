@@ -0,0 +1,86 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestIterator(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		source   string
+		expected string
+	}{
+		{"[1, 2, 3]", "1,2,3"},
+		{"'héllo'", "h,é,l,l,o"},
+		{"new Set(['a', 'b'])", "a,b"},
+		{"new Map([['k', 'v']])", "k,v"},
+		{"(function*() { yield 'x'; yield 'y' })()", "x,y"},
+		{"[]", ""},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.source, "")
+		fatalIf(t, err)
+		it, err := val.Iterator()
+		fatalIf(t, err)
+		var items []string
+		for it.Next() {
+			items = append(items, it.Value().String())
+		}
+		fatalIf(t, it.Err())
+		if got := strings.Join(items, ","); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.source, tt.expected, got)
+		}
+		if !it.Done() || it.Value() != nil || it.Next() {
+			t.Errorf("%s: expected the iterator to be done", tt.source)
+		}
+	}
+
+	// Closing early runs a generator's finally block.
+	val, err := ctx.RunScript(`globalThis.cleanedUp = false;
+		(function*() { try { yield 1; yield 2 } finally { cleanedUp = true } })()`, "")
+	fatalIf(t, err)
+	it, err := val.Iterator()
+	fatalIf(t, err)
+	if !it.Next() || it.Value().Int32() != 1 {
+		t.Fatal("expected a first item")
+	}
+	fatalIf(t, it.Close())
+	if cleanedUp, _ := ctx.Global().Get("cleanedUp"); !cleanedUp.Boolean() {
+		t.Error("expected the generator's finally block to run")
+	}
+	if it.Next() {
+		t.Error("expected no more items after Close")
+	}
+
+	// Exceptions thrown by the iterator.
+	val, err = ctx.RunScript("(function*() { yield 1; throw new Error('oops') })()", "")
+	fatalIf(t, err)
+	it, err = val.Iterator()
+	fatalIf(t, err)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if count != 1 || it.Err() == nil || !strings.Contains(it.Err().Error(), "oops") {
+		t.Errorf("expected 1 item then an error, got %d, %v", count, it.Err())
+	}
+
+	for _, source := range []string{"42", "({})", "null", "({[Symbol.iterator]: () => 1})"} {
+		val, err := ctx.RunScript(source, "")
+		fatalIf(t, err)
+		if _, err := val.Iterator(); err == nil {
+			t.Errorf("%s: expected an error", source)
+		}
+	}
+}
@@ -27,6 +27,8 @@ func JSONParse(ctx *Context, str string) (*Value, error) {
 }
 
 // JSONStringify tries to stringify the JSON-serializable object value and returns it as string.
+// If stringifying throws, e.g. because the value contains a BigInt or a circular reference,
+// the error will be of type `JSError`.
 func JSONStringify(ctx *Context, val Valuer) (string, error) {
 	var v *Value
 	if val != nil {
@@ -42,6 +44,9 @@ func JSONStringify(ctx *Context, val Valuer) (string, error) {
 
 	s := C.JSONStringify(v.valuePtr(), bufPtr, C.int(len(buffer)))
 	if s.data == nil {
+		if s.error.msg != nil {
+			return "", newJSError(v.ctx.iso, s.error)
+		}
 		return "", errors.New("v8go could not encode Value to JSON")
 	} else if unsafe.Pointer(s.data) == bufPtr {
 		return string(buffer[0:s.length]), nil
@@ -51,3 +56,15 @@ func JSONStringify(ctx *Context, val Valuer) (string, error) {
 		return C.GoStringN(s.data, C.int(s.length)), nil
 	}
 }
+
+// JSONParse parses the JSON string, as `JSON.parse` does, without running a script.
+// error will be of type `JSError` if the string is not valid JSON.
+func (c *Context) JSONParse(str string) (*Value, error) {
+	return JSONParse(c, str)
+}
+
+// JSONStringify converts the value to a JSON string, as `JSON.stringify` does, without
+// running a script. error will be of type `JSError` if stringifying throws.
+func (c *Context) JSONStringify(val Valuer) (string, error) {
+	return JSONStringify(c, val)
+}
@@ -42,6 +42,34 @@ func TestJSONStringify(t *testing.T) {
 	}
 }
 
+func TestContextJSON(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.JSONParse(`{"s": "quote \" and \u00e9", "n": [1, 2.5, null]}`)
+	fatalIf(t, err)
+	str, err := ctx.JSONStringify(val)
+	fatalIf(t, err)
+	if want := `{"s":"quote \" and é","n":[1,2.5,null]}`; str != want {
+		t.Errorf("expected %s, got %s", want, str)
+	}
+
+	for _, source := range []string{"({big: 1n})", "const o = {}; o.o = o; o"} {
+		val, err := ctx.RunScript(source, "test.js")
+		fatalIf(t, err)
+		_, err = ctx.JSONStringify(val)
+		if _, ok := err.(*v8.JSError); !ok {
+			t.Errorf("%s: expected a JSError, got %v", source, err)
+		}
+	}
+	if _, err := ctx.JSONParse("{'a': 1}"); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
 func ExampleJSONParse() {
 	ctx := v8.NewContext()
 	defer ctx.Isolate().Dispose()
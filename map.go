@@ -0,0 +1,111 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+)
+
+// Map is a JavaScript Map object, a subtype of Object.
+// Its Get, Set, Has and Delete methods operate on the map's entries; the object's
+// properties can still be accessed through its Object.
+type Map struct {
+	*Object
+}
+
+// NewMap creates a new, empty Map.
+func (c *Context) NewMap() *Map {
+	return &Map{&Object{&Value{C.NewMap(c.ptr), c}}}
+}
+
+// AsMap returns the value as a Map, or an error if it is not a Map.
+func (v *Value) AsMap() (*Map, error) {
+	if !v.IsMap() {
+		return nil, errors.New("v8go: value is not a Map")
+	}
+	return &Map{&Object{v}}, nil
+}
+
+// Size returns the number of entries in the map.
+func (m *Map) Size() int {
+	return int(C.MapSize(m.valuePtr()))
+}
+
+// Get returns the value for the given key, or undefined if the map has no such key.
+// The key may be a Value or any Go type that can be passed to NewValue.
+func (m *Map) Get(key interface{}) (*Value, error) {
+	k, err := m.ctx.NewValue(key)
+	if err != nil {
+		return nil, err
+	}
+	return valueResult(m.ctx, C.MapGet(m.valuePtr(), k.valuePtr()))
+}
+
+// Set sets the value for the given key.
+// The key and value may be Values or any Go type that can be passed to NewValue.
+func (m *Map) Set(key, val interface{}) error {
+	k, err := m.ctx.NewValue(key)
+	if err != nil {
+		return err
+	}
+	v, err := m.ctx.NewValue(val)
+	if err != nil {
+		return err
+	}
+	_, err = valueResult(m.ctx, C.MapSet(m.valuePtr(), k.valuePtr(), v.valuePtr()))
+	return err
+}
+
+// Has returns true if the map has an entry for the given key.
+func (m *Map) Has(key interface{}) (bool, error) {
+	k, err := m.ctx.NewValue(key)
+	if err != nil {
+		return false, err
+	}
+	return C.MapHas(m.valuePtr(), k.valuePtr()) != 0, nil
+}
+
+// Delete removes the entry for the given key, returning true if there was one.
+func (m *Map) Delete(key interface{}) (bool, error) {
+	k, err := m.ctx.NewValue(key)
+	if err != nil {
+		return false, err
+	}
+	return C.MapDelete(m.valuePtr(), k.valuePtr()) != 0, nil
+}
+
+// Clear removes all entries from the map.
+func (m *Map) Clear() {
+	C.MapClear(m.valuePtr())
+}
+
+// Entries returns the map's keys and values, in insertion order.
+func (m *Map) Entries() (keys, values []*Value) {
+	pairs := m.asArray()
+	keys = make([]*Value, len(pairs)/2)
+	values = make([]*Value, len(pairs)/2)
+	for i := range keys {
+		keys[i], values[i] = pairs[2*i], pairs[2*i+1]
+	}
+	return keys, values
+}
+
+func (m *Map) asArray() []*Value {
+	arr := &Array{Object{&Value{C.MapAsArray(m.valuePtr()), m.ctx}}}
+	return arr.values()
+}
+
+// ToGoMap converts the map to a Go map, converting its keys and values deeply as described
+// for Value.Export. It returns an error if a key or value can't be converted, or if a key
+// converts to a Go value that can't be a map key, such as a slice.
+func (m *Map) ToGoMap() (map[interface{}]interface{}, error) {
+	gv, err := m.Value.export(nil)
+	if err != nil {
+		return nil, err
+	}
+	return gv.(map[interface{}]interface{}), nil
+}
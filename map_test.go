@@ -0,0 +1,158 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	m := ctx.NewMap()
+	fatalIf(t, m.Set("a", int32(1)))
+	fatalIf(t, m.Set(int32(2), "two"))
+	if n := m.Size(); n != 2 {
+		t.Errorf("unexpected size: %d", n)
+	}
+	val, err := m.Get("a")
+	fatalIf(t, err)
+	if val.Int32() != 1 {
+		t.Errorf("unexpected value for 'a': %v", val)
+	}
+	if val, _ := m.Get("2"); !val.IsUndefined() {
+		t.Errorf("expected the key 2 not to match '2', got %v", val)
+	}
+	if ok, _ := m.Has(int32(2)); !ok {
+		t.Error("expected the map to have the key 2")
+	}
+	if ok, _ := m.Delete("a"); !ok {
+		t.Error("expected 'a' to be deleted")
+	}
+	if ok, _ := m.Delete("a"); ok {
+		t.Error("expected 'a' to be deleted only once")
+	}
+
+	keys, values := m.Entries()
+	if len(keys) != 1 || keys[0].Int32() != 2 || values[0].String() != "two" {
+		t.Errorf("unexpected entries: %v, %v", keys, values)
+	}
+	m.Clear()
+	if n := m.Size(); n != 0 {
+		t.Errorf("expected the map to be empty, got size %d", n)
+	}
+
+	fatalIf(t, ctx.Global().Set("m", m))
+	val, err = ctx.RunScript("m.set('x', 42); m instanceof Map && m.get('x')", "test.js")
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("unexpected value from JS: %v", val)
+	}
+
+	val, _ = ctx.RunScript("({})", "test.js")
+	if _, err := val.AsMap(); err == nil {
+		t.Error("expected an error for an object that is not a Map")
+	}
+}
+
+func TestMapToGoMap(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`new Map([
+		["str", "s"], [1, true], [null, {a: [1, "b"], n: null}],
+		[10n, new Set([1, 1, 2])], ["nested", new Map([["x", 1]])],
+	])`, "test.js")
+	fatalIf(t, err)
+	m, err := val.AsMap()
+	fatalIf(t, err)
+	got, err := m.ToGoMap()
+	fatalIf(t, err)
+
+	var bigKey interface{}
+	for k := range got {
+		if b, ok := k.(*big.Int); ok {
+			bigKey = b
+			if b.Int64() != 10 {
+				t.Errorf("unexpected BigInt key: %v", b)
+			}
+		}
+	}
+	want := map[interface{}]interface{}{
+		"str":    "s",
+		1.0:      true,
+		nil:      map[string]interface{}{"a": []interface{}{1.0, "b"}, "n": nil},
+		bigKey:   []interface{}{1.0, 2.0},
+		"nested": map[interface{}]interface{}{"x": 1.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected Go map:\n got: %#v\nwant: %#v", got, want)
+	}
+
+	for _, source := range []string{
+		"new Map([['f', () => 1]])",
+		"new Map([[Symbol(), 1]])",
+		"new Map([[[1], 1]])",
+		"const m = new Map(); m.set('self', m); m",
+	} {
+		val, err := ctx.RunScript(source, "test.js")
+		fatalIf(t, err)
+		m, _ := val.AsMap()
+		if _, err := m.ToGoMap(); err == nil {
+			t.Errorf("%s: expected an error", source)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	s := ctx.NewSet()
+	fatalIf(t, s.Add("a"))
+	fatalIf(t, s.Add("a"))
+	fatalIf(t, s.Add(int32(3)))
+	if n := s.Size(); n != 2 {
+		t.Errorf("unexpected size: %d", n)
+	}
+	if ok, _ := s.Has("a"); !ok {
+		t.Error("expected the set to have 'a'")
+	}
+	if ok, _ := s.Delete("a"); !ok {
+		t.Error("expected 'a' to be deleted")
+	}
+	if vals := s.Values(); len(vals) != 1 || vals[0].Int32() != 3 {
+		t.Errorf("unexpected values: %v", vals)
+	}
+
+	val, err := ctx.RunScript("new Set(['x', [1, 2], new Date(0)])", "test.js")
+	fatalIf(t, err)
+	s, err = val.AsSet()
+	fatalIf(t, err)
+	got, err := s.ToGoSlice()
+	fatalIf(t, err)
+	if len(got) != 3 || got[0] != "x" || !reflect.DeepEqual(got[1], []interface{}{1.0, 2.0}) {
+		t.Errorf("unexpected Go slice: %#v", got)
+	}
+
+	s.Clear()
+	if n := s.Size(); n != 0 {
+		t.Errorf("expected the set to be empty, got size %d", n)
+	}
+	if _, err := ctx.Global().Value.AsSet(); err == nil {
+		t.Error("expected an error for an object that is not a Set")
+	}
+}
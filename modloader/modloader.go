@@ -0,0 +1,199 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package modloader loads ES modules from an fs.FS, so that JavaScript run with v8go
+// can use `import` and `import()` without the embedder writing a module resolver.
+//
+// Modules are identified by their path in the file system, which is also their origin
+// in stack traces. Specifiers starting with "./" or "../" are resolved relative to the
+// importing module (or script); all others, including those starting with "/", are
+// resolved relative to the root of the file system. Files imported with
+// `assert {type: "json"}` are loaded as JSON modules.
+package modloader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+// Options configure a Loader.
+type Options struct {
+	// Extensions are appended in turn to a specifier that doesn't name a file,
+	// e.g. []string{".js", ".mjs"} lets "./util" import "util.js".
+	Extensions []string
+
+	// CodeCache maps module paths to code caches to compile them with, as returned by
+	// Loader.CodeCache, e.g. in a previous run of the process. A code cache that doesn't
+	// match its module's source, or the V8 version and flags, is ignored.
+	CodeCache map[string][]byte
+
+	// CreateCodeCache makes the Loader create a code cache for each JavaScript module
+	// it compiles, for Loader.CodeCache to return.
+	CreateCodeCache bool
+}
+
+// Loader loads the modules imported in a Context from a file system. Each module is read
+// and compiled once; later imports of the same path get the same Module.
+type Loader struct {
+	ctx     *v8.Context
+	fsys    fs.FS
+	opts    Options
+	modules map[string]*v8.Module // Compiled modules, by path and then type
+	caches  map[string][]byte     // Code caches created for modules, by path
+}
+
+// New creates a Loader for the given Context, and sets it as the Context's
+// ResolveModuleCallback and DynamicImportCallback.
+func New(ctx *v8.Context, fsys fs.FS, opts Options) *Loader {
+	l := &Loader{
+		ctx:     ctx,
+		fsys:    fsys,
+		opts:    opts,
+		modules: make(map[string]*v8.Module),
+		caches:  make(map[string][]byte),
+	}
+	ctx.SetResolveModuleCallback(l.resolve)
+	ctx.SetDynamicImportCallback(l.importDynamically)
+	return l
+}
+
+// Load returns the module at the given path, reading and compiling it if it hasn't
+// been loaded yet. The module is not instantiated or evaluated.
+func (l *Loader) Load(name string) (*v8.Module, error) {
+	return l.load(name, "")
+}
+
+// load loads the module at the given path, as JavaScript if typ is empty,
+// or as JSON if it is "json".
+func (l *Loader) load(name, typ string) (*v8.Module, error) {
+	if typ != "" && typ != "json" {
+		return nil, fmt.Errorf("unsupported module type '%s'", typ)
+	}
+	p, err := l.find(strings.TrimPrefix(path.Clean(name), "/"), typ)
+	if err != nil {
+		return nil, err
+	}
+	key := p + "\x00" + typ
+	if m := l.modules[key]; m != nil {
+		return m, nil
+	}
+	source, err := fs.ReadFile(l.fsys, p)
+	if err != nil {
+		return nil, err
+	}
+	var m *v8.Module
+	if typ == "json" {
+		m, err = l.ctx.NewJSONModule(p, source)
+	} else {
+		m, err = l.compile(p, string(source))
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.modules[key] = m
+	return m, nil
+}
+
+// compile compiles a JavaScript module, using and creating code caches as configured.
+func (l *Loader) compile(p, source string) (*v8.Module, error) {
+	var opts v8.CompileOptions
+	if cache := l.opts.CodeCache[p]; len(cache) > 0 {
+		opts.CachedData = &v8.CompilerCachedData{Bytes: cache}
+	}
+	m, err := l.ctx.CompileModuleWithOptions(source, p, opts)
+	if err != nil {
+		return nil, err
+	}
+	if l.opts.CreateCodeCache {
+		if opts.CachedData != nil && !opts.CachedData.Rejected {
+			l.caches[p] = opts.CachedData.Bytes
+		} else if cache, err := m.CreateCodeCache(); err == nil {
+			l.caches[p] = cache.Bytes
+		}
+	}
+	return m, nil
+}
+
+// CodeCache returns the code caches of the JavaScript modules compiled so far, by path,
+// if Options.CreateCodeCache is set. They can be saved and passed in Options.CodeCache
+// to a later Loader, to load the same modules faster.
+func (l *Loader) CodeCache() map[string][]byte {
+	caches := make(map[string][]byte, len(l.caches))
+	for p, cache := range l.caches {
+		caches[p] = cache
+	}
+	return caches
+}
+
+// Run loads, instantiates and evaluates the module at the given path, and the modules
+// it imports. Like Context.EvaluateModule, it returns a Promise that settles once the
+// module has finished running.
+func (l *Loader) Run(name string) (*v8.Value, error) {
+	m, err := l.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.ctx.InstantiateModule(m); err != nil {
+		return nil, err
+	}
+	return l.ctx.EvaluateModule(m)
+}
+
+// Reset forgets the modules loaded so far, so that later imports read and compile
+// them again, e.g. after the files have changed. Modules that have already been
+// imported are not affected.
+func (l *Loader) Reset() {
+	l.modules = make(map[string]*v8.Module)
+}
+
+func (l *Loader) resolve(ctx *v8.Context, specifier string, referrer *v8.Module, attributes map[string]string) (*v8.Module, error) {
+	var referrerPath string
+	if referrer != nil {
+		referrerPath = referrer.Origin()
+	}
+	return l.importDynamically(ctx, specifier, referrerPath, attributes)
+}
+
+func (l *Loader) importDynamically(ctx *v8.Context, specifier string, referrer string, attributes map[string]string) (*v8.Module, error) {
+	p := specifier
+	if strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../") {
+		p = path.Join(path.Dir(referrer), specifier)
+	}
+	p = strings.TrimPrefix(path.Clean(p), "/")
+	if !fs.ValidPath(p) {
+		return nil, fmt.Errorf("Cannot find module '%s' imported from '%s'", specifier, referrer)
+	}
+	m, err := l.load(p, attributes["type"])
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Cannot find module '%s' imported from '%s'", specifier, referrer)
+	}
+	return m, err
+}
+
+// find returns the path of the file that p refers to, trying each of the extensions
+// in turn if p isn't a file.
+func (l *Loader) find(p, typ string) (string, error) {
+	if m := l.modules[p+"\x00"+typ]; m != nil {
+		return p, nil
+	}
+	if isFile(l.fsys, p) {
+		return p, nil
+	}
+	for _, ext := range l.opts.Extensions {
+		if isFile(l.fsys, p+ext) {
+			return p + ext, nil
+		}
+	}
+	return "", &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+}
+
+func isFile(fsys fs.FS, p string) bool {
+	info, err := fs.Stat(fsys, p)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package modloader_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	v8 "github.com/couchbasedeps/v8go"
+	"github.com/couchbasedeps/v8go/modloader"
+)
+
+var files = fstest.MapFS{
+	"main.js":          {Data: []byte("import {greet} from './lib/greet'; import {name} from '/lib/name.mjs'; globalThis.result = greet(name);")},
+	"lib/greet.js":     {Data: []byte("import {suffix} from '../suffix.js'; export const greet = n => 'hello ' + n + suffix;")},
+	"lib/name.mjs":     {Data: []byte("export const name = 'world';")},
+	"suffix.js":        {Data: []byte("export const suffix = '!';")},
+	"lazy/answer.js":   {Data: []byte("export default 42;")},
+	"broken.js":        {Data: []byte("import './nowhere.js';")},
+	"config.js":        {Data: []byte("import data from './data/config.json' assert {type: 'json'}; export default data.name;")},
+	"data/config.json": {Data: []byte(`{"name": "v8go"}`)},
+}
+
+func TestLoaderRun(t *testing.T) {
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	l := modloader.New(ctx, files, modloader.Options{Extensions: []string{".js", ".mjs"}})
+	if _, err := l.Run("main.js"); err != nil {
+		t.Fatal(err)
+	}
+	val, err := ctx.RunScript("result", "check.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "hello world!" {
+		t.Errorf("unexpected result %q", val)
+	}
+
+	m1, err := l.Load("suffix.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := l.Load("/suffix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m1 != m2 || m1.Status() != v8.ModuleEvaluated {
+		t.Error("expected the imported module to be cached")
+	}
+
+	l.Reset()
+	if m3, _ := l.Load("suffix.js"); m3 == m1 {
+		t.Error("expected Reset to clear the cache")
+	}
+}
+
+func TestLoaderDynamicImport(t *testing.T) {
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	modloader.New(ctx, files, modloader.Options{Extensions: []string{".js"}})
+	_, err := ctx.RunScript(`
+		var results = [];
+		import('./answer').then(ns => results.push(ns.default), e => results.push(e.message));`, "lazy/script.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.PerformMicrotaskCheckpoint()
+	val, err := ctx.RunScript("results.join()", "check.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "42" {
+		t.Errorf("unexpected result %q", val)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	l := modloader.New(ctx, files, modloader.Options{})
+	if _, err := l.Load("missing.js"); err == nil {
+		t.Error("expected error loading a missing file")
+	}
+	if _, err := l.Load("lib"); err == nil {
+		t.Error("expected error loading a directory")
+	}
+	_, err := l.Run("broken.js")
+	if err == nil || err.Error() != "Error: Cannot find module './nowhere.js' imported from 'broken.js'" {
+		t.Errorf("unexpected error %v", err)
+	}
+	_, err = ctx.RunScript("var message; import('../../etc/passwd').catch(e => message = e.message)", "main.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.PerformMicrotaskCheckpoint()
+	val, err := ctx.RunScript("message", "check.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "Cannot find module '../../etc/passwd' imported from 'main.js'" {
+		t.Errorf("unexpected error %q", val)
+	}
+}
+
+func TestLoaderJSON(t *testing.T) {
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	l := modloader.New(ctx, files, modloader.Options{})
+	if _, err := l.Run("config.js"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := l.Load("config.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := m.GetModuleNamespace().Get("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name.String() != "v8go" {
+		t.Errorf("unexpected result %q", name)
+	}
+
+	_, err = ctx.RunScript(`var message;
+		import("./data/config.json", {assert: {type: "css"}}).catch(e => message = e.message)`, "main.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.PerformMicrotaskCheckpoint()
+	val, err := ctx.RunScript("message", "check.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "unsupported module type 'css'" {
+		t.Errorf("unexpected error %q", val)
+	}
+}
+
+func TestLoaderCodeCache(t *testing.T) {
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	l := modloader.New(ctx, files, modloader.Options{Extensions: []string{".js", ".mjs"}, CreateCodeCache: true})
+	if _, err := l.Run("main.js"); err != nil {
+		t.Fatal(err)
+	}
+	caches := l.CodeCache()
+	for _, p := range []string{"main.js", "lib/greet.js", "lib/name.mjs", "suffix.js"} {
+		if len(caches[p]) == 0 {
+			t.Errorf("expected code cache for %s", p)
+		}
+	}
+
+	ctx2 := v8.NewContext()
+	defer ctx2.Isolate().Dispose()
+	defer ctx2.Close()
+	caches["suffix.js"] = []byte("stale")
+	l2 := modloader.New(ctx2, files, modloader.Options{Extensions: []string{".js", ".mjs"}, CodeCache: caches, CreateCodeCache: true})
+	if _, err := l2.Run("main.js"); err != nil {
+		t.Fatal(err)
+	}
+	val, err := ctx2.RunScript("result", "check.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "hello world!" {
+		t.Errorf("unexpected result %q", val)
+	}
+	if string(l2.CodeCache()["suffix.js"]) == "stale" {
+		t.Error("expected rejected code cache to be replaced")
+	}
+}
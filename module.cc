@@ -0,0 +1,298 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+#include "v8go.hh"
+
+
+/********** Module **********/
+
+// The import assertions of a module request, e.g. `assert {type: "json"}`, as alternating
+// keys and values for passing to Go. V8 gives them as a FixedArray of entries that are
+// `stride` long, each starting with the key and value.
+struct ImportAttributes {
+  ImportAttributes(Local<Context> context, Local<FixedArray> assertions, int stride) {
+    Isolate* iso = context->GetIsolate();
+    for (int i = 0; i + 1 < assertions->Length(); i += stride) {
+      for (int j = 0; j < 2; j++) {
+        String::Utf8Value str(iso, assertions->Get(context, i + j).As<Value>());
+        strings.emplace_back(*str, str.length());
+      }
+    }
+    for (auto& str : strings) {
+      ptrs.push_back(const_cast<char*>(str.data()));
+      lens.push_back(int(str.size()));
+    }
+  }
+
+  int count() const {return int(strings.size() / 2);}
+
+  std::vector<std::string> strings;
+  std::vector<char*> ptrs;
+  std::vector<int> lens;
+};
+
+static MaybeLocal<Module> resolveModuleCallback(Local<Context> context,
+                                                Local<String> specifier,
+                                                Local<FixedArray> import_assertions,
+                                                Local<Module> referrer) {
+  Isolate* iso = context->GetIsolate();
+  V8GoContext* ctx = V8GoContext::fromContext(context);
+
+  String::Utf8Value spec(iso, specifier);
+  ImportAttributes attrs(context, import_assertions, 3);
+  RtnModule rtn = goResolveModule(ctx->goRef, *spec, spec.length(), ctx->findModule(referrer),
+                                  attrs.count(), attrs.ptrs.data(), attrs.lens.data());
+  if (rtn.ptr == nullptr) {
+    iso->ThrowException(Exception::Error(String::NewFromUtf8(iso, rtn.error.msg).ToLocalChecked()));
+    free((void*)rtn.error.msg);
+    return MaybeLocal<Module>();
+  }
+  return rtn.ptr->ptr.Get(iso);
+}
+
+void v8go::InitializeImportMetaObject(Local<Context> context,
+                                      Local<Module> module,
+                                      Local<Object> meta) {
+  V8GoContext* ctx = V8GoContext::fromContext(context);
+  V8GoModule* m = ctx->findModule(module);
+  if (ctx->goRef == 0 || m == nullptr) {
+    return;
+  }
+  goInitializeImportMeta(ctx->goRef, m, ctx->addValue(meta));
+}
+
+static void returnData(const FunctionCallbackInfo<Value>& info) {
+  info.GetReturnValue().Set(info.Data());
+}
+
+MaybeLocal<Promise> v8go::ImportModuleDynamically(Local<Context> context,
+                                                  Local<ScriptOrModule> referrer,
+                                                  Local<String> specifier,
+                                                  Local<FixedArray> import_assertions) {
+  Isolate* iso = context->GetIsolate();
+  V8GoContext* ctx = V8GoContext::fromContext(context);
+  Local<Promise::Resolver> resolver;
+  if (!Promise::Resolver::New(context).ToLocal(&resolver)) {
+    return MaybeLocal<Promise>();
+  }
+
+  String::Utf8Value spec(iso, specifier);
+  String::Utf8Value ref(iso, referrer->GetResourceName());
+  ImportAttributes attrs(context, import_assertions, 2);
+  RtnModule rtn = goImportModuleDynamically(ctx->goRef, *spec, spec.length(), *ref, ref.length(),
+                                            attrs.count(), attrs.ptrs.data(), attrs.lens.data());
+  if (rtn.ptr == nullptr) {
+    resolver->Reject(context, Exception::Error(String::NewFromUtf8(iso, rtn.error.msg)
+                                                   .ToLocalChecked())).Check();
+    free((void*)rtn.error.msg);
+    return resolver->GetPromise();
+  }
+
+  Local<Module> module = rtn.ptr->ptr.Get(iso);
+  TryCatch try_catch(iso);
+  Local<Value> result;
+  if (module->InstantiateModule(context, resolveModuleCallback).IsNothing() ||
+      !module->Evaluate(context).ToLocal(&result)) {
+    if (try_catch.HasTerminated()) {
+      try_catch.ReThrow();
+      return MaybeLocal<Promise>();
+    }
+    resolver->Reject(context, try_catch.Exception()).Check();
+    return resolver->GetPromise();
+  }
+
+  // Evaluation returns a promise that settles once the module has run; resolve
+  // the import with the module's namespace after that.
+  if (!result->IsPromise()) {
+    resolver->Resolve(context, module->GetModuleNamespace()).Check();
+    return resolver->GetPromise();
+  }
+  Local<Function> getNamespace;
+  if (!Function::New(context, returnData, module->GetModuleNamespace()).ToLocal(&getNamespace)) {
+    return MaybeLocal<Promise>();
+  }
+  return result.As<Promise>()->Then(context, getNamespace);
+}
+
+RtnModule CompileModule(ContextPtr ctx, const char* source, int sourceLen,
+                        ScriptOriginData origin, CompileOptions opts) {
+  WithContext _with(ctx);
+  auto iso = ctx->iso;
+
+  RtnModule rtn = {};
+
+  Local<String> src;
+  if (!String::NewFromUtf8(iso, source, NewStringType::kNormal, sourceLen).ToLocal(&src)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+
+  ScriptCompiler::CachedData* cached_data = nullptr;
+  if (opts.cachedData.data) {
+    cached_data = new ScriptCompiler::CachedData(opts.cachedData.data,
+                                                 opts.cachedData.length);
+  }
+
+  ScriptCompiler::Source module_source(src, NewScriptOrigin(iso, origin, true), cached_data);
+  Local<Module> module;
+  if (!ScriptCompiler::CompileModule(iso, &module_source,
+                                     static_cast<ScriptCompiler::CompileOptions>(opts.compileOption))
+           .ToLocal(&module)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  if (cached_data) {
+    rtn.cachedDataRejected = cached_data->rejected;
+  }
+  rtn.ptr = ctx->newModule(module);
+  return rtn;
+}
+
+ScriptCompilerCachedData* ModuleCreateCodeCache(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  Local<Module> module = ptr->ptr.Get(ctx->iso);
+  // Only modules that have not been evaluated still have their unbound script.
+  if (!module->IsSourceTextModule() || module->GetStatus() >= Module::kEvaluating) {
+    return nullptr;
+  }
+
+  ScriptCompiler::CachedData* cached_data =
+      ScriptCompiler::CreateCodeCache(module->GetUnboundModuleScript());
+  if (cached_data == nullptr) {
+    return nullptr;
+  }
+  ScriptCompilerCachedData* cd = new ScriptCompilerCachedData;
+  cd->ptr = cached_data;
+  cd->data = cached_data->data;
+  cd->length = cached_data->length;
+  cd->rejected = cached_data->rejected;
+  return cd;
+}
+
+static MaybeLocal<Value> evaluateSyntheticModule(Local<Context> context,
+                                                 Local<Module> module) {
+  V8GoContext* ctx = V8GoContext::fromContext(context);
+  Isolate* iso = ctx->iso;
+  V8GoModule* m = ctx->findModule(module);
+  for (auto& e : m->syntheticExports) {
+    if (module->SetSyntheticModuleExport(iso, e.first.Get(iso), e.second.Get(iso)).IsNothing()) {
+      return MaybeLocal<Value>();
+    }
+  }
+  // Like the evaluation of any module, return a promise, which is already resolved.
+  Local<Promise::Resolver> resolver;
+  if (!Promise::Resolver::New(context).ToLocal(&resolver) ||
+      resolver->Resolve(context, Undefined(iso)).IsNothing()) {
+    return MaybeLocal<Value>();
+  }
+  return resolver->GetPromise();
+}
+
+RtnModule NewSyntheticModule(ContextPtr ctx, const char* name, int nameLen,
+                             int exportCount,
+                             const char* exportNames, const int* exportNameLens,
+                             ValuePtr exportValues[]) {
+  WithContext _with(ctx);
+  auto iso = ctx->iso;
+
+  // The export names are passed concatenated, along with their lengths.
+  std::vector<Local<String>> names;
+  for (int i = 0; i < exportCount; i++) {
+    names.push_back(String::NewFromUtf8(iso, exportNames, NewStringType::kInternalized,
+                                        exportNameLens[i]).ToLocalChecked());
+    exportNames += exportNameLens[i];
+  }
+
+  Local<Module> module = Module::CreateSyntheticModule(
+      iso, _with.makeString(name, NewStringType::kNormal, nameLen), names,
+      evaluateSyntheticModule);
+  V8GoModule* m = ctx->newModule(module);
+  for (int i = 0; i < exportCount; i++) {
+    m->syntheticExports.emplace_back(
+        Persistent<String, CopyablePersistentTraits<String>>(iso, names[i]),
+        Persistent<Value, CopyablePersistentTraits<Value>>(iso, Deref(exportValues[i])));
+  }
+
+  RtnModule rtn = {};
+  rtn.ptr = m;
+  return rtn;
+}
+
+RtnError ModuleInstantiate(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  Local<Module> module = ptr->ptr.Get(ctx->iso);
+
+  RtnError rtn = {};
+  if (module->InstantiateModule(_with.local_ctx, resolveModuleCallback).IsNothing()) {
+    rtn = _with.exceptionError();
+  }
+  return rtn;
+}
+
+RtnValue ModuleEvaluate(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  Local<Module> module = ptr->ptr.Get(ctx->iso);
+
+  RtnValue rtn = {};
+  Local<Value> result;
+  if (!module->Evaluate(_with.local_ctx).ToLocal(&result)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  if (module->GetStatus() == Module::kErrored) {
+    rtn.error = ModuleGetError(ctx, ptr);
+    return rtn;
+  }
+  rtn.value = ctx->addValue(result);
+  return rtn;
+}
+
+RtnError ModuleGetError(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  // The exception is not thrown, only stored in the module; rethrow it so that
+  // the error includes its message and location.
+  ctx->iso->ThrowException(ptr->ptr.Get(ctx->iso)->GetException());
+  return _with.exceptionError();
+}
+
+int ModuleGetStatus(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  return ptr->ptr.Get(ctx->iso)->GetStatus();
+}
+
+ValueRef ModuleGetException(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  Local<Module> module = ptr->ptr.Get(ctx->iso);
+  if (module->GetStatus() != Module::kErrored) {
+    return ctx->addValue(Undefined(ctx->iso));
+  }
+  return ctx->addValue(module->GetException());
+}
+
+ValueRef ModuleGetModuleNamespace(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  return ctx->addValue(ptr->ptr.Get(ctx->iso)->GetModuleNamespace());
+}
+
+int ModuleScriptId(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  Local<Module> module = ptr->ptr.Get(ctx->iso);
+  // (V8 only knows the script of a source text module, and forgets it if the module errors.)
+  if (!module->IsSourceTextModule() || module->GetStatus() == Module::kErrored) {
+    return 0;
+  }
+  return module->ScriptId();
+}
+
+int ModuleGetRequestCount(ContextPtr ctx, ModulePtr ptr) {
+  WithContext _with(ctx);
+  return ptr->ptr.Get(ctx->iso)->GetModuleRequests()->Length();
+}
+
+RtnString ModuleGetRequestSpecifier(ContextPtr ctx, ModulePtr ptr, int index) {
+  WithContext _with(ctx);
+  Local<FixedArray> requests = ptr->ptr.Get(ctx->iso)->GetModuleRequests();
+  Local<ModuleRequest> request = requests->Get(_with.local_ctx, index).As<ModuleRequest>();
+  return CopyString(ctx->iso, request->GetSpecifier());
+}
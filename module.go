@@ -0,0 +1,258 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static RtnModule CompileModuleGo(ContextPtr ctx, _GoString_ src, ScriptOriginData org,
+								CompileOptions options) {
+	return CompileModule(ctx, _GoStringPtr(src), _GoStringLen(src), org, options); }
+static RtnModule NewSyntheticModuleGo(ContextPtr ctx, _GoString_ name, int exportCount,
+									_GoString_ exportNames, const int* exportNameLens,
+									ValuePtr exportValues[]) {
+	return NewSyntheticModule(ctx, _GoStringPtr(name), _GoStringLen(name), exportCount,
+							_GoStringPtr(exportNames), exportNameLens, exportValues); }
+*/
+import "C"
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// ModuleStatus is the status of a Module, which advances as it is instantiated
+// and evaluated.
+type ModuleStatus int
+
+const (
+	ModuleUninstantiated ModuleStatus = iota
+	ModuleInstantiating
+	ModuleInstantiated
+	ModuleEvaluating
+	ModuleEvaluated
+	ModuleErrored
+)
+
+// Module is an ES module, i.e. a script that can use `import` and `export`.
+// A Module belongs to the Context it was compiled in. It must be instantiated
+// with Context.InstantiateModule, which links its imports, before it is run
+// with Context.EvaluateModule.
+type Module struct {
+	ptr    C.ModulePtr
+	ctx    *Context
+	origin string
+}
+
+// CompileModule compiles the source JavaScript as an ES module; origin (a.k.a. filename)
+// identifies the module in stack traces. The module is not run.
+// error will be of type `JSError` if not nil.
+func (c *Context) CompileModule(source, origin string) (*Module, error) {
+	return c.CompileModuleWithOptions(source, origin, CompileOptions{})
+}
+
+// CompileModuleWithOptions is like CompileModule, but with the given options.
+// If the options contain CachedData, e.g. from Module.CreateCodeCache, compilation of
+// the module will use that code cache. Modules can't be compiled eagerly, so the
+// options must not contain a Mode.
+// error will be of type `JSError` if not nil.
+func (c *Context) CompileModuleWithOptions(source, origin string, opts CompileOptions) (*Module, error) {
+	if opts.Mode != 0 {
+		panic("CompileOptions.Mode is not supported for modules")
+	}
+	var cOptions C.CompileOptions
+	if opts.CachedData != nil && len(opts.CachedData.Bytes) > 0 {
+		cOptions.compileOption = C.ScriptCompilerConsumeCodeCache
+		cOptions.cachedData = C.ScriptCompilerCachedData{
+			data:   (*C.uchar)(unsafe.Pointer(&opts.CachedData.Bytes[0])),
+			length: C.int(len(opts.CachedData.Bytes)),
+		}
+	}
+
+	scriptOrigin := ScriptOrigin{ResourceName: origin}
+	if opts.Origin != nil {
+		scriptOrigin = *opts.Origin
+	}
+	cOrigin := newCScriptOrigin(scriptOrigin)
+	defer freeCScriptOrigin(cOrigin)
+
+	rtn := C.CompileModuleGo(c.ptr, source, cOrigin, cOptions)
+	if rtn.ptr == nil {
+		return nil, newJSError(c.iso, rtn.error)
+	}
+	if opts.CachedData != nil {
+		opts.CachedData.Rejected = cOptions.cachedData.data == nil || int(rtn.cachedDataRejected) == 1
+	}
+	return c.addModule(rtn.ptr, scriptOrigin.ResourceName), nil
+}
+
+// NewSyntheticModule creates a module whose exports are the given values, without any
+// JavaScript source; e.g. a ResolveModuleCallback can return a synthetic module with
+// Go functions as exports for `import {get} from "couchbase"`. The name is the module's
+// origin. The values must belong to this Context's Isolate; functions can be created with
+// FunctionTemplate.GetFunction.
+func (c *Context) NewSyntheticModule(name string, exports map[string]Valuer) (*Module, error) {
+	names := make([]string, 0, len(exports))
+	for n := range exports {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	nameLens := make([]C.int, len(names)+1)
+	values := make([]Valuer, len(names))
+	for i, n := range names {
+		nameLens[i] = C.int(len(n))
+		values[i] = exports[n]
+		if values[i].value().ctx.iso != c.iso {
+			return nil, errors.New("v8go: export '" + n + "' belongs to a different isolate")
+		}
+	}
+	cValues, valueptr := convertArgs(values)
+
+	rtn := C.NewSyntheticModuleGo(c.ptr, name, C.int(len(names)),
+		strings.Join(names, ""), &nameLens[0], valueptr)
+	runtime.KeepAlive(cValues)
+	return c.addModule(rtn.ptr, name), nil
+}
+
+// NewJSONModule creates a JSON module, i.e. a module whose default export is the given
+// JSON data parsed; e.g. a ResolveModuleCallback can return a JSON module for
+// `import data from "./data.json" assert {type: "json"}`. The name is the module's origin.
+// error will be of type `JSError` if the data is not valid JSON.
+func (c *Context) NewJSONModule(name string, data []byte) (*Module, error) {
+	val, err := JSONParse(c, string(data))
+	if err != nil {
+		return nil, err
+	}
+	return c.NewSyntheticModule(name, map[string]Valuer{"default": val})
+}
+
+func (c *Context) addModule(ptr C.ModulePtr, origin string) *Module {
+	m := &Module{ptr: ptr, ctx: c, origin: origin}
+	if c.modules == nil {
+		c.modules = make(map[C.ModulePtr]*Module)
+	}
+	c.modules[m.ptr] = m
+	return m
+}
+
+// InstantiateModule links the module to the modules it imports, recursively, using
+// the Context's ResolveModuleCallback to find each imported module.
+// error will be of type `JSError` if not nil.
+func (c *Context) InstantiateModule(m *Module) error {
+	c.checkModule(m)
+	rtn := C.ModuleInstantiate(c.ptr, m.ptr)
+	if rtn.msg != nil {
+		return newJSError(c.iso, rtn)
+	}
+	return nil
+}
+
+// EvaluateModule runs the module and the modules it imports, if they haven't run yet.
+// As modules may use top-level `await`, the result is a Promise that settles once the
+// module has finished running. If the module throws before its first `await`, the
+// exception is returned as an error of type `JSError`.
+func (c *Context) EvaluateModule(m *Module) (*Value, error) {
+	c.checkModule(m)
+	rtn := C.ModuleEvaluate(c.ptr, m.ptr)
+	return valueResult(c, rtn)
+}
+
+// ErrModulePending is returned by AwaitModule if the module is still waiting for a promise
+// after the microtasks have run, i.e. one that only the host can settle.
+var ErrModulePending = errors.New("v8go: module is awaiting a promise that is still pending")
+
+// AwaitModule runs the module, like EvaluateModule, and then runs the microtasks until its
+// top-level `await`s have finished. If the module throws, or awaits a promise that is
+// rejected, the exception is returned as an error of type `JSError`.
+func (c *Context) AwaitModule(m *Module) error {
+	val, err := c.EvaluateModule(m)
+	if err != nil {
+		return err
+	}
+	promise, err := val.AsPromise()
+	if err != nil {
+		return nil // (without top-level await, the result is undefined)
+	}
+	c.PerformMicrotaskCheckpoint()
+	switch promise.State() {
+	case Rejected:
+		return newJSError(c.iso, C.ModuleGetError(c.ptr, m.ptr))
+	case Pending:
+		return ErrModulePending
+	}
+	return nil
+}
+
+func (c *Context) checkModule(m *Module) {
+	if m.ctx != c {
+		panic("module belongs to a different context")
+	}
+}
+
+// Status returns the status of the module.
+func (m *Module) Status() ModuleStatus {
+	return ModuleStatus(C.ModuleGetStatus(m.ctx.ptr, m.ptr))
+}
+
+// Exception returns the exception that the module threw, if its Status is
+// ModuleErrored; otherwise it returns undefined.
+func (m *Module) Exception() *Value {
+	return &Value{C.ModuleGetException(m.ctx.ptr, m.ptr), m.ctx}
+}
+
+// CreateCodeCache creates a code cache for the module, which can be passed to
+// Context.CompileModuleWithOptions, even in another Isolate or process, to compile the
+// same source faster. A code cache can only be created for a module compiled from
+// source that hasn't been evaluated yet.
+func (m *Module) CreateCodeCache() (*CompilerCachedData, error) {
+	rtn := C.ModuleCreateCodeCache(m.ctx.ptr, m.ptr)
+	if rtn == nil {
+		return nil, errors.New("v8go: can't create code cache for a module that has been evaluated or has no source")
+	}
+	cachedData := &CompilerCachedData{
+		Bytes:    C.GoBytes(unsafe.Pointer(rtn.data), rtn.length),
+		Rejected: int(rtn.rejected) == 1,
+	}
+	C.ScriptCompilerCachedDataDelete(rtn)
+	return cachedData, nil
+}
+
+// GetModuleNamespace returns the module's namespace object, whose properties are the
+// module's exports, with the default export as "default". The module must have been
+// instantiated; the values of the exports are only set once it has been evaluated.
+func (m *Module) GetModuleNamespace() *Object {
+	if m.Status() < ModuleInstantiated {
+		panic("module namespace is not available until the module is instantiated")
+	}
+	return &Object{&Value{C.ModuleGetModuleNamespace(m.ctx.ptr, m.ptr), m.ctx}}
+}
+
+// Origin returns the origin (a.k.a. filename) the module was compiled with.
+func (m *Module) Origin() string {
+	return m.origin
+}
+
+// ScriptID returns the ID V8 assigned to the module's script. It returns 0 for a synthetic
+// module, such as a JSON module, which has no script, and for a module whose instantiation
+// or evaluation failed.
+func (m *Module) ScriptID() int {
+	return int(C.ModuleScriptId(m.ctx.ptr, m.ptr))
+}
+
+// Requests returns the specifiers of the modules that the module imports,
+// e.g. "./util.js" for `import {x} from "./util.js"`, in source order.
+func (m *Module) Requests() []string {
+	n := int(C.ModuleGetRequestCount(m.ctx.ptr, m.ptr))
+	specifiers := make([]string, n)
+	for i := range specifiers {
+		rtn := C.ModuleGetRequestSpecifier(m.ctx.ptr, m.ptr, C.int(i))
+		specifiers[i] = C.GoStringN(rtn.data, rtn.length)
+		C.free(unsafe.Pointer(rtn.data))
+	}
+	return specifiers
+}
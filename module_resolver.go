@@ -0,0 +1,104 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ResolveModuleCallback is called while a module is being instantiated, for each module
+// it imports. It returns the Module that the specifier, e.g. "./util.js", refers to when
+// imported by referrer; the Module must have been compiled in the same Context, and is
+// usually compiled by the callback itself. The attributes are the import's assertions,
+// e.g. {"type": "json"} for `import data from "./data.json" assert {type: "json"}`, or
+// nil; a callback that doesn't support an attribute should return an error. To parse
+// this syntax, v8go turns on V8's process-wide `--harmony-import-assertions` flag when
+// it is initialized; SetFlags("--no-harmony-import-assertions") turns it off again.
+// A ResolveModuleCallback that implements a module graph should return the same Module
+// every time the same module is imported.
+// If it returns an error, the error's message is thrown as a JavaScript Error, which
+// InstantiateModule returns.
+type ResolveModuleCallback func(ctx *Context, specifier string, referrer *Module, attributes map[string]string) (*Module, error)
+
+// SetResolveModuleCallback sets the callback that resolves the modules imported by the
+// modules instantiated in this Context. Passing nil removes the callback, so only
+// modules without imports can be instantiated.
+func (c *Context) SetResolveModuleCallback(cb ResolveModuleCallback) {
+	c.resolveModule = cb
+}
+
+// DynamicImportCallback is called when JavaScript calls `import(specifier)`, in a module
+// or a classic script. It returns the Module that the specifier refers to when imported
+// by the script or module whose origin is referrer, with the given import assertions,
+// like a ResolveModuleCallback.
+// The Module is then instantiated and evaluated, and the `import()` promise resolves to
+// its namespace. If the callback returns an error, the promise is rejected with a
+// JavaScript Error with the error's message.
+type DynamicImportCallback func(ctx *Context, specifier string, referrer string, attributes map[string]string) (*Module, error)
+
+// SetDynamicImportCallback sets the callback that resolves the modules that JavaScript
+// in this Context imports with `import()`. Passing nil removes the callback, so `import()`
+// always fails.
+func (c *Context) SetDynamicImportCallback(cb DynamicImportCallback) {
+	c.dynamicImport = cb
+}
+
+//export goResolveModule
+func goResolveModule(ctxHandle C.uintptr_t, specifier *C.char, specifierLen C.int, referrer C.ModulePtr,
+	attrCount C.int, attrs **C.char, attrLens *C.int) C.RtnModule {
+	ctx := contextFromHandle(ctxHandle)
+	spec := C.GoStringN(specifier, specifierLen)
+
+	if ctx.resolveModule == nil {
+		return moduleResult(ctx, spec, nil, nil)
+	}
+	m, err := ctx.resolveModule(ctx, spec, ctx.modules[referrer], importAttributes(attrCount, attrs, attrLens))
+	return moduleResult(ctx, spec, m, err)
+}
+
+//export goImportModuleDynamically
+func goImportModuleDynamically(ctxHandle C.uintptr_t, specifier *C.char, specifierLen C.int, referrer *C.char, referrerLen C.int,
+	attrCount C.int, attrs **C.char, attrLens *C.int) C.RtnModule {
+	ctx := contextFromHandle(ctxHandle)
+	spec := C.GoStringN(specifier, specifierLen)
+	if ctx.dynamicImport == nil {
+		return moduleResult(ctx, spec, nil, nil)
+	}
+	m, err := ctx.dynamicImport(ctx, spec, C.GoStringN(referrer, referrerLen), importAttributes(attrCount, attrs, attrLens))
+	return moduleResult(ctx, spec, m, err)
+}
+
+// importAttributes converts import assertions, passed from C as alternating keys and values.
+func importAttributes(count C.int, strs **C.char, lens *C.int) map[string]string {
+	if count == 0 {
+		return nil
+	}
+	n := int(count) * 2
+	s := (*[1 << 20]*C.char)(unsafe.Pointer(strs))[:n:n]
+	l := (*[1 << 20]C.int)(unsafe.Pointer(lens))[:n:n]
+	attrs := make(map[string]string, count)
+	for i := 0; i < n; i += 2 {
+		attrs[C.GoStringN(s[i], l[i])] = C.GoStringN(s[i+1], l[i+1])
+	}
+	return attrs
+}
+
+// moduleResult checks the result of a module callback and converts it for returning to C.
+func moduleResult(ctx *Context, specifier string, m *Module, err error) C.RtnModule {
+	if err == nil {
+		if m == nil {
+			err = fmt.Errorf("Cannot find module '%s'", specifier)
+		} else if m.ctx != ctx {
+			err = fmt.Errorf("Module '%s' belongs to a different context", specifier)
+		}
+	}
+	if err != nil {
+		return C.RtnModule{error: C.RtnError{msg: C.CString(err.Error())}}
+	}
+	return C.RtnModule{ptr: m.ptr}
+}
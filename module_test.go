@@ -0,0 +1,474 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestContextCompileModule(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	mod, err := ctx.CompileModule("export const answer = 6 * 7; globalThis.answer = answer;", "answer.mjs")
+	fatalIf(t, err)
+	if s := mod.Status(); s != v8.ModuleUninstantiated {
+		t.Errorf("unexpected status %v", s)
+	}
+	if mod.ScriptID() <= 0 {
+		t.Errorf("unexpected script ID %d", mod.ScriptID())
+	}
+
+	fatalIf(t, ctx.InstantiateModule(mod))
+	if s := mod.Status(); s != v8.ModuleInstantiated {
+		t.Errorf("unexpected status %v", s)
+	}
+
+	val, err := ctx.EvaluateModule(mod)
+	fatalIf(t, err)
+	if !val.IsPromise() {
+		t.Errorf("expected a promise, got %v", val)
+	}
+	if s := mod.Status(); s != v8.ModuleEvaluated {
+		t.Errorf("unexpected status %v", s)
+	}
+
+	val, err = ctx.RunScript("answer", "check.js")
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("expected 42, got %v", val)
+	}
+}
+
+func TestContextCompileModule_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	if _, err := ctx.CompileModule("export 42", "bad.mjs"); err == nil {
+		t.Error("expected syntax error")
+	}
+
+	mod, err := ctx.CompileModule("import {x} from './x.js'; import './y.js';", "imports.mjs")
+	fatalIf(t, err)
+	if reqs := mod.Requests(); !reflect.DeepEqual(reqs, []string{"./x.js", "./y.js"}) {
+		t.Errorf("unexpected requests %q", reqs)
+	}
+	err = ctx.InstantiateModule(mod)
+	if err == nil || err.Error() != "Error: Cannot find module './x.js'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	mod, err = ctx.CompileModule("\nthrow new TypeError('oops')", "throws.mjs")
+	fatalIf(t, err)
+	fatalIf(t, ctx.InstantiateModule(mod))
+	_, err = ctx.EvaluateModule(mod)
+	if e, ok := err.(*v8.JSError); !ok || e.Message != "TypeError: oops" || e.Location != "throws.mjs:2:7" {
+		t.Errorf("unexpected error %#v", err)
+	}
+	if s := mod.Status(); s != v8.ModuleErrored {
+		t.Errorf("unexpected status %v", s)
+	}
+	if exc := mod.Exception(); exc.String() != "TypeError: oops" {
+		t.Errorf("unexpected exception %v", exc)
+	}
+}
+
+func TestContextSetResolveModuleCallback(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	sources := map[string]string{
+		"main.mjs":   "import {double} from './lib.mjs'; import {base} from './base.mjs'; globalThis.result = double(base);",
+		"lib.mjs":    "import {base} from './base.mjs'; export function double(x) { return x * 2 + base - base; }",
+		"base.mjs":   "export const base = 21;",
+		"broken.mjs": "import './missing.mjs';",
+	}
+	names := map[*v8.Module]string{}
+	modules := map[string]*v8.Module{}
+	load := func(name string) (*v8.Module, error) {
+		if m, ok := modules[name]; ok {
+			return m, nil
+		}
+		source, ok := sources[name]
+		if !ok {
+			return nil, fmt.Errorf("no such module %q", name)
+		}
+		m, err := ctx.CompileModule(source, name)
+		if err != nil {
+			return nil, err
+		}
+		modules[name] = m
+		names[m] = name
+		return m, nil
+	}
+	var referrers []string
+	ctx.SetResolveModuleCallback(func(ctx *v8.Context, specifier string, referrer *v8.Module, attributes map[string]string) (*v8.Module, error) {
+		referrers = append(referrers, names[referrer]+" -> "+specifier)
+		return load(strings.TrimPrefix(specifier, "./"))
+	})
+
+	main, err := load("main.mjs")
+	fatalIf(t, err)
+	fatalIf(t, ctx.InstantiateModule(main))
+	_, err = ctx.EvaluateModule(main)
+	fatalIf(t, err)
+
+	val, err := ctx.RunScript("result", "check.js")
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("expected 42, got %v", val)
+	}
+	if len(referrers) == 0 || referrers[0] != "main.mjs -> ./lib.mjs" {
+		t.Errorf("unexpected resolutions %q", referrers)
+	}
+
+	bad, err := load("broken.mjs")
+	fatalIf(t, err)
+	err = ctx.InstantiateModule(bad)
+	if err == nil || err.Error() != `Error: no such module "missing.mjs"` {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	other := v8.NewContext(ctx.Isolate())
+	defer other.Close()
+	foreign, err := other.CompileModule("export default 1", "foreign.mjs")
+	fatalIf(t, err)
+	ctx.SetResolveModuleCallback(func(*v8.Context, string, *v8.Module, map[string]string) (*v8.Module, error) {
+		return foreign, nil
+	})
+	mod, err := ctx.CompileModule("import x from 'foreign'", "importer.mjs")
+	fatalIf(t, err)
+	if err := ctx.InstantiateModule(mod); err == nil {
+		t.Error("expected error importing a module from another context")
+	}
+}
+
+func TestContextSetImportMetaCallback(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	var calls int
+	ctx.SetImportMetaCallback(func(ctx *v8.Context, module *v8.Module, meta *v8.Object) {
+		calls++
+		fatalIf(t, meta.Set("url", "file:///app/"+module.Origin()))
+	})
+
+	mod, err := ctx.CompileModule("globalThis.url = import.meta.url; globalThis.same = import.meta === import.meta;", "meta.mjs")
+	fatalIf(t, err)
+	if mod.Origin() != "meta.mjs" {
+		t.Errorf("unexpected origin %q", mod.Origin())
+	}
+	fatalIf(t, ctx.InstantiateModule(mod))
+	_, err = ctx.EvaluateModule(mod)
+	fatalIf(t, err)
+
+	val, err := ctx.RunScript("url + ' ' + same", "check.js")
+	fatalIf(t, err)
+	if val.String() != "file:///app/meta.mjs true" {
+		t.Errorf("unexpected import.meta: %v", val)
+	}
+	if calls != 1 {
+		t.Errorf("expected callback to be called once, got %d", calls)
+	}
+}
+
+func TestContextNewSyntheticModule(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	get := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		val, _ := v8.NewValue(iso, "value of "+info.Args()[0].String())
+		return val
+	})
+	version, err := v8.NewValue(iso, "7.0")
+	fatalIf(t, err)
+	couchbase, err := ctx.NewSyntheticModule("couchbase", map[string]v8.Valuer{
+		"get":     get.GetFunction(ctx),
+		"version": version,
+	})
+	fatalIf(t, err)
+	if couchbase.Origin() != "couchbase" {
+		t.Errorf("unexpected origin %q", couchbase.Origin())
+	}
+	if id := couchbase.ScriptID(); id != 0 {
+		t.Errorf("expected no script ID for a synthetic module, got %d", id)
+	}
+	ctx.SetResolveModuleCallback(func(ctx *v8.Context, specifier string, referrer *v8.Module, attributes map[string]string) (*v8.Module, error) {
+		return couchbase, nil
+	})
+
+	mod, err := ctx.CompileModule("import {get, version} from 'couchbase'; globalThis.result = get('doc') + ' ' + version;", "main.mjs")
+	fatalIf(t, err)
+	fatalIf(t, ctx.InstantiateModule(mod))
+	_, err = ctx.EvaluateModule(mod)
+	fatalIf(t, err)
+	val, err := ctx.EvaluateModule(couchbase)
+	fatalIf(t, err)
+	if !val.IsPromise() {
+		t.Errorf("expected a promise, got %v", val)
+	}
+	if s := couchbase.Status(); s != v8.ModuleEvaluated {
+		t.Errorf("unexpected status %v", s)
+	}
+
+	val, err = ctx.RunScript("result", "check.js")
+	fatalIf(t, err)
+	if val.String() != "value of doc 7.0" {
+		t.Errorf("unexpected result %q", val)
+	}
+
+	otherIso := v8.NewIsolate()
+	defer otherIso.Dispose()
+	other := v8.NewContext(otherIso)
+	defer other.Close()
+	if _, err := other.NewSyntheticModule("x", map[string]v8.Valuer{"version": version}); err == nil {
+		t.Error("expected error for value from another isolate")
+	}
+}
+
+func TestModuleGetModuleNamespace(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	mod, err := ctx.CompileModule("export default 'hello'; export const answer = 42; export function twice(x) { return 2 * x }", "exports.mjs")
+	fatalIf(t, err)
+	if recoverPanic(func() { mod.GetModuleNamespace() }) == nil {
+		t.Error("expected panic before instantiation")
+	}
+	fatalIf(t, ctx.InstantiateModule(mod))
+	_, err = ctx.EvaluateModule(mod)
+	fatalIf(t, err)
+
+	ns := mod.GetModuleNamespace()
+	def, err := ns.Get("default")
+	fatalIf(t, err)
+	if def.String() != "hello" {
+		t.Errorf("unexpected default export %v", def)
+	}
+	answer, err := ns.Get("answer")
+	fatalIf(t, err)
+	if answer.Int32() != 42 {
+		t.Errorf("unexpected answer export %v", answer)
+	}
+	twiceVal, err := ns.Get("twice")
+	fatalIf(t, err)
+	twice, err := twiceVal.AsFunction()
+	fatalIf(t, err)
+	arg, _ := v8.NewValue(ctx.Isolate(), int32(21))
+	val, err := twice.Call(v8.Undefined(ctx.Isolate()), arg)
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("unexpected result %v", val)
+	}
+}
+
+func TestContextSetDynamicImportCallback(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	var referrers []string
+	ctx.SetDynamicImportCallback(func(ctx *v8.Context, specifier, referrer string, attributes map[string]string) (*v8.Module, error) {
+		referrers = append(referrers, referrer)
+		switch specifier {
+		case "answer":
+			return ctx.CompileModule("await null; export default 42;", "answer.mjs")
+		case "throws":
+			return ctx.CompileModule("throw new RangeError('bad')", "throws.mjs")
+		}
+		return nil, fmt.Errorf("no module %q", specifier)
+	})
+
+	val, err := ctx.RunScript(`
+		var results = [];
+		import('answer').then(ns => results.push(ns.default));
+		import('missing').catch(e => results.push(e.message));
+		import('throws').catch(e => results.push(e.name));`, "main.js")
+	fatalIf(t, err)
+	if !val.IsPromise() {
+		t.Errorf("expected a promise, got %v", val)
+	}
+	ctx.PerformMicrotaskCheckpoint()
+
+	val, err = ctx.RunScript("results.sort().join()", "check.js")
+	fatalIf(t, err)
+	if val.String() != `42,RangeError,no module "missing"` {
+		t.Errorf("unexpected results %q", val)
+	}
+	if !reflect.DeepEqual(referrers, []string{"main.js", "main.js", "main.js"}) {
+		t.Errorf("unexpected referrers %q", referrers)
+	}
+}
+
+func TestContextNewJSONModule(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	var attrs []map[string]string
+	resolve := func(ctx *v8.Context, specifier string, attributes map[string]string) (*v8.Module, error) {
+		attrs = append(attrs, attributes)
+		if attributes["type"] != "json" {
+			return nil, fmt.Errorf("'%s' must be imported as JSON", specifier)
+		}
+		return ctx.NewJSONModule(specifier, []byte(`{"name": "v8go", "tags": ["js", "go"]}`))
+	}
+	ctx.SetResolveModuleCallback(func(ctx *v8.Context, specifier string, referrer *v8.Module, attributes map[string]string) (*v8.Module, error) {
+		return resolve(ctx, specifier, attributes)
+	})
+	ctx.SetDynamicImportCallback(func(ctx *v8.Context, specifier, referrer string, attributes map[string]string) (*v8.Module, error) {
+		return resolve(ctx, specifier, attributes)
+	})
+
+	mod, err := ctx.CompileModule(`import config from "./config.json" assert {type: "json"};
+		globalThis.result = config.name + " " + config.tags.join("+");
+		globalThis.dynamic = import("./other.json", {assert: {type: "json"}});`, "main.mjs")
+	fatalIf(t, err)
+	fatalIf(t, ctx.InstantiateModule(mod))
+	_, err = ctx.EvaluateModule(mod)
+	fatalIf(t, err)
+	ctx.PerformMicrotaskCheckpoint()
+
+	val, err := ctx.RunScript("result", "check.js")
+	fatalIf(t, err)
+	if val.String() != "v8go js+go" {
+		t.Errorf("unexpected result %q", val)
+	}
+	expected := []map[string]string{{"type": "json"}, {"type": "json"}}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+
+	mod, err = ctx.CompileModule(`import config from "./config.json";`, "plain.mjs")
+	fatalIf(t, err)
+	if err := ctx.InstantiateModule(mod); err == nil || err.Error() != "Error: './config.json' must be imported as JSON" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := ctx.NewJSONModule("bad.json", []byte("{")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestContextAwaitModule(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	await := func(source string) (*v8.Module, error) {
+		mod, err := ctx.CompileModule(source, "tla.mjs")
+		fatalIf(t, err)
+		fatalIf(t, ctx.InstantiateModule(mod))
+		return mod, ctx.AwaitModule(mod)
+	}
+
+	mod, err := await(`export const answer = await Promise.resolve(42);`)
+	fatalIf(t, err)
+	if mod.Status() != v8.ModuleEvaluated {
+		t.Errorf("expected the module to be evaluated, got status %v", mod.Status())
+	}
+	answer, err := mod.GetModuleNamespace().Get("answer")
+	fatalIf(t, err)
+	if answer.Integer() != 42 {
+		t.Errorf("expected 42, got %v", answer)
+	}
+
+	mod, err = await(`await null; throw new RangeError("too late");`)
+	var jsErr *v8.JSError
+	if !errors.As(err, &jsErr) || jsErr.Message != "RangeError: too late" {
+		t.Errorf("expected a JSError, got %v", err)
+	}
+	if mod.Status() != v8.ModuleErrored {
+		t.Errorf("expected the module to be errored, got status %v", mod.Status())
+	}
+	if id := mod.ScriptID(); id != 0 {
+		t.Errorf("expected no script ID for an errored module, got %d", id)
+	}
+
+	if _, err := await(`await new Promise(() => {});`); err != v8.ErrModulePending {
+		t.Errorf("expected ErrModulePending, got %v", err)
+	}
+}
+
+func TestModuleCreateCodeCache(t *testing.T) {
+	t.Parallel()
+	source := "export function greet(name) { return 'hello ' + name; }"
+
+	ctx1 := v8.NewContext()
+	defer ctx1.Isolate().Dispose()
+	defer ctx1.Close()
+	mod, err := ctx1.CompileModule(source, "greet.mjs")
+	fatalIf(t, err)
+	cache, err := mod.CreateCodeCache()
+	fatalIf(t, err)
+	if len(cache.Bytes) == 0 {
+		t.Fatal("expected a code cache")
+	}
+	fatalIf(t, ctx1.InstantiateModule(mod))
+	_, err = ctx1.EvaluateModule(mod)
+	fatalIf(t, err)
+	if _, err := mod.CreateCodeCache(); err == nil {
+		t.Error("expected error creating a code cache for an evaluated module")
+	}
+
+	ctx2 := v8.NewContext()
+	defer ctx2.Isolate().Dispose()
+	defer ctx2.Close()
+	opts := v8.CompileOptions{CachedData: cache}
+	mod2, err := ctx2.CompileModuleWithOptions(source, "greet.mjs", opts)
+	fatalIf(t, err)
+	if cache.Rejected {
+		t.Error("expected code cache to be accepted")
+	}
+	fatalIf(t, ctx2.InstantiateModule(mod2))
+	_, err = ctx2.EvaluateModule(mod2)
+	fatalIf(t, err)
+	greet, err := mod2.GetModuleNamespace().Get("greet")
+	fatalIf(t, err)
+	fn, err := greet.AsFunction()
+	fatalIf(t, err)
+	arg, _ := v8.NewValue(ctx2.Isolate(), "cache")
+	val, err := fn.Call(v8.Undefined(ctx2.Isolate()), arg)
+	fatalIf(t, err)
+	if val.String() != "hello cache" {
+		t.Errorf("unexpected result %q", val)
+	}
+
+	opts = v8.CompileOptions{CachedData: &v8.CompilerCachedData{Bytes: []byte("garbage")}}
+	_, err = ctx2.CompileModuleWithOptions(source, "other.mjs", opts)
+	fatalIf(t, err)
+	if !opts.CachedData.Rejected {
+		t.Error("expected invalid code cache to be rejected")
+	}
+
+	syn, err := ctx2.NewSyntheticModule("synthetic", nil)
+	fatalIf(t, err)
+	if _, err := syn.CreateCodeCache(); err == nil {
+		t.Error("expected error creating a code cache for a synthetic module")
+	}
+}
@@ -41,6 +41,91 @@ void ObjectSetIdx(ValuePtr ptr, uint32_t idx, ValuePtr prop_val) {
 }
 
 
+RtnError ObjectDefineProperty(ValuePtr ptr, ValuePtr key_val, ValuePtr value,
+                              ValuePtr getter, ValuePtr setter, int flags) {
+  WithObject _with(ptr);
+  Isolate* iso = _with.iso();
+  Local<Value> key = Deref(key_val);
+  if (!key->IsName()) {
+    iso->ThrowException(Exception::TypeError(
+        String::NewFromUtf8Literal(iso, "Property key must be a string or symbol")));
+    return _with.exceptionError();
+  }
+  Local<Value> undefined = Undefined(iso);
+  bool accessor = getter.ctx != nullptr || setter.ctx != nullptr;
+  std::unique_ptr<PropertyDescriptor> desc;
+  if (accessor) {
+    desc.reset(new PropertyDescriptor(getter.ctx ? Deref(getter) : undefined,
+                                      setter.ctx ? Deref(setter) : undefined));
+  } else {
+    desc.reset(new PropertyDescriptor(value.ctx ? Deref(value) : undefined,
+                                      (flags & PropertyDescriptorWritable) != 0));
+  }
+  desc->set_enumerable((flags & PropertyDescriptorEnumerable) != 0);
+  desc->set_configurable((flags & PropertyDescriptorConfigurable) != 0);
+
+  Maybe<bool> ok = _with.obj->DefineProperty(_with.local_ctx, key.As<Name>(), *desc);
+  if (ok.IsJust() && !ok.FromJust()) {
+    // V8 reports a failure without throwing; throw the TypeError JS would.
+    Local<String> msg = String::NewFromUtf8Literal(iso, "Cannot redefine property");
+    if (key->IsString()) {
+      msg = String::Concat(iso, String::NewFromUtf8Literal(iso, "Cannot redefine property: "),
+                           key.As<String>());
+    }
+    iso->ThrowException(Exception::TypeError(msg));
+  } else if (ok.IsJust()) {
+    return RtnError{};
+  }
+  return _with.exceptionError();
+}
+
+// The getter and setter of every property defined by ObjectSetAccessor call Go through
+// goAccessorCallback, with the cgo.Handle of the property's Go callbacks, which is stored
+// in the accessor's data.
+template <typename T>
+static CallbackResult callAccessor(const PropertyCallbackInfo<T>& info, Local<Value> value) {
+  Isolate* iso = info.GetIsolate();
+  V8GoContext* ctx = V8GoContext::fromContext(iso->GetCurrentContext());
+  uintptr_t handle = reinterpret_cast<uintptr_t>(info.Data().template As<External>()->Value());
+  ValueRef _this = ctx->addValue(info.This());
+  ValueRef holder = info.Holder() == info.This() ? _this : ctx->addValue(info.Holder());
+  ValueRef val = value.IsEmpty() ? ValueRef{} : ctx->addValue(value);
+  return goAccessorCallback(ctx->goRef, handle, _this, holder, val, !value.IsEmpty());
+}
+
+static void accessorGetter(Local<Name> property, const PropertyCallbackInfo<Value>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  SetCallbackResult(info.GetReturnValue(), callAccessor(info, Local<Value>()));
+}
+
+static void accessorSetter(Local<Name> property, Local<Value> value,
+                           const PropertyCallbackInfo<void>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  callAccessor(info, value);
+}
+
+RtnValue ObjectSetAccessor(ValuePtr ptr, const char* name, int nameLen, uintptr_t handle,
+                           Bool getter, Bool setter, int attributes) {
+  WithObject _with(ptr);
+  Local<String> prop_name = _with.makeString(name, NewStringType::kInternalized, nameLen);
+  // Go frees the handle when the External is garbage collected.
+  Local<External> data = External::New(_with.iso(), reinterpret_cast<void*>(handle));
+  Maybe<bool> ok = _with.obj->SetAccessor(_with.local_ctx, prop_name,
+                                          getter ? accessorGetter : nullptr,
+                                          setter ? accessorSetter : nullptr,
+                                          data, DEFAULT, (PropertyAttribute)attributes);
+  MaybeLocal<External> result;
+  if (ok.IsJust() && !ok.FromJust()) {
+    // V8 reports a failure without throwing; throw the TypeError JS would.
+    Isolate* iso = _with.iso();
+    iso->ThrowException(Exception::TypeError(String::Concat(
+        iso, String::NewFromUtf8Literal(iso, "Cannot redefine property: "), prop_name)));
+  } else if (ok.IsJust()) {
+    result = data;
+  }
+  return _with.returnValue(result);
+}
+
 RtnValue ObjectGet(ValuePtr ptr, const char* key, int keyLen) {
   WithObject _with(ptr);
   Local<String> key_val = _with.makeString(key, NewStringType::kInternalized, keyLen);
@@ -75,6 +160,53 @@ int ObjectHasIdx(ValuePtr ptr, uint32_t idx) {
 }
 
 
+// Own-property variants of Has, Get and Delete, which ignore the prototype chain and
+// report exceptions, e.g. from Proxy traps.
+
+RtnError ObjectHasOwn(ValuePtr ptr, const char* key, int keyLen, Bool* result) {
+  WithObject _with(ptr);
+  Local<String> key_val = _with.makeString(key, NewStringType::kInternalized, keyLen);
+  Maybe<bool> has = _with.obj->HasOwnProperty(_with.local_ctx, key_val);
+  if (has.IsNothing()) {
+    return _with.exceptionError();
+  }
+  *result = has.FromJust();
+  return RtnError{};
+}
+
+RtnValue ObjectGetOwn(ValuePtr ptr, const char* key, int keyLen, Bool* found) {
+  WithObject _with(ptr);
+  Local<String> key_val = _with.makeString(key, NewStringType::kInternalized, keyLen);
+  Maybe<bool> has = _with.obj->HasOwnProperty(_with.local_ctx, key_val);
+  *found = has.FromMaybe(false);
+  if (has.IsNothing()) {
+    RtnValue rtn = {};
+    rtn.error = _with.exceptionError();
+    return rtn;
+  } else if (!*found) {
+    return _with.returnValue(MaybeLocal<Value>(Undefined(_with.iso())));
+  }
+  return _with.returnValue(_with.obj->Get(_with.local_ctx, key_val));
+}
+
+RtnError ObjectDeleteOwn(ValuePtr ptr, const char* key, int keyLen, Bool* deleted) {
+  WithObject _with(ptr);
+  Local<String> key_val = _with.makeString(key, NewStringType::kInternalized, keyLen);
+  *deleted = false;
+  Maybe<bool> has = _with.obj->HasOwnProperty(_with.local_ctx, key_val);
+  if (has.IsNothing()) {
+    return _with.exceptionError();
+  } else if (!has.FromJust()) {
+    return RtnError{};
+  }
+  Maybe<bool> ok = _with.obj->Delete(_with.local_ctx, key_val);
+  if (ok.IsNothing()) {
+    return _with.exceptionError();
+  }
+  *deleted = ok.FromJust();
+  return RtnError{};
+}
+
 int ObjectDelete(ValuePtr ptr, const char* key, int keyLen) {
   WithObject _with(ptr);
   Local<String> key_val = _with.makeString(key, NewStringType::kInternalized, keyLen);
@@ -92,8 +224,188 @@ int ObjectDeleteIdx(ValuePtr ptr, uint32_t idx) {
 }
 
 
+// Returns an array of the object's own enumerable string-keyed property names.
+RtnValue ObjectGetOwnPropertyNames(ValuePtr ptr) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->GetOwnPropertyNames(_with.local_ctx));
+}
+
+// Reads the properties named by `names[start..end)`, skipping any that are no longer own
+// enumerable properties, as `Object.entries` does. The values go in `values` and the keys,
+// UTF-8 encoded, are concatenated into a malloc'ed `*keys` with their lengths in
+// `key_lengths`. `*count` is set to the number of properties read.
+RtnError ObjectGetProperties(ValuePtr ptr, ValuePtr names_ptr, uint32_t start, uint32_t end,
+                             ValueRef* values, char** keys, int* key_lengths, uint32_t* count) {
+  WithObject _with(ptr);
+  Isolate* iso = _with.iso();
+  Local<Array> names = Deref(names_ptr).As<Array>();
+  std::string buffer;
+  uint32_t n = 0;
+  *keys = nullptr;
+  *count = 0;
+  for (uint32_t i = start; i < end; i++) {
+    Local<Value> name, val;
+    Local<String> key;
+    if (!names->Get(_with.local_ctx, i).ToLocal(&name) ||
+        !name->ToString(_with.local_ctx).ToLocal(&key)) {  // (indices are numbers)
+      return _with.exceptionError();
+    }
+    Maybe<bool> own = _with.obj->HasOwnProperty(_with.local_ctx, key);
+    if (own.IsNothing()) {
+      return _with.exceptionError();
+    } else if (!own.FromJust()) {
+      continue;
+    }
+    Maybe<PropertyAttribute> attrs = _with.obj->GetPropertyAttributes(_with.local_ctx, key);
+    if (attrs.IsNothing()) {
+      return _with.exceptionError();
+    } else if (attrs.FromJust() & DontEnum) {
+      continue;
+    }
+    if (!_with.obj->Get(_with.local_ctx, key).ToLocal(&val)) {
+      return _with.exceptionError();
+    }
+    String::Utf8Value utf8(iso, key);
+    buffer.append(*utf8, utf8.length());
+    key_lengths[n] = utf8.length();
+    values[n] = ptr.ctx->addValue(val);
+    n++;
+  }
+  *keys = (char*)malloc(buffer.size() + 1);
+  memcpy(*keys, buffer.data(), buffer.size());
+  *count = n;
+  return RtnError{};
+}
+
+
 /********** Object Internal Fields **********/
 
+ValueRef ObjectGetPrototype(ValuePtr ptr) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->GetPrototype());
+}
+
+RtnError ObjectSetPrototype(ValuePtr ptr, ValuePtr proto_ptr) {
+  WithObject _with(ptr);
+  Isolate* iso = _with.iso();
+  Local<Value> proto = Deref(proto_ptr);
+  if (!proto->IsObject() && !proto->IsNull()) {
+    iso->ThrowException(Exception::TypeError(
+        String::NewFromUtf8Literal(iso, "Object prototype may only be an Object or null")));
+    return _with.exceptionError();
+  }
+  if (_with.obj->SetPrototype(_with.local_ctx, proto).FromMaybe(false)) {
+    return RtnError{};
+  }
+  if (!_with.try_catch.HasCaught()) {
+    // V8 clears the exception for ordinary objects; throw the TypeError JS would.
+    iso->ThrowException(Exception::TypeError(
+        String::NewFromUtf8Literal(iso, "Cannot set the prototype of this object")));
+  }
+  return _with.exceptionError();
+}
+
+// Returns a shallow copy of the object, with the same prototype and own properties.
+ValueRef ObjectClone(ValuePtr ptr) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->Clone());
+}
+
+// Returns the Go handle of the Context the object was created in, or 0 if that context
+// has been closed or isn't one the Go side knows about.
+uintptr_t ObjectGetCreationContext(ValuePtr ptr) {
+  WithObject _with(ptr);
+  Local<Context> creation;
+  if (!_with.obj->GetCreationContext().ToLocal(&creation)) {
+    return 0;
+  }
+  V8GoContext* ctx = V8GoContext::fromContext(creation);
+  return ctx ? ctx->goRef : 0;
+}
+
+RtnError ObjectSetIntegrityLevel(ValuePtr ptr, int level) {
+  WithObject _with(ptr);
+  if (_with.obj->SetIntegrityLevel(_with.local_ctx, IntegrityLevel(level)).FromMaybe(false)) {
+    return RtnError{};
+  }
+  if (!_with.try_catch.HasCaught()) {
+    Isolate* iso = _with.iso();
+    iso->ThrowException(Exception::TypeError(
+        String::NewFromUtf8Literal(iso, "Cannot change the integrity level of this object")));
+  }
+  return _with.exceptionError();
+}
+
+// Freezes obj and, recursively, the objects reachable through its own properties, including
+// accessors' functions but not prototypes. `visited` guards against cycles.
+static Maybe<bool> deepFreeze(Local<Context> ctx, Local<Object> obj, Local<Set> visited) {
+  Isolate* iso = ctx->GetIsolate();
+  if (visited->Has(ctx, obj).FromMaybe(true)) {
+    return Just(true);
+  }
+  if (visited->Add(ctx, obj).IsEmpty() ||
+      !obj->SetIntegrityLevel(ctx, IntegrityLevel::kFrozen).FromMaybe(false)) {
+    return Nothing<bool>();
+  }
+  Local<Array> keys;
+  if (!obj->GetOwnPropertyNames(ctx, ALL_PROPERTIES, KeyConversionMode::kConvertToString)
+           .ToLocal(&keys)) {
+    return Nothing<bool>();
+  }
+  Local<String> fields[] = {String::NewFromUtf8Literal(iso, "value"),
+                            String::NewFromUtf8Literal(iso, "get"),
+                            String::NewFromUtf8Literal(iso, "set")};
+  for (uint32_t i = 0; i < keys->Length(); i++) {
+    Local<Value> key, desc;
+    if (!keys->Get(ctx, i).ToLocal(&key) ||
+        !obj->GetOwnPropertyDescriptor(ctx, key.As<Name>()).ToLocal(&desc)) {
+      return Nothing<bool>();
+    }
+    if (!desc->IsObject()) {
+      continue;
+    }
+    for (auto& field : fields) {
+      Local<Value> val;
+      if (!desc.As<Object>()->Get(ctx, field).ToLocal(&val)) {
+        return Nothing<bool>();
+      }
+      if (val->IsObject() && deepFreeze(ctx, val.As<Object>(), visited).IsNothing()) {
+        return Nothing<bool>();
+      }
+    }
+  }
+  return Just(true);
+}
+
+RtnError ObjectDeepFreeze(ValuePtr ptr) {
+  WithObject _with(ptr);
+  if (deepFreeze(_with.local_ctx, _with.obj, Set::New(_with.iso())).IsJust()) {
+    return RtnError{};
+  }
+  if (!_with.try_catch.HasCaught()) {
+    Isolate* iso = _with.iso();
+    iso->ThrowException(Exception::TypeError(
+        String::NewFromUtf8Literal(iso, "Cannot freeze this object")));
+  }
+  return _with.exceptionError();
+}
+
+RtnError ObjectCallBuiltin(ValuePtr ptr, ObjectBuiltin fn, Bool* result) {
+  static const char* const kNames[] = {"isFrozen", "isSealed", "isExtensible", "preventExtensions"};
+  WithObject _with(ptr);
+  // Use the functions of the isolate's internal context, which scripts can't tamper with.
+  Local<Context> internal = static_cast<V8GoContext*>(_with.iso()->GetData(0))->context();
+  Local<Value> object, func, rtn;
+  Local<Value> args[] = {_with.obj};
+  if (internal->Global()->Get(internal, _with.makeString("Object")).ToLocal(&object) &&
+      object.As<Object>()->Get(internal, _with.makeString(kNames[fn])).ToLocal(&func) &&
+      func.As<Function>()->Call(internal, object, 1, args).ToLocal(&rtn)) {
+    *result = rtn->BooleanValue(_with.iso());
+    return RtnError{};
+  }
+  return _with.exceptionError();
+}
+
 int ObjectSetInternalField(ValuePtr ptr, int idx, ValuePtr val_ptr) {
   WithObject _with(ptr);
 
@@ -118,11 +430,43 @@ ValuePtr ObjectGetInternalField(ValuePtr ptr, int idx) {
   return {ptr.ctx, ptr.ctx->addValue(result)};
 }
 
+int ObjectSetInternalFieldHandle(ValuePtr ptr, int idx, uintptr_t handle) {
+  WithObject _with(ptr);
+
+  if (idx >= _with.obj->InternalFieldCount()) {
+    return 0;
+  }
+
+  // The handle is stored as an External, which only Go values are stored in.
+  _with.obj->SetInternalField(idx, External::New(_with.iso(), reinterpret_cast<void*>(handle)));
+
+  return 1;
+}
+
+uintptr_t ObjectGetInternalFieldHandle(ValuePtr ptr, int idx) {
+  WithObject _with(ptr);
+
+  if (idx >= _with.obj->InternalFieldCount()) {
+    return 0;
+  }
+
+  Local<Value> field = _with.obj->GetInternalField(idx);
+  if (!field->IsExternal()) {
+    return 0;
+  }
+  return reinterpret_cast<uintptr_t>(field.As<External>()->Value());
+}
+
 int ObjectInternalFieldCount(ValuePtr ptr) {
   WithObject _with(ptr);
   return _with.obj->InternalFieldCount();
 }
 
+int ObjectGetIdentityHash(ValuePtr ptr) {
+  WithObject _with(ptr);
+  return _with.obj->GetIdentityHash();
+}
+
 
 /********** Promise **********/
 
@@ -205,6 +549,46 @@ RtnValue PromiseCatch(ValuePtr ptr, int callback_ref) {
   return _with.returnValue(promise->Catch(_with.local_ctx, func));
 }
 
+// The reactions of a promise passed to PromiseFinally call the Go callback, which is their
+// data, then pass the promise's value or reason through, unless the callback threw.
+static void finallyReaction(const FunctionCallbackInfo<Value>& info, bool rejected) {
+  Isolate* iso = info.GetIsolate();
+  Local<Function> callback = info.Data().As<Function>();
+  if (callback->Call(iso->GetCurrentContext(), Undefined(iso), 0, nullptr).IsEmpty()) {
+    return;
+  }
+  if (rejected) {
+    iso->ThrowException(info[0]);
+  } else {
+    info.GetReturnValue().Set(info[0]);
+  }
+}
+
+static void finallyFulfilled(const FunctionCallbackInfo<Value>& info) {
+  finallyReaction(info, false);
+}
+
+static void finallyRejected(const FunctionCallbackInfo<Value>& info) {
+  finallyReaction(info, true);
+}
+
+RtnValue PromiseFinally(ValuePtr ptr, int callback_ref) {
+  WithValue _with(ptr);
+  RtnValue rtn = {};
+  Local<Promise> promise = _with.value.As<Promise>();
+  Local<Integer> cbData = Integer::New(_with.iso(), callback_ref);
+  Local<Function> func, onFulfilled, onRejected;
+  // There's no Promise::Finally in the V8 API, and `promise.finally` can be replaced by
+  // scripts, so use Then with reactions that pass the result through.
+  if (!Function::New(_with.local_ctx, FunctionTemplateCallback, cbData).ToLocal(&func) ||
+      !Function::New(_with.local_ctx, finallyFulfilled, func).ToLocal(&onFulfilled) ||
+      !Function::New(_with.local_ctx, finallyRejected, func).ToLocal(&onRejected)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  return _with.returnValue(promise->Then(_with.local_ctx, onFulfilled, onRejected));
+}
+
 ValueRef PromiseResult(ValuePtr ptr) {
   WithValue _with(ptr);
   Local<Promise> promise = _with.value.As<Promise>();
@@ -214,21 +598,12 @@ ValueRef PromiseResult(ValuePtr ptr) {
 
 /********** Function **********/
 
-static void buildCallArguments(Isolate* iso,
-                               Local<Value>* argv,
-                               int argc,
-                               ValuePtr args[]) {
-  for (int i = 0; i < argc; i++) {
-    argv[i] = Deref(args[i]);
-  }
-}
-
 RtnValue FunctionCall(ValuePtr ptr, ValuePtr recv, int argc, ValuePtr args[]) {
   WithValue _with(ptr);
 
   RtnValue rtn = {};
   Local<Function> fn = Local<Function>::Cast(_with.value);
-  Local<Value> argv[argc];
+  Local<Value> argv[argc + 1];  // (+1 because zero-length arrays aren't allowed)
   buildCallArguments(_with.iso(), argv, argc, args);
 
   Local<Value> local_recv = Deref(recv);
@@ -242,11 +617,28 @@ RtnValue FunctionCall(ValuePtr ptr, ValuePtr recv, int argc, ValuePtr args[]) {
   return rtn;
 }
 
+void FunctionCallBatch(ValuePtr ptr, ValuePtr recv, int count, const int* argCounts,
+                       ValuePtr args[], RtnValue* results) {
+  WithValue _with(ptr);
+  Local<Function> fn = Local<Function>::Cast(_with.value);
+  Local<Value> local_recv = Deref(recv);
+  // The argument sets are passed concatenated, along with their lengths.
+  for (int i = 0; i < count; i++) {
+    HandleScope handle_scope(_with.iso());
+    int argc = argCounts[i];
+    Local<Value> argv[argc + 1];  // (+1 because zero-length arrays aren't allowed)
+    buildCallArguments(_with.iso(), argv, argc, args);
+    args += argc;
+    results[i] = _with.returnValue(fn->Call(_with.local_ctx, local_recv, argc, argv));
+    _with.try_catch.Reset();
+  }
+}
+
 RtnValue FunctionNewInstance(ValuePtr ptr, int argc, ValuePtr args[]) {
   WithValue _with(ptr);
   RtnValue rtn = {};
   Local<Function> fn = Local<Function>::Cast(_with.value);
-  Local<Value> argv[argc];
+  Local<Value> argv[argc + 1];  // (+1 because zero-length arrays aren't allowed)
   buildCallArguments(_with.iso(), argv, argc, args);
   Local<Object> result;
   if (!fn->NewInstance(_with.local_ctx, argc, argv).ToLocal(&result)) {
@@ -257,6 +649,36 @@ RtnValue FunctionNewInstance(ValuePtr ptr, int argc, ValuePtr args[]) {
   return rtn;
 }
 
+Bool FunctionIsConstructor(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Function>()->IsConstructor();
+}
+
+// Returns the function's name or, if it has none, the name V8 inferred for it.
+RtnString FunctionName(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return CopyString(_with.iso(), _with.value.As<Function>()->GetDebugName());
+}
+
+int FunctionScriptID(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Function>()->ScriptId();
+}
+
+// Returns the name of the script defining the function, and the 0-based position of the
+// function in it, or -1 if unknown.
+RtnString FunctionLocation(ValuePtr ptr, int* line, int* column) {
+  WithValue _with(ptr);
+  Local<Function> fn = _with.value.As<Function>();
+  *line = fn->GetScriptLineNumber();
+  *column = fn->GetScriptColumnNumber();
+  Local<Value> name = fn->GetScriptOrigin().ResourceName();
+  if (name.IsEmpty() || !name->IsString()) {
+    return RtnString{};
+  }
+  return CopyString(_with.iso(), name.As<String>());
+}
+
 ValueRef FunctionSourceMapUrl(ValuePtr ptr) {
   WithValue _with(ptr);
   Local<Function> fn = Local<Function>::Cast(_with.value);
@@ -279,3 +701,464 @@ uint32_t ArrayLength(ValuePtr ptr) {
     return 0;
   }
 }
+
+// Stores the array's elements from `start` up to `end` in `values`, which must have room
+// for `end - start` refs, so that they can be fetched with a single call from Go.
+RtnError ArrayGetValues(ValuePtr ptr, uint32_t start, uint32_t end, ValueRef* values) {
+  WithObject _with(ptr);
+  RtnError rtn = {};
+  for (uint32_t i = start; i < end; i++) {
+    Local<Value> val;
+    if (!_with.obj->Get(_with.local_ctx, i).ToLocal(&val)) {
+      rtn = _with.exceptionError();
+      return rtn;
+    }
+    values[i - start] = ptr.ctx->addValue(val);
+  }
+  return rtn;
+}
+
+// Appends the values to the end of the array, as `Array.prototype.push` does.
+RtnError ArrayPush(ValuePtr ptr, int count, ValuePtr* values) {
+  WithObject _with(ptr);
+  RtnError rtn = {};
+  uint32_t length = _with.obj.As<Array>()->Length();
+  for (int i = 0; i < count; i++) {
+    Maybe<bool> ok = _with.obj->CreateDataProperty(_with.local_ctx, length + i, Deref(values[i]));
+    if (ok.IsJust() && !ok.FromJust()) {
+      // Like `push`, fail rather than ignore elements that can't be set.
+      _with.iso()->ThrowException(Exception::TypeError(
+          String::NewFromUtf8Literal(_with.iso(), "Cannot add elements to the array")));
+    }
+    if (!ok.FromMaybe(false)) {
+      rtn = _with.exceptionError();
+      return rtn;
+    }
+  }
+  return rtn;
+}
+
+
+/********** Map **********/
+
+ValueRef NewMap(ContextPtr ctx) {
+  WithContext _with(ctx);
+  return _with.returnValue(Map::New(_with.iso()));
+}
+
+uint32_t MapSize(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return uint32_t(_with.value.As<Map>()->Size());
+}
+
+RtnValue MapGet(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Map>()->Get(_with.local_ctx, Deref(key)));
+}
+
+RtnValue MapSet(ValuePtr ptr, ValuePtr key, ValuePtr val) {
+  WithValue _with(ptr);
+  MaybeLocal<Map> result = _with.value.As<Map>()->Set(_with.local_ctx, Deref(key), Deref(val));
+  return _with.returnValue(result);
+}
+
+int MapHas(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.value.As<Map>()->Has(_with.local_ctx, Deref(key)).ToChecked();
+}
+
+int MapDelete(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.value.As<Map>()->Delete(_with.local_ctx, Deref(key)).ToChecked();
+}
+
+void MapClear(ValuePtr ptr) {
+  WithValue _with(ptr);
+  _with.value.As<Map>()->Clear();
+}
+
+// Returns the entries of the map as an array of alternating keys and values.
+ValueRef MapAsArray(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Map>()->AsArray());
+}
+
+
+/********** Set **********/
+
+ValueRef NewSet(ContextPtr ctx) {
+  WithContext _with(ctx);
+  return _with.returnValue(Set::New(_with.iso()));
+}
+
+uint32_t SetSize(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return uint32_t(_with.value.As<Set>()->Size());
+}
+
+RtnValue SetAdd(ValuePtr ptr, ValuePtr val) {
+  WithValue _with(ptr);
+  MaybeLocal<Set> result = _with.value.As<Set>()->Add(_with.local_ctx, Deref(val));
+  return _with.returnValue(result);
+}
+
+int SetHas(ValuePtr ptr, ValuePtr val) {
+  WithValue _with(ptr);
+  return _with.value.As<Set>()->Has(_with.local_ctx, Deref(val)).ToChecked();
+}
+
+int SetDelete(ValuePtr ptr, ValuePtr val) {
+  WithValue _with(ptr);
+  return _with.value.As<Set>()->Delete(_with.local_ctx, Deref(val)).ToChecked();
+}
+
+void SetClear(ValuePtr ptr) {
+  WithValue _with(ptr);
+  _with.value.As<Set>()->Clear();
+}
+
+ValueRef SetAsArray(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Set>()->AsArray());
+}
+
+
+/********** RegExp **********/
+
+RtnValue NewRegExp(ContextPtr ctx, const char* pattern, int patternLen,
+                   const char* flags, int flagsLen) {
+  WithContext _with(ctx);
+  int bits = RegExp::kNone;
+  for (int i = 0; i < flagsLen; i++) {
+    int flag;
+    switch (flags[i]) {
+      case 'd': flag = RegExp::kHasIndices; break;
+      case 'g': flag = RegExp::kGlobal; break;
+      case 'i': flag = RegExp::kIgnoreCase; break;
+      case 'm': flag = RegExp::kMultiline; break;
+      case 's': flag = RegExp::kDotAll; break;
+      case 'u': flag = RegExp::kUnicode; break;
+      case 'y': flag = RegExp::kSticky; break;
+      default:  flag = 0; break;
+    }
+    if (flag == 0 || (bits & flag)) {
+      _with.iso()->ThrowException(Exception::SyntaxError(_with.makeString(
+          ("Invalid regular expression flags '" + std::string(flags, flagsLen) + "'").c_str())));
+      RtnValue rtn = {};
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    bits |= flag;
+  }
+  Local<String> source = _with.makeString(pattern, NewStringType::kNormal, patternLen);
+  return _with.returnValue(RegExp::New(_with.local_ctx, source, RegExp::Flags(bits)));
+}
+
+RtnString RegExpSource(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return CopyString(_with.iso(), _with.value.As<RegExp>()->GetSource());
+}
+
+int RegExpFlags(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<RegExp>()->GetFlags();
+}
+
+// Returns the match array, or null if there's no match.
+RtnValue RegExpExec(ValuePtr ptr, const char* subject, int subjectLen) {
+  WithValue _with(ptr);
+  Local<String> str = _with.makeString(subject, NewStringType::kNormal, subjectLen);
+  return _with.returnValue(_with.value.As<RegExp>()->Exec(_with.local_ctx, str));
+}
+
+
+/********** Proxy **********/
+
+RtnValue NewProxy(ContextPtr ctx, ValuePtr target, ValuePtr handler) {
+  WithContext _with(ctx);
+  return _with.returnValue(Proxy::New(_with.local_ctx, Deref(target).As<Object>(),
+                                      Deref(handler).As<Object>()));
+}
+
+ValueRef ProxyGetTarget(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Proxy>()->GetTarget());
+}
+
+ValueRef ProxyGetHandler(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Proxy>()->GetHandler());
+}
+
+int ProxyIsRevoked(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Proxy>()->IsRevoked();
+}
+
+void ProxyRevoke(ValuePtr ptr) {
+  WithValue _with(ptr);
+  _with.value.As<Proxy>()->Revoke();
+}
+
+
+/********** Iterator **********/
+
+// Gets an iterator from an iterable value, as `value[Symbol.iterator]()` does.
+RtnValue ValueGetIterator(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<Context> local_ctx = _with.local_ctx;
+  Local<Value> method;
+  if (_with.value->IsNullOrUndefined()) {
+    _with.iso()->ThrowException(Exception::TypeError(
+        String::NewFromUtf8Literal(_with.iso(), "Value is not iterable")));
+  } else if (_with.value->ToObject(local_ctx).ToLocalChecked()
+                 ->Get(local_ctx, Symbol::GetIterator(_with.iso())).ToLocal(&method)) {
+    if (!method->IsFunction()) {
+      _with.iso()->ThrowException(Exception::TypeError(
+          String::NewFromUtf8Literal(_with.iso(), "Value is not iterable")));
+    } else {
+      Local<Value> iter;
+      if (method.As<Function>()->Call(local_ctx, _with.value, 0, nullptr).ToLocal(&iter)) {
+        if (iter->IsObject()) {
+          return _with.returnValue(MaybeLocal<Value>(iter));
+        }
+        _with.iso()->ThrowException(Exception::TypeError(
+            String::NewFromUtf8Literal(_with.iso(), "Result of the Symbol.iterator method is not an object")));
+      }
+    }
+  }
+  RtnValue rtn = {};
+  rtn.error = _with.exceptionError();
+  return rtn;
+}
+
+// Calls the iterator's `next` method, returning the result's value and setting `*done`.
+RtnValue IteratorNext(ValuePtr ptr, Bool* done) {
+  WithObject _with(ptr);
+  Local<Context> local_ctx = _with.local_ctx;
+  Local<Value> next, result, value, isDone;
+  if (_with.obj->Get(local_ctx, _with.makeString("next")).ToLocal(&next)) {
+    if (!next->IsFunction()) {
+      _with.iso()->ThrowException(Exception::TypeError(
+          String::NewFromUtf8Literal(_with.iso(), "Iterator's next method is not a function")));
+    } else if (next.As<Function>()->Call(local_ctx, _with.obj, 0, nullptr).ToLocal(&result)) {
+      if (!result->IsObject()) {
+        _with.iso()->ThrowException(Exception::TypeError(
+            String::NewFromUtf8Literal(_with.iso(), "Iterator result is not an object")));
+      } else if (result.As<Object>()->Get(local_ctx, _with.makeString("done")).ToLocal(&isDone)) {
+        *done = isDone->BooleanValue(_with.iso());
+        if (*done) {
+          return _with.returnValue(MaybeLocal<Value>(Undefined(_with.iso())));
+        }
+        return _with.returnValue(result.As<Object>()->Get(local_ctx, _with.makeString("value")));
+      }
+    }
+  }
+  *done = true;
+  RtnValue rtn = {};
+  rtn.error = _with.exceptionError();
+  return rtn;
+}
+
+// Calls the iterator's `return` method, if it has one, to tell it iteration is over.
+RtnError IteratorReturn(ValuePtr ptr) {
+  WithObject _with(ptr);
+  Local<Value> method, result;
+  if (_with.obj->Get(_with.local_ctx, _with.makeString("return")).ToLocal(&method)) {
+    if (method->IsNullOrUndefined() ||
+        (method->IsFunction() &&
+         method.As<Function>()->Call(_with.local_ctx, _with.obj, 0, nullptr).ToLocal(&result))) {
+      return RtnError{};
+    }
+    if (!method->IsFunction()) {
+      _with.iso()->ThrowException(Exception::TypeError(
+          String::NewFromUtf8Literal(_with.iso(), "Iterator's return method is not a function")));
+    }
+  }
+  return _with.exceptionError();
+}
+
+
+/********** TypedArray **********/
+
+static void releaseExternalMemory(void* data, size_t length, void* deleter_data) {
+  goReleaseExternalMemory(reinterpret_cast<uintptr_t>(deleter_data));
+}
+
+// Creates a typed array over external memory, without copying it. When V8 no longer
+// needs the memory, it passes `handle` back to Go to release it.
+RtnValue NewExternalTypedArray(ContextPtr ctx, int /*TypedArrayType*/ type,
+                               void* data, size_t length, size_t byteLength, uintptr_t handle) {
+  WithContext _with(ctx);
+  Isolate* iso = _with.iso();
+  Local<ArrayBuffer> buffer;
+  if (data == nullptr) {
+    buffer = ArrayBuffer::New(iso, 0);
+  } else {
+    std::unique_ptr<BackingStore> store = ArrayBuffer::NewBackingStore(
+        data, byteLength, releaseExternalMemory, reinterpret_cast<void*>(handle));
+    buffer = ArrayBuffer::New(iso, std::move(store));
+  }
+
+  Local<Value> array;
+  switch (type) {
+    case Int8Array_type:      array = Int8Array::New(buffer, 0, length); break;
+    case Uint8Array_type:     array = Uint8Array::New(buffer, 0, length); break;
+    case Int16Array_type:     array = Int16Array::New(buffer, 0, length); break;
+    case Uint16Array_type:    array = Uint16Array::New(buffer, 0, length); break;
+    case Int32Array_type:     array = Int32Array::New(buffer, 0, length); break;
+    case Uint32Array_type:    array = Uint32Array::New(buffer, 0, length); break;
+    case Float32Array_type:   array = Float32Array::New(buffer, 0, length); break;
+    case Float64Array_type:   array = Float64Array::New(buffer, 0, length); break;
+    case BigInt64Array_type:  array = BigInt64Array::New(buffer, 0, length); break;
+    case BigUint64Array_type: array = BigUint64Array::New(buffer, 0, length); break;
+  }
+  RtnValue rtn = {};
+  rtn.value = ctx->addValue(array);
+  return rtn;
+}
+
+size_t TypedArrayLength(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<TypedArray>()->Length();
+}
+
+void TypedArrayRelease(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<ArrayBuffer> buffer = _with.value.As<TypedArray>()->Buffer();
+  if (buffer->IsDetachable()) {
+    buffer->Detach();
+  }
+}
+
+
+/********** ArrayBuffer **********/
+
+ValueRef NewArrayBuffer(ContextPtr ctx, size_t byteLength) {
+  WithContext _with(ctx);
+  return _with.returnValue(ArrayBuffer::New(_with.iso(), byteLength));
+}
+
+// Returns the memory of an ArrayBuffer, SharedArrayBuffer or ArrayBufferView.
+ByteArray ArrayBufferContents(ValuePtr ptr) {
+  WithValue _with(ptr);
+  ByteArray rtn = {};
+  if (_with.value->IsArrayBufferView()) {
+    Local<ArrayBufferView> view = _with.value.As<ArrayBufferView>();
+    // Getting the buffer moves the contents of small typed arrays off the V8 heap,
+    // where they would be subject to compaction.
+    std::shared_ptr<BackingStore> store = view->Buffer()->GetBackingStore();
+    rtn.data = static_cast<char*>(store->Data()) + view->ByteOffset();
+    rtn.length = view->ByteLength();
+  } else if (_with.value->IsArrayBuffer()) {
+    std::shared_ptr<BackingStore> store = _with.value.As<ArrayBuffer>()->GetBackingStore();
+    rtn.data = store->Data();
+    rtn.length = store->ByteLength();
+  } else if (_with.value->IsSharedArrayBuffer()) {
+    std::shared_ptr<BackingStore> store = _with.value.As<SharedArrayBuffer>()->GetBackingStore();
+    rtn.data = store->Data();
+    rtn.length = store->ByteLength();
+  }
+  if (rtn.length == 0) {
+    rtn.data = nullptr;
+  }
+  return rtn;
+}
+
+size_t ArrayBufferByteLength(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<ArrayBuffer>()->ByteLength();
+}
+
+int ArrayBufferDetach(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<ArrayBuffer> buffer = _with.value.As<ArrayBuffer>();
+  if (!buffer->IsDetachable()) {
+    return 0;
+  }
+  buffer->Detach();
+  return 1;
+}
+
+
+/********** DataView **********/
+
+ValueRef NewDataView(ValuePtr buffer, size_t byteOffset, size_t byteLength) {
+  WithValue _with(buffer);
+  return _with.returnValue(DataView::New(_with.value.As<ArrayBuffer>(), byteOffset, byteLength));
+}
+
+
+/********** SharedArrayBuffer **********/
+
+ValueRef NewSharedArrayBuffer(ContextPtr ctx, size_t byteLength) {
+  WithContext _with(ctx);
+  return _with.returnValue(SharedArrayBuffer::New(_with.iso(), byteLength));
+}
+
+ValueRef NewSharedArrayBufferFromStore(ContextPtr ctx, BackingStorePtr ptr) {
+  WithContext _with(ctx);
+  return _with.returnValue(SharedArrayBuffer::New(_with.iso(), ptr->store));
+}
+
+size_t SharedArrayBufferByteLength(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<SharedArrayBuffer>()->ByteLength();
+}
+
+BackingStorePtr SharedArrayBufferGetBackingStore(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return new V8GoBackingStore{_with.value.As<SharedArrayBuffer>()->GetBackingStore()};
+}
+
+ByteArray BackingStoreContents(BackingStorePtr ptr) {
+  ByteArray rtn = {};
+  if (ptr->store->ByteLength() > 0) {
+    rtn.data = ptr->store->Data();
+    rtn.length = ptr->store->ByteLength();
+  }
+  return rtn;
+}
+
+void BackingStoreRelease(BackingStorePtr ptr) {
+  delete ptr;
+}
+
+
+/********** Private **********/
+
+PrivatePtr NewPrivate(IsolatePtr iso, const char* name, int nameLen) {
+  WithIsolate _withiso(iso);
+  Local<String> name_val =
+      String::NewFromUtf8(iso, name, NewStringType::kNormal, nameLen).ToLocalChecked();
+  V8GoPrivate* priv = new V8GoPrivate;
+  priv->iso = iso;
+  priv->ptr.Reset(iso, Private::New(iso, name_val));
+  return priv;
+}
+
+void PrivateFreeWrapper(PrivatePtr priv) {
+  priv->ptr.Empty();  // Just does `val_ = 0;` without calling V8::DisposeGlobal
+  delete priv;
+}
+
+int ObjectSetPrivate(ValuePtr ptr, PrivatePtr key, ValuePtr val_ptr) {
+  WithObject _with(ptr);
+  return _with.obj->SetPrivate(_with.local_ctx, key->ptr.Get(_with.iso()),
+                               Deref(val_ptr)).FromMaybe(false);
+}
+
+RtnValue ObjectGetPrivate(ValuePtr ptr, PrivatePtr key) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->GetPrivate(_with.local_ctx, key->ptr.Get(_with.iso())));
+}
+
+int ObjectHasPrivate(ValuePtr ptr, PrivatePtr key) {
+  WithObject _with(ptr);
+  return _with.obj->HasPrivate(_with.local_ctx, key->ptr.Get(_with.iso())).FromMaybe(false);
+}
+
+int ObjectDeletePrivate(ValuePtr ptr, PrivatePtr key) {
+  WithObject _with(ptr);
+  return _with.obj->DeletePrivate(_with.local_ctx, key->ptr.Get(_with.iso())).FromMaybe(false);
+}
@@ -15,10 +15,23 @@ static void ObjectSetGo(ValuePtr ptr, _GoString_ key, ValuePtr val_ptr) {
 	ObjectSet(ptr, _GoStringPtr(key), _GoStringLen(key), val_ptr); }
 static int ObjectDeleteGo(ValuePtr ptr, _GoString_ key) {
 	return ObjectDelete(ptr, _GoStringPtr(key), _GoStringLen(key)); }
+static RtnError ObjectHasOwnGo(ValuePtr ptr, _GoString_ key, Bool* result) {
+	return ObjectHasOwn(ptr, _GoStringPtr(key), _GoStringLen(key), result); }
+static RtnValue ObjectGetOwnGo(ValuePtr ptr, _GoString_ key, Bool* found) {
+	return ObjectGetOwn(ptr, _GoStringPtr(key), _GoStringLen(key), found); }
+static RtnValue ObjectSetAccessorGo(ValuePtr ptr, _GoString_ name, uintptr_t handle,
+                                    Bool getter, Bool setter, int attributes) {
+	return ObjectSetAccessor(ptr, _GoStringPtr(name), _GoStringLen(name), handle,
+	                         getter, setter, attributes); }
+static RtnError ObjectDeleteOwnGo(ValuePtr ptr, _GoString_ key, Bool* deleted) {
+	return ObjectDeleteOwn(ptr, _GoStringPtr(key), _GoStringLen(key), deleted); }
 */
 import "C"
 import (
+	"errors"
 	"fmt"
+	"runtime/cgo"
+	"unsafe"
 )
 
 // Object is a JavaScript object (ECMA-262, 4.3.3)
@@ -62,7 +75,25 @@ func (o *Object) Set(key string, val interface{}) error {
 	return nil
 }
 
-// SetKey is like Set except that the key is passed as a Value (which must be a string.)
+// SetWithAttributes is like Set, but also sets the property's attributes: a ReadOnly
+// property can't be assigned to, a DontEnum property isn't listed by `Object.keys` or
+// `for...in`, and a DontDelete property can't be deleted or redefined. It defines the
+// property, as DefineProperty does, so it fails if the property already exists and is
+// DontDelete.
+func (o *Object) SetWithAttributes(key string, val interface{}, attributes PropertyAttribute) error {
+	value, err := o.ctx.NewValue(val)
+	if err != nil {
+		return err
+	}
+	return o.DefineProperty(key, PropertyDescriptor{
+		Value:        value,
+		Writable:     attributes&ReadOnly == 0,
+		Enumerable:   attributes&DontEnum == 0,
+		Configurable: attributes&DontDelete == 0,
+	})
+}
+
+// SetKey is like Set except that the key is passed as a Value (which must be a string or symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) SetKey(key *Value, val interface{}) error {
 	value, err := o.ctx.NewValue(val)
@@ -88,6 +119,103 @@ func (o *Object) SetIdx(idx uint32, val interface{}) error {
 	return nil
 }
 
+// PropertyDescriptor describes a property to be defined by Object.DefineProperty, like the
+// descriptor passed to `Object.defineProperty` in JS. A descriptor with a Get or Set function
+// defines an accessor property; otherwise it defines a data property with the given Value.
+// The attributes that are not set are false, as they are in JS when a property is created.
+type PropertyDescriptor struct {
+	Value interface{} // The value of a data property; any type that can be passed to NewValue
+	Get   *Function   // The getter of an accessor property
+	Set   *Function   // The setter of an accessor property
+
+	Writable     bool // Whether a data property's value can be changed by assignment
+	Enumerable   bool // Whether the property is listed by `for...in` and `Object.keys`
+	Configurable bool // Whether the property can be deleted or redefined
+}
+
+// DefineProperty defines or modifies a property of the object, as `Object.defineProperty`
+// does in JS. error will be of type `JSError` if the property can't be defined, e.g. because
+// it already exists and isn't configurable.
+func (o *Object) DefineProperty(key string, desc PropertyDescriptor) error {
+	keyVal, err := o.ctx.NewValue(key)
+	if err != nil {
+		return err
+	}
+	return o.DefinePropertyKey(keyVal, desc)
+}
+
+// DefinePropertyKey is like DefineProperty except that the key is passed as a Value,
+// which must be a string or symbol.
+func (o *Object) DefinePropertyKey(key *Value, desc PropertyDescriptor) error {
+	var value, getter, setter C.ValuePtr
+	if desc.Get != nil || desc.Set != nil {
+		if desc.Value != nil || desc.Writable {
+			return errors.New("v8go: a PropertyDescriptor can't have both a Value or Writable and a Get or Set")
+		}
+		if desc.Get != nil {
+			getter = desc.Get.valuePtr()
+		}
+		if desc.Set != nil {
+			setter = desc.Set.valuePtr()
+		}
+	} else if desc.Value != nil {
+		val, err := o.ctx.NewValue(desc.Value)
+		if err != nil {
+			return err
+		}
+		value = val.valuePtr()
+	}
+
+	var flags C.int
+	if desc.Writable {
+		flags |= C.PropertyDescriptorWritable
+	}
+	if desc.Enumerable {
+		flags |= C.PropertyDescriptorEnumerable
+	}
+	if desc.Configurable {
+		flags |= C.PropertyDescriptorConfigurable
+	}
+	rtn := C.ObjectDefineProperty(o.valuePtr(), key.valuePtr(), value, getter, setter, flags)
+	if rtn.msg != nil {
+		return newJSError(o.ctx.iso, rtn)
+	}
+	return nil
+}
+
+// SetAccessor defines a property whose value is computed by calling Go functions: getter is
+// called when the property is read and returns its value, and setter is called with the new
+// value as its single argument when the property is assigned to. Either may be nil; without a
+// setter the property is ReadOnly, so assignments are ignored, or throw a TypeError in strict
+// mode. The callbacks' `this` is the object the property was accessed through, and they can
+// throw exceptions with Isolate.ThrowException. The DontEnum and DontDelete attributes are
+// supported. Like a native property, it appears to JS as a data property rather than one with
+// get and set functions. The callbacks are kept until the object is garbage collected.
+// error will be of type `JSError` if the property can't be defined.
+func (o *Object) SetAccessor(name string, getter, setter FunctionCallback, attributes ...PropertyAttribute) error {
+	if getter == nil && setter == nil {
+		return errors.New("v8go: SetAccessor needs a getter or a setter")
+	}
+	var attrs PropertyAttribute
+	for _, a := range attributes {
+		attrs |= a
+	}
+	if setter == nil {
+		attrs |= ReadOnly
+	}
+	h := cgo.NewHandle(&accessor{getter, setter})
+	rtn := C.ObjectSetAccessorGo(o.valuePtr(), name, C.uintptr_t(h),
+		boolToCBool(getter != nil), boolToCBool(setter != nil), C.int(attrs))
+	data, err := valueResult(o.ctx, rtn)
+	if err != nil {
+		h.Delete()
+		return err
+	}
+	NewPersistent(data).MakeWeak(h.Delete)
+	data.Release()
+	return nil
+}
+
 // SetInternalField sets the value of an internal field for an ObjectTemplate instance.
 // Panics if the index isn't in the range set by (*ObjectTemplate).SetInternalFieldCount.
 func (o *Object) SetInternalField(idx uint32, val interface{}) error {
@@ -106,25 +234,261 @@ func (o *Object) SetInternalField(idx uint32, val interface{}) error {
 	return nil
 }
 
+// internalGoValue holds a Go value stored in an internal field by SetInternalGoValue.
+type internalGoValue struct {
+	v interface{}
+}
+
+// SetInternalGoValue stores a Go value in an internal field of an ObjectTemplate instance,
+// so that a JS object can carry a reference to the Go object backing it, e.g. for use in
+// the FunctionCallbacks of its methods. The Go value is kept alive until the JS object is
+// garbage collected or the Isolate is disposed, or until it is replaced. Panics if the index
+// isn't in the range set by (*ObjectTemplate).SetInternalFieldCount.
+func (o *Object) SetInternalGoValue(idx uint32, v interface{}) {
+	if idx >= o.InternalFieldCount() {
+		panic(fmt.Errorf("index out of range [%v] with length %v", idx, o.InternalFieldCount()))
+	}
+	if h := C.ObjectGetInternalFieldHandle(o.valuePtr(), C.int(idx)); h != 0 {
+		cgo.Handle(h).Value().(*internalGoValue).v = v
+		return
+	}
+	h := cgo.NewHandle(&internalGoValue{v})
+	C.ObjectSetInternalFieldHandle(o.valuePtr(), C.int(idx), C.uintptr_t(h))
+	NewPersistent(o).MakeWeak(h.Delete)
+}
+
+// GetInternalGoValue returns the Go value stored in an internal field by SetInternalGoValue,
+// or nil if there is none. Panics if given an out of range index.
+func (o *Object) GetInternalGoValue(idx uint32) interface{} {
+	if idx >= o.InternalFieldCount() {
+		panic(fmt.Errorf("index out of range [%v] with length %v", idx, o.InternalFieldCount()))
+	}
+	if h := C.ObjectGetInternalFieldHandle(o.valuePtr(), C.int(idx)); h != 0 {
+		return cgo.Handle(h).Value().(*internalGoValue).v
+	}
+	return nil
+}
+
 // InternalFieldCount returns the number of internal fields this Object has.
 func (o *Object) InternalFieldCount() uint32 {
 	count := C.ObjectInternalFieldCount(o.valuePtr())
 	return uint32(count)
 }
 
+// IdentityHash returns a hash code for the object's identity, which is the same for the
+// lifetime of the object, without adding any properties to it. It is not unique: different
+// objects may have the same hash, so use SameValue to check whether two objects with the
+// same hash are the same object.
+func (o *Object) IdentityHash() int {
+	return int(C.ObjectGetIdentityHash(o.valuePtr()))
+}
+
+// GetPrototype returns the object's prototype, like `Object.getPrototypeOf` in JS,
+// which is an object or null.
+func (o *Object) GetPrototype() *Value {
+	return &Value{C.ObjectGetPrototype(o.valuePtr()), o.ctx}
+}
+
+// SetPrototype sets the object's prototype, like `Object.setPrototypeOf` in JS. The
+// prototype must be an object or null. error will be of type `JSError` if the prototype
+// can't be changed, e.g. because the object isn't extensible or it would create a cycle.
+func (o *Object) SetPrototype(proto Valuer) error {
+	rtn := C.ObjectSetPrototype(o.valuePtr(), proto.value().valuePtr())
+	if rtn.msg != nil {
+		return newJSError(o.ctx.iso, rtn)
+	}
+	return nil
+}
+
+// Clone returns a copy of the object. A shallow copy has the same prototype as the object
+// and copies of its own properties, including accessors and non-enumerable ones, but shares
+// the objects they refer to. A deep copy is made with the structured clone algorithm,
+// described for Value.Serialize, so nested objects are copied too, but prototypes and
+// accessors aren't preserved. error will be of type `JSError` if a deep copy can't be made,
+// e.g. because the object contains a function.
+func (o *Object) Clone(deep bool) (*Object, error) {
+	if !deep {
+		return &Object{&Value{C.ObjectClone(o.valuePtr()), o.ctx}}, nil
+	}
+	return objectResult(o.ctx, C.ValueCloneInto(o.valuePtr(), o.ctx.ptr))
+}
+
+// CreationContext returns the Context the object was created in, which may differ from the
+// Context it's being accessed through. Returns nil if that context has been closed.
+func (o *Object) CreationContext() *Context {
+	ref := C.ObjectGetCreationContext(o.valuePtr())
+	if ref == 0 {
+		return nil
+	}
+	return contextFromHandle(ref)
+}
+
+// IntegrityLevel is a level of protection against changes to an object's properties.
+type IntegrityLevel int
+
+const (
+	// Frozen objects can't have properties added, removed, or changed; like `Object.freeze`.
+	Frozen IntegrityLevel = iota
+	// Sealed objects can't have properties added or removed; like `Object.seal`.
+	Sealed
+)
+
+// SetIntegrityLevel freezes or seals the object, as `Object.freeze` or `Object.seal` does.
+// This only affects the object itself, not the objects its properties refer to; see
+// DeepFreeze. error will be of type `JSError` if the object can't be frozen or sealed.
+func (o *Object) SetIntegrityLevel(level IntegrityLevel) error {
+	rtn := C.ObjectSetIntegrityLevel(o.valuePtr(), C.int(level))
+	if rtn.msg != nil {
+		return newJSError(o.ctx.iso, rtn)
+	}
+	return nil
+}
+
+// DeepFreeze freezes the object and, recursively, every object reachable through its own
+// properties, including the getters and setters of accessor properties, but not prototypes.
+// Frozen host APIs can't be modified by the scripts they're exposed to.
+// error will be of type `JSError` if an object can't be frozen, e.g. a typed array.
+func (o *Object) DeepFreeze() error {
+	rtn := C.ObjectDeepFreeze(o.valuePtr())
+	if rtn.msg != nil {
+		return newJSError(o.ctx.iso, rtn)
+	}
+	return nil
+}
+
+// PreventExtensions prevents properties from being added to the object, as
+// `Object.preventExtensions` does. error will be of type `JSError` if it fails.
+func (o *Object) PreventExtensions() error {
+	_, err := o.callBuiltin(C.ObjectPreventExtensions_fn)
+	return err
+}
+
+// IsFrozen returns true if the object is frozen, as `Object.isFrozen` does.
+func (o *Object) IsFrozen() bool {
+	result, _ := o.callBuiltin(C.ObjectIsFrozen_fn)
+	return result
+}
+
+// IsSealed returns true if the object is sealed (or frozen), as `Object.isSealed` does.
+func (o *Object) IsSealed() bool {
+	result, _ := o.callBuiltin(C.ObjectIsSealed_fn)
+	return result
+}
+
+// IsExtensible returns true if properties can be added to the object, as
+// `Object.isExtensible` does.
+func (o *Object) IsExtensible() bool {
+	result, _ := o.callBuiltin(C.ObjectIsExtensible_fn)
+	return result
+}
+
+func (o *Object) callBuiltin(fn C.ObjectBuiltin) (bool, error) {
+	var result C.Bool
+	rtn := C.ObjectCallBuiltin(o.valuePtr(), fn, &result)
+	if rtn.msg != nil {
+		return false, newJSError(o.ctx.iso, rtn)
+	}
+	return result != 0, nil
+}
+
 // Get tries to get a Value for a given Object property key.
 func (o *Object) Get(key string) (*Value, error) {
 	rtn := C.ObjectGetGo(o.valuePtr(), key)
 	return valueResult(o.ctx, rtn)
 }
 
-// GetKey is like Get except that the key is passed as a Value (which must be a string.)
+// GetInto gets the value of a property and stores it in the Go value that target points
+// to, as Value.Unmarshal does, checking its type and converting it in one step. If the
+// value can't be stored in target, error will be of type `*UnmarshalTypeError`.
+func (o *Object) GetInto(key string, target interface{}) error {
+	val, err := o.Get(key)
+	if err != nil {
+		return err
+	}
+	return val.Unmarshal(target)
+}
+
+// Get gets the value of an object's property as a T, checking its type and converting it
+// in one step, as Value.Unmarshal does. For example:
+//
+//	name, err := v8go.Get[string](obj, "name")
+//
+// If the value can't be stored in a T, error will be of type `*UnmarshalTypeError`.
+func Get[T any](obj *Object, key string) (T, error) {
+	var t T
+	err := obj.GetInto(key, &t)
+	return t, err
+}
+
+// GetKey is like Get except that the key is passed as a Value (which must be a string or symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) GetKey(key *Value) (*Value, error) {
 	rtn := C.ObjectGetKey(o.valuePtr(), key.valuePtr())
 	return valueResult(o.ctx, rtn)
 }
 
+// propertiesBatchSize is the number of properties Properties reads per call into V8.
+const propertiesBatchSize = 64
+
+// Properties returns an iterator over the object's own enumerable string-keyed properties,
+// in the same order as `Object.entries`, and a function returning any error that ended the
+// iteration early. With Go 1.23 or later it can be used as:
+//
+//	props, errFn := obj.Properties()
+//	for key, val := range props {
+//		...
+//	}
+//	if err := errFn(); err != nil {
+//		...
+//	}
+//
+// The keys are read when iteration begins; properties deleted or made non-enumerable while
+// iterating are skipped. Properties are read from V8 in batches, which is much faster than
+// calling Get for each key. The error will be of type `JSError` if a getter threw.
+func (o *Object) Properties() (func(yield func(key string, value *Value) bool), func() error) {
+	var err error
+	seq := func(yield func(key string, value *Value) bool) {
+		err = nil
+		names, e := valueResult(o.ctx, C.ObjectGetOwnPropertyNames(o.valuePtr()))
+		if e != nil {
+			err = e
+			return
+		}
+		length := (&Array{Object{names}}).Length()
+		values := make([]C.ValueRef, propertiesBatchSize)
+		lengths := make([]C.int, propertiesBatchSize)
+		for start := uint32(0); start < length; start += propertiesBatchSize {
+			end := start + propertiesBatchSize
+			if end > length {
+				end = length
+			}
+			var keys *C.char
+			var count C.uint32_t
+			rtn := C.ObjectGetProperties(o.valuePtr(), names.valuePtr(), C.uint32_t(start),
+				C.uint32_t(end), &values[0], &keys, &lengths[0], &count)
+			if rtn.msg != nil {
+				err = newJSError(o.ctx.iso, rtn)
+				return
+			}
+			total := 0
+			for _, n := range lengths[:count] {
+				total += int(n)
+			}
+			buf := C.GoStringN(keys, C.int(total))
+			C.free(unsafe.Pointer(keys))
+			offset := 0
+			for i, n := range lengths[:count] {
+				key := buf[offset : offset+int(n)]
+				offset += int(n)
+				if !yield(key, &Value{values[i], o.ctx}) {
+					return
+				}
+			}
+		}
+	}
+	return seq, func() error { return err }
+}
+
 // GetInternalField gets the Value set by SetInternalField for the given index
 // or the JS undefined value if the index hadn't been set.
 // Panics if given an out of range index.
@@ -148,7 +512,7 @@ func (o *Object) Has(key string) bool {
 	return C.ObjectHasGo(o.valuePtr(), key) != 0
 }
 
-// HasKey is like Has except that the key is passed as a Value (which must be a string.)
+// HasKey is like Has except that the key is passed as a Value (which must be a string or symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) HasKey(key *Value) bool {
 	return C.ObjectHasKey(o.valuePtr(), key.valuePtr()) != 0
@@ -164,7 +528,7 @@ func (o *Object) Delete(key string) bool {
 	return C.ObjectDeleteGo(o.valuePtr(), key) != 0
 }
 
-// DeleteKey is like Delete except that the key is passed as a Value (which must be a string.)
+// DeleteKey is like Delete except that the key is passed as a Value (which must be a string or symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) DeleteKey(key *Value) bool {
 	return C.ObjectDeleteKey(o.valuePtr(), key.valuePtr()) != 0
@@ -174,3 +538,38 @@ func (o *Object) DeleteKey(key *Value) bool {
 func (o *Object) DeleteIdx(idx uint32) bool {
 	return C.ObjectDeleteIdx(o.valuePtr(), C.uint32_t(idx)) != 0
 }
+
+// HasOwnProperty returns true if the object itself has the property, ignoring its prototype
+// chain, like `Object.hasOwn` in JS, so that properties added to prototypes by scripts, e.g.
+// `Object.prototype`, can't be mistaken for the object's. error will be of type `JSError` if
+// the check throws, as a Proxy can.
+func (o *Object) HasOwnProperty(key string) (bool, error) {
+	var result C.Bool
+	rtn := C.ObjectHasOwnGo(o.valuePtr(), key, &result)
+	if rtn.msg != nil {
+		return false, newJSError(o.ctx.iso, rtn)
+	}
+	return result != 0, nil
+}
+
+// GetOwnProperty is like Get, but only gets a property the object itself has, ignoring its
+// prototype chain. ok is false, and the value undefined, if the object has no such property.
+// error will be of type `JSError` if reading the property throws.
+func (o *Object) GetOwnProperty(key string) (val *Value, ok bool, err error) {
+	var found C.Bool
+	rtn := C.ObjectGetOwnGo(o.valuePtr(), key, &found)
+	val, err = valueResult(o.ctx, rtn)
+	return val, err == nil && found != 0, err
+}
+
+// DeleteOwnProperty deletes a property the object itself has. Unlike Delete, it returns false
+// if the object doesn't have the property, as well as if it can't be deleted. error will be
+// of type `JSError` if the deletion throws, as a Proxy can.
+func (o *Object) DeleteOwnProperty(key string) (bool, error) {
+	var deleted C.Bool
+	rtn := C.ObjectDeleteOwnGo(o.valuePtr(), key, &deleted)
+	if rtn.msg != nil {
+		return false, newJSError(o.ctx.iso, rtn)
+	}
+	return deleted != 0, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import "runtime/cgo"
+
+// accessor holds the Go callbacks of a property defined by Object.SetAccessor.
+type accessor struct {
+	getter, setter FunctionCallback
+}
+
+//export goAccessorCallback
+func goAccessorCallback(ctxHandle C.uintptr_t, handle C.uintptr_t, thisRef, holderRef, value C.ValueRef, isSetter C.Bool) (result C.CallbackResult) {
+	ctx := contextFromHandle(ctxHandle)
+	if !ctx.iso.crashOnPanic {
+		defer func() {
+			if r := recover(); r != nil {
+				result = C.CallbackResult{value: ctx.throwPanic(r).valuePtr()}
+			}
+		}()
+	}
+	acc := cgo.Handle(handle).Value().(*accessor)
+	info := &FunctionCallbackInfo{
+		ctx:    ctx,
+		this:   &Object{&Value{thisRef, ctx}},
+		holder: &Object{&Value{holderRef, ctx}},
+	}
+	if isSetter != 0 {
+		info.args = []*Value{{value, ctx}}
+		acc.setter(info)
+		return result
+	}
+	if val := acc.getter(info); val != nil {
+		return C.CallbackResult{value: val.valuePtr()}
+	}
+	return info.rtn
+}
@@ -21,7 +21,7 @@ const (
 	// None.
 	None PropertyAttribute = 0
 	// ReadOnly, ie. not writable.
-	ReadOnly PropertyAttribute = 1 << iota
+	ReadOnly PropertyAttribute = 1 << (iota - 1) // (the same values as V8's)
 	// DontEnum, ie. not enumerable.
 	DontEnum
 	// DontDelete, ie. not configurable.
@@ -60,6 +60,24 @@ func (o *ObjectTemplate) NewInstance(ctx *Context) (*Object, error) {
 	return objectResult(ctx, rtn)
 }
 
+// CachedInstance returns an instance of this template that is created, as by NewInstance,
+// the first time it's called for a Context, and returned again by later calls for the same
+// Context, so that a host API can be injected repeatedly without duplicating its objects.
+// The instance is kept alive until the Context is closed.
+func (o *ObjectTemplate) CachedInstance(ctx *Context) (*Object, error) {
+	val, err := ctx.cachedInstance(o.template, func() (*Value, error) {
+		obj, err := o.NewInstance(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return obj.Value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Object{val}, nil
+}
+
 // SetInternalFieldCount sets the number of internal fields that instances of this
 // template will have.
 func (o *ObjectTemplate) SetInternalFieldCount(fieldCount uint32) {
@@ -72,6 +90,138 @@ func (o *ObjectTemplate) InternalFieldCount() uint32 {
 	return uint32(C.ObjectTemplateInternalFieldCount(o.ptr))
 }
 
+// SetCallAsFunctionHandler makes the objects created from this template callable, like
+// functions: calling one, with or without `new`, calls callback, whose result is the result
+// of the call. As for a function, the callback's receiver is the `this` of the call, not the
+// object itself. `typeof` reports these objects as "function".
+func (o *ObjectTemplate) SetCallAsFunctionHandler(callback FunctionCallback) {
+	if callback == nil {
+		panic("nil FunctionCallback argument not supported")
+	}
+	cbref := o.iso.registerCallback(callback)
+	C.ObjectTemplateSetCallAsFunctionHandler(o.ptr, C.int(cbref))
+	runtime.KeepAlive(o)
+}
+
+// SetImmutableProto makes the prototype of the objects created from this template
+// immutable, so that assigning to `__proto__` or calling `Object.setPrototypeOf` on them
+// throws a TypeError, unless the prototype is unchanged. This is useful for a global object
+// template, to stop untrusted scripts from swapping the global's prototype.
+func (o *ObjectTemplate) SetImmutableProto() {
+	C.ObjectTemplateSetImmutableProto(o.ptr)
+	runtime.KeepAlive(o)
+}
+
+// PropertyCallbackInfo is the argument that is passed to the callbacks of a
+// NamedPropertyHandler.
+type PropertyCallbackInfo struct {
+	ctx  *Context
+	this *Object
+	name *Value
+}
+
+// Context is the current context that the callback is being executed in.
+func (i *PropertyCallbackInfo) Context() *Context {
+	return i.ctx
+}
+
+// This returns the object whose property is being accessed.
+func (i *PropertyCallbackInfo) This() *Object {
+	return i.this
+}
+
+// Name returns the name of the property being accessed, or "" in an Enumerator.
+func (i *PropertyCallbackInfo) Name() string {
+	if i.name == nil {
+		return ""
+	}
+	return i.name.String()
+}
+
+// NamedPropertyHandler holds Go callbacks that intercept access to the string-named
+// properties of objects created from an ObjectTemplate; see ObjectTemplate.SetHandler.
+// Any of them may be nil. Each can decline to intercept an access, in which case the
+// object's own properties are used as usual. They can throw exceptions with
+// Isolate.ThrowException.
+type NamedPropertyHandler struct {
+	// Getter returns the value of a property, or nil to not intercept it.
+	Getter func(info *PropertyCallbackInfo) *Value
+	// Setter is called when a property is assigned to, and returns true if it handled
+	// the assignment, so that the value is not stored in the object.
+	Setter func(info *PropertyCallbackInfo, value *Value) bool
+	// Query returns the attributes of a property and true if it exists, as used by the `in`
+	// operator and `Object.getOwnPropertyDescriptor`, or false to not intercept it.
+	Query func(info *PropertyCallbackInfo) (attributes PropertyAttribute, ok bool)
+	// Deleter is called when a property is deleted, and returns whether it was deleted,
+	// and true if it handled the deletion.
+	Deleter func(info *PropertyCallbackInfo) (deleted bool, ok bool)
+	// Enumerator returns the names of the properties the handler provides, as listed by
+	// `Object.keys` and `for...in`. A Query callback is needed for them to be enumerable.
+	Enumerator func(info *PropertyCallbackInfo) []string
+}
+
+// SetHandler installs Go callbacks that intercept access to the named properties of the
+// objects created from this template, so that their properties can be computed dynamically.
+// Symbol-named properties are not intercepted.
+func (o *ObjectTemplate) SetHandler(handler NamedPropertyHandler) {
+	var ops C.int
+	for op, present := range map[C.InterceptorOp]bool{
+		C.NamedGetter_op:     handler.Getter != nil,
+		C.NamedSetter_op:     handler.Setter != nil,
+		C.NamedQuery_op:      handler.Query != nil,
+		C.NamedDeleter_op:    handler.Deleter != nil,
+		C.NamedEnumerator_op: handler.Enumerator != nil,
+	} {
+		if present {
+			ops |= 1 << op
+		}
+	}
+	ref := o.iso.registerHandler(&handler)
+	C.ObjectTemplateSetNamedHandler(o.ptr, C.int(ref), ops)
+}
+
+//export goNamedPropertyCallback
+func goNamedPropertyCallback(ctxHandle C.uintptr_t, ref C.int, op C.InterceptorOp, thisRef, name, value C.ValueRef) C.InterceptorResult {
+	ctx := contextFromHandle(ctxHandle)
+	handler := ctx.iso.getHandler(int(ref))
+	info := &PropertyCallbackInfo{
+		ctx:  ctx,
+		this: &Object{&Value{thisRef, ctx}},
+	}
+	if op != C.NamedEnumerator_op {
+		info.name = &Value{name, ctx}
+	}
+
+	var rtn C.InterceptorResult
+	var ok bool
+	switch op {
+	case C.NamedGetter_op:
+		if val := handler.Getter(info); val != nil {
+			rtn.value = val.valuePtr()
+			ok = true
+		}
+	case C.NamedSetter_op:
+		ok = handler.Setter(info, &Value{value, ctx})
+	case C.NamedQuery_op:
+		var attrs PropertyAttribute
+		attrs, ok = handler.Query(info)
+		rtn.result = C.int(attrs)
+	case C.NamedDeleter_op:
+		var deleted bool
+		deleted, ok = handler.Deleter(info)
+		rtn.result = C.int(boolToCBool(deleted))
+	case C.NamedEnumerator_op:
+		if names := handler.Enumerator(info); names != nil {
+			if val, err := NewValueOf(ctx, names); err == nil {
+				rtn.value = val.valuePtr()
+				ok = true
+			}
+		}
+	}
+	rtn.intercepted = boolToCBool(ok)
+	return rtn
+}
+
 func (o *ObjectTemplate) apply(opts *contextOptions) {
 	opts.gTmpl = o
 }
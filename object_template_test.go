@@ -142,6 +142,291 @@ func TestObjectTemplateNewInstance(t *testing.T) {
 	}
 }
 
+func TestObjectTemplateAttributes(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	tmpl := v8.NewObjectTemplate(iso)
+	fatalIf(t, tmpl.Set("VERSION", "1.0", v8.ReadOnly))
+	fatalIf(t, tmpl.Set("hidden", int32(1), v8.DontEnum))
+	fatalIf(t, tmpl.Set("fixed", int32(2), v8.DontDelete))
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	obj, err := tmpl.NewInstance(ctx)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("obj", obj))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"obj.VERSION = '2.0'; obj.VERSION", "1.0"},
+		{"Object.keys(obj).join()", "VERSION,fixed"},
+		{"delete obj.fixed", "false"},
+		{"delete obj.VERSION", "true"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+}
+
+func TestObjectTemplateSetHandler(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	env := map[string]string{"HOME": "/home/me", "SHELL": "/bin/sh"}
+	tmpl := v8.NewObjectTemplate(iso)
+	tmpl.SetHandler(v8.NamedPropertyHandler{
+		Getter: func(info *v8.PropertyCallbackInfo) *v8.Value {
+			if v, ok := env[info.Name()]; ok {
+				val, _ := v8.NewValue(iso, v)
+				return val
+			}
+			return nil
+		},
+		Setter: func(info *v8.PropertyCallbackInfo, value *v8.Value) bool {
+			if info.Name() == "local" {
+				return false
+			}
+			if !value.IsString() {
+				msg, _ := v8.NewValue(iso, "environment values must be strings")
+				iso.ThrowException(msg)
+				return true
+			}
+			env[info.Name()] = value.String()
+			return true
+		},
+		Query: func(info *v8.PropertyCallbackInfo) (v8.PropertyAttribute, bool) {
+			_, ok := env[info.Name()]
+			return v8.DontDelete, ok
+		},
+		Deleter: func(info *v8.PropertyCallbackInfo) (bool, bool) {
+			if _, ok := env[info.Name()]; !ok {
+				return false, false
+			}
+			delete(env, info.Name())
+			return true, true
+		},
+		Enumerator: func(info *v8.PropertyCallbackInfo) []string {
+			return []string{"HOME", "SHELL"}
+		},
+	})
+	tmpl.Set("version", "1")
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	obj, err := tmpl.NewInstance(ctx)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("env", obj))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"env.HOME", "/home/me"},
+		{"env.version", "1"},
+		{"env.MISSING === undefined", "true"},
+		{"'SHELL' in env", "true"},
+		{"Object.keys(env).join()", "version,HOME,SHELL"},
+		{"env.EDITOR = 'vi'; env.EDITOR", "vi"},
+		{"env.local = 5; env.local", "5"},
+		{"try { env.X = 1 } catch (e) { e }", "environment values must be strings"},
+		{"delete env.SHELL", "true"},
+		{"'SHELL' in env", "false"},
+		{"env[Symbol.iterator] === undefined", "true"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+	if env["EDITOR"] != "vi" {
+		t.Errorf("expected the setter to store the value, got %v", env)
+	}
+	if _, ok := env["local"]; ok {
+		t.Error("expected the setter to decline the property")
+	}
+}
+
+func TestObjectTemplateSetCallAsFunctionHandler(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	modules := map[string]string{"fs": "file system", "path": "paths"}
+	tmpl := v8.NewObjectTemplate(iso)
+	tmpl.SetCallAsFunctionHandler(func(info *v8.FunctionCallbackInfo) *v8.Value {
+		args := info.Args()
+		if len(args) == 0 {
+			return nil
+		}
+		mod, ok := modules[args[0].String()]
+		if !ok {
+			msg, _ := v8.NewValue(iso, "no module "+args[0].String())
+			return iso.ThrowException(msg)
+		}
+		val, _ := v8.NewValue(iso, mod)
+		return val
+	})
+	tmpl.Set("cache", "none")
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	obj, err := tmpl.NewInstance(ctx)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("require", obj))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"require('fs')", "file system"},
+		{"require.cache", "none"},
+		{"typeof require", "function"},
+		{"require() === undefined", "true"},
+		{"try { require('http') } catch (e) { e }", "no module http"},
+		{"[require].map(r => r('path')).join()", "paths"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+	if recoverPanic(func() { tmpl.SetCallAsFunctionHandler(nil) }) == nil {
+		t.Error("expected panic for a nil callback")
+	}
+}
+
+func TestObjectTemplateCachedInstance(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx1 := v8.NewContext(iso)
+	defer ctx1.Close()
+	ctx2 := v8.NewContext(iso)
+	defer ctx2.Close()
+
+	tmpl := v8.NewObjectTemplate(iso)
+	fn := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value { return nil })
+
+	// The cached instance outlives the scope it was created in.
+	ctx1.WithTemporaryValues(func() {
+		obj, err := tmpl.CachedInstance(ctx1)
+		fatalIf(t, err)
+		fatalIf(t, obj.Set("tag", "first"))
+	})
+	obj1, err := tmpl.CachedInstance(ctx1)
+	fatalIf(t, err)
+	if tag, _ := obj1.Get("tag"); tag.String() != "first" {
+		t.Error("expected the same instance in the same context")
+	}
+	again, err := tmpl.CachedInstance(ctx1)
+	fatalIf(t, err)
+	if !again.SameValue(obj1.Value) {
+		t.Error("expected the same instance in the same context")
+	}
+	obj2, err := tmpl.CachedInstance(ctx2)
+	fatalIf(t, err)
+	if obj2.CreationContext() != ctx2 {
+		t.Error("expected a separate instance for another context")
+	}
+
+	f1 := fn.CachedFunction(ctx1)
+	if !fn.CachedFunction(ctx1).SameValue(f1.Value) {
+		t.Error("expected the same function in the same context")
+	}
+	if fn.CachedFunction(ctx2).SameValue(f1.Value) {
+		t.Error("expected a separate function for another context")
+	}
+}
+
+func TestObjectTemplateSetImmutableProto(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	global := v8.NewObjectTemplate(iso)
+	global.SetImmutableProto()
+	ctx := v8.NewContext(iso, global)
+	defer ctx.Close()
+
+	for _, script := range []string{
+		`Object.setPrototypeOf(globalThis, {})`,
+		`globalThis.__proto__ = {evil: 1}`,
+	} {
+		if _, err := ctx.RunScript(script, ""); err == nil {
+			t.Errorf("expected %q to fail", script)
+		}
+	}
+	val, err := ctx.RunScript(`typeof evil`, "")
+	fatalIf(t, err)
+	if val.String() != "undefined" {
+		t.Error("expected the global's prototype to be unchanged")
+	}
+
+	// Setting the same prototype is allowed.
+	_, err = ctx.RunScript(`Object.setPrototypeOf(globalThis, Object.getPrototypeOf(globalThis))`, "")
+	fatalIf(t, err)
+}
+
+func TestObjectTemplateSetLazyDataProperty(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	calls := 0
+	tmpl := v8.NewObjectTemplate(iso)
+	tmpl.SetLazyDataProperty("config", func(info *v8.PropertyCallbackInfo) *v8.Value {
+		calls++
+		if info.Name() != "config" {
+			t.Errorf("unexpected name %q", info.Name())
+		}
+		val, err := info.Context().JSONParse(`{"debug": true}`)
+		fatalIf(t, err)
+		return val
+	}, v8.ReadOnly)
+	tmpl.SetLazyDataProperty("empty", func(info *v8.PropertyCallbackInfo) *v8.Value {
+		return nil
+	})
+
+	obj, err := tmpl.NewInstance(ctx)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("host", obj))
+
+	val, err := ctx.RunScript(`Object.keys(host).sort().join()`, "")
+	fatalIf(t, err)
+	if val.String() != "config,empty" || calls != 0 {
+		t.Errorf("unexpected keys %q after %d calls", val, calls)
+	}
+
+	val, err = ctx.RunScript(`
+		host.config = null; // (read-only)
+		[host.config === host.config, host.config.debug,
+		 "value" in Object.getOwnPropertyDescriptor(host, "config"), host.empty].join()`, "")
+	fatalIf(t, err)
+	if val.String() != "true,true,true," {
+		t.Errorf("unexpected result %q", val)
+	}
+	if calls != 1 {
+		t.Errorf("expected the getter to be called once, got %d", calls)
+	}
+}
+
 func TestObjectTemplate_garbageCollection(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,8 @@ package v8go_test
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	v8 "github.com/couchbasedeps/v8go"
@@ -153,6 +155,175 @@ func TestObjectSet(t *testing.T) {
 	}
 }
 
+func TestObjectSetWithAttributes(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+	obj := ctx.NewObject()
+	fatalIf(t, ctx.Global().Set("obj", obj))
+
+	fatalIf(t, obj.SetWithAttributes("PI", 3.14, v8.ReadOnly|v8.DontDelete))
+	fatalIf(t, obj.SetWithAttributes("secret", "s", v8.DontEnum))
+	fatalIf(t, obj.SetWithAttributes("plain", "p", v8.None))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"obj.PI = 3; obj.PI", "3.14"},
+		{"delete obj.PI", "false"},
+		{"Object.keys(obj).join()", "PI,plain"},
+		{"obj.secret = 't'; obj.secret", "t"},
+		{"delete obj.plain", "true"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+	if err := obj.SetWithAttributes("PI", 3, v8.None); err == nil {
+		t.Error("expected an error redefining a DontDelete property")
+	}
+	if err := obj.SetWithAttributes("bad", nil, v8.None); err == nil {
+		t.Error("expected an error for a nil value")
+	}
+}
+
+func TestObjectDefineProperty(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	obj := ctx.NewObject()
+	fatalIf(t, ctx.Global().Set("obj", obj))
+
+	fatalIf(t, obj.DefineProperty("fixed", v8.PropertyDescriptor{Value: "x", Enumerable: true}))
+	fatalIf(t, obj.DefineProperty("hidden", v8.PropertyDescriptor{Value: int32(1), Writable: true}))
+
+	count := 0
+	getter := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		count++
+		val, _ := v8.NewValue(iso, int32(count))
+		return val
+	}).GetFunction(ctx)
+	setter := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		count = int(info.Args()[0].Int32())
+		return nil
+	}).GetFunction(ctx)
+	fatalIf(t, obj.DefineProperty("count", v8.PropertyDescriptor{Get: getter, Set: setter, Configurable: true}))
+
+	sym := v8.SymbolIterator(iso)
+	fatalIf(t, obj.DefinePropertyKey(sym.Value, v8.PropertyDescriptor{Value: getter}))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"obj.fixed = 'y'; obj.fixed", "x"},
+		{"obj.hidden = 2; obj.hidden", "2"},
+		{"Object.keys(obj).join()", "fixed"},
+		{"obj.count + obj.count", "3"},
+		{"obj.count = 10; obj.count", "11"},
+		{"JSON.stringify(Object.getOwnPropertyDescriptor(obj, 'fixed'))",
+			`{"value":"x","writable":false,"enumerable":true,"configurable":false}`},
+		{"typeof obj[Symbol.iterator]", "function"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+
+	// Redefining a non-configurable property throws a TypeError, as in JS.
+	err := obj.DefineProperty("fixed", v8.PropertyDescriptor{Value: "z"})
+	if jsErr, ok := err.(*v8.JSError); !ok || jsErr.Message != "TypeError: Cannot redefine property: fixed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := obj.DefineProperty("count", v8.PropertyDescriptor{Value: 1}); err != nil {
+		t.Errorf("expected to redefine a configurable property, got %v", err)
+	}
+	if err := obj.DefineProperty("bad", v8.PropertyDescriptor{Value: 1, Get: getter}); err == nil {
+		t.Error("expected an error for a descriptor with a value and a getter")
+	}
+	if err := obj.DefinePropertyKey(v8.Null(iso), v8.PropertyDescriptor{}); err == nil {
+		t.Error("expected an error for a null key")
+	}
+}
+
+func TestObjectSetAccessor(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	obj := ctx.NewObject()
+	fatalIf(t, ctx.Global().Set("obj", obj))
+
+	celsius := 20.0
+	fatalIf(t, obj.SetAccessor("fahrenheit",
+		func(info *v8.FunctionCallbackInfo) *v8.Value {
+			val, _ := v8.NewValue(iso, celsius*9/5+32)
+			return val
+		},
+		func(info *v8.FunctionCallbackInfo) *v8.Value {
+			f := info.Args()[0]
+			if !f.IsNumber() {
+				msg, _ := v8.NewValue(iso, "fahrenheit must be a number")
+				return iso.ThrowException(msg)
+			}
+			celsius = (f.Number() - 32) * 5 / 9
+			return nil
+		}))
+	fatalIf(t, obj.SetAccessor("id", func(info *v8.FunctionCallbackInfo) *v8.Value {
+		if !info.This().SameValue(obj.Value) {
+			t.Error("unexpected receiver")
+		}
+		val, _ := v8.NewValue(iso, "doc1")
+		return val
+	}, nil, v8.DontEnum, v8.DontDelete))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"obj.fahrenheit", "68"},
+		{"obj.fahrenheit = 212; obj.fahrenheit", "212"},
+		{"obj.id = 'other'; obj.id", "doc1"},
+		{"Object.keys(obj).join()", "fahrenheit"},
+		{"delete obj.id", "false"},
+		{"try { obj.fahrenheit = 'hot' } catch (e) { e }", "fahrenheit must be a number"},
+		{"(() => { 'use strict'; try { obj.id = 'x' } catch (e) { return e.name } })()", "TypeError"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+	if celsius != 100 {
+		t.Errorf("expected the setter to be called, got %v", celsius)
+	}
+	if err := obj.SetAccessor("none", nil, nil); err == nil {
+		t.Error("expected an error without a getter or setter")
+	}
+	frozen, err := ctx.RunScript("Object.freeze({x: 1})", "")
+	fatalIf(t, err)
+	frozenObj, _ := frozen.AsObject()
+	if err := frozenObj.SetAccessor("x", func(info *v8.FunctionCallbackInfo) *v8.Value { return nil }, nil); err == nil {
+		t.Error("expected an error redefining a frozen property")
+	}
+}
+
 func TestObjectInternalFields(t *testing.T) {
 	iso := v8.NewIsolate()
 	defer iso.Dispose()
@@ -200,6 +371,284 @@ func TestObjectInternalFields(t *testing.T) {
 	}
 }
 
+func TestObjectInternalGoValue(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	type document struct{ id string }
+	tmpl := v8.NewObjectTemplate(iso)
+	tmpl.SetInternalFieldCount(2)
+	getID := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		doc := info.This().GetInternalGoValue(0).(*document)
+		val, _ := v8.NewValue(iso, doc.id)
+		return val
+	})
+	fatalIf(t, tmpl.Set("getID", getID))
+
+	for _, id := range []string{"doc1", "doc2"} {
+		obj, err := tmpl.NewInstance(ctx)
+		fatalIf(t, err)
+		if v := obj.GetInternalGoValue(0); v != nil {
+			t.Errorf("expected nil, got %v", v)
+		}
+		obj.SetInternalGoValue(0, &document{id})
+		fatalIf(t, ctx.Global().Set(id, obj))
+	}
+	val, err := ctx.RunScript("doc1.getID() + ' ' + doc2.getID()", "")
+	fatalIf(t, err)
+	if s := val.String(); s != "doc1 doc2" {
+		t.Errorf("unexpected result %q", s)
+	}
+
+	obj, err := tmpl.NewInstance(ctx)
+	fatalIf(t, err)
+	obj.SetInternalGoValue(1, "first")
+	obj.SetInternalGoValue(1, "second")
+	if v := obj.GetInternalGoValue(1); v != "second" {
+		t.Errorf("expected the value to be replaced, got %v", v)
+	}
+	if recoverPanic(func() { obj.SetInternalGoValue(2, "x") }) == nil {
+		t.Error("expected panic from index out of bounds")
+	}
+	if recoverPanic(func() { obj.GetInternalGoValue(2) }) == nil {
+		t.Error("expected panic from index out of bounds")
+	}
+}
+
+func TestObjectIdentityHash(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("globalThis.o = {}; o", "")
+	fatalIf(t, err)
+	obj1, err := val.AsObject()
+	fatalIf(t, err)
+	val, err = ctx.RunScript("o", "")
+	fatalIf(t, err)
+	obj2, err := val.AsObject()
+	fatalIf(t, err)
+
+	if h1, h2 := obj1.IdentityHash(), obj2.IdentityHash(); h1 != h2 || h1 == 0 {
+		t.Errorf("expected equal nonzero hashes for the same object, got %d and %d", h1, h2)
+	}
+	if keys, _ := v8.JSONStringify(ctx, val); keys != "{}" {
+		t.Errorf("expected the object to be unchanged, got %s", keys)
+	}
+}
+
+func TestObjectPrototype(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("globalThis.base = {greet() { return 'hi ' + this.name }}; ({name: 'obj'})", "")
+	fatalIf(t, err)
+	obj, err := val.AsObject()
+	fatalIf(t, err)
+	objectProto, _ := ctx.RunScript("Object.prototype", "")
+	if !obj.GetPrototype().SameValue(objectProto) {
+		t.Error("expected Object.prototype")
+	}
+
+	base, _ := ctx.Global().Get("base")
+	fatalIf(t, obj.SetPrototype(base))
+	if !obj.GetPrototype().SameValue(base) {
+		t.Error("expected the new prototype")
+	}
+	if result, err := obj.MethodCall("greet"); err != nil || result.String() != "hi obj" {
+		t.Errorf("unexpected result %v, %v", result, err)
+	}
+
+	fatalIf(t, obj.SetPrototype(v8.Null(iso)))
+	if !obj.GetPrototype().IsNull() {
+		t.Error("expected a null prototype")
+	}
+
+	baseObj, _ := base.AsObject()
+	fatalIf(t, obj.SetPrototype(base))
+	if err := baseObj.SetPrototype(obj); err == nil {
+		t.Error("expected an error for a cyclic prototype chain")
+	}
+	str, _ := v8.NewValue(iso, "proto")
+	if err := obj.SetPrototype(str); err == nil {
+		t.Error("expected an error for a string prototype")
+	}
+	frozen, _ := ctx.RunScript("Object.freeze({})", "")
+	frozenObj, _ := frozen.AsObject()
+	if err := frozenObj.SetPrototype(base); err == nil {
+		t.Error("expected an error for a non-extensible object")
+	}
+}
+
+func TestObjectCreationContext(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx1 := v8.NewContext(iso)
+	ctx2 := v8.NewContext(iso)
+	defer ctx2.Close()
+
+	val, err := ctx1.RunScript("({})", "")
+	fatalIf(t, err)
+	obj, _ := val.AsObject()
+	if got := obj.CreationContext(); got != ctx1 {
+		t.Errorf("expected the creating context, got %v", got)
+	}
+
+	fatalIf(t, ctx2.Global().Set("shared", obj))
+	val, err = ctx2.RunScript("shared", "")
+	fatalIf(t, err)
+	shared, _ := val.AsObject()
+	if got := shared.CreationContext(); got != ctx1 {
+		t.Errorf("expected the creating context, got %v", got)
+	}
+	local, _ := ctx2.Global().AsObject()
+	if got := local.CreationContext(); got != ctx2 {
+		t.Errorf("expected ctx2 for its own global, got %v", got)
+	}
+
+	ctx1.Close()
+	if got := shared.CreationContext(); got != nil {
+		t.Errorf("expected nil after the creating context closed, got %v", got)
+	}
+}
+
+func TestObjectClone(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		class Template { greet() { return "hi" } }
+		const tmpl = new Template();
+		tmpl.headers = {accept: "text/html"};
+		tmpl.count = 1;
+		tmpl`, "")
+	fatalIf(t, err)
+	obj, _ := val.AsObject()
+
+	shallow, err := obj.Clone(false)
+	fatalIf(t, err)
+	deep, err := obj.Clone(true)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("shallow", shallow))
+	fatalIf(t, ctx.Global().Set("deep", deep))
+
+	val, err = ctx.RunScript(`
+		shallow.count = 2; deep.count = 3;
+		deep.headers.accept = "application/json";
+		[shallow !== tmpl, tmpl.count === 1, shallow.greet() === "hi",
+		 shallow.headers === tmpl.headers,
+		 deep.headers !== tmpl.headers, tmpl.headers.accept === "text/html",
+		 Object.getPrototypeOf(deep) === Object.prototype].every(x => x)`, "")
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Error("unexpected clone behavior")
+	}
+
+	val, err = ctx.RunScript(`({f() {}})`, "")
+	fatalIf(t, err)
+	obj, _ = val.AsObject()
+	if _, err := obj.Clone(true); err == nil {
+		t.Error("expected an error deep-cloning a function")
+	}
+	if _, err := obj.Clone(false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestObjectIntegrityLevel(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	object := func(script string) *v8.Object {
+		t.Helper()
+		val, err := ctx.RunScript(script, "")
+		fatalIf(t, err)
+		obj, err := val.AsObject()
+		fatalIf(t, err)
+		return obj
+	}
+
+	obj := object("globalThis.a = {x: {y: 1}}")
+	if obj.IsFrozen() || obj.IsSealed() || !obj.IsExtensible() {
+		t.Error("expected an ordinary object")
+	}
+	fatalIf(t, obj.SetIntegrityLevel(v8.Sealed))
+	if obj.IsFrozen() || !obj.IsSealed() || obj.IsExtensible() {
+		t.Error("expected a sealed object")
+	}
+	fatalIf(t, obj.SetIntegrityLevel(v8.Frozen))
+	if !obj.IsFrozen() {
+		t.Error("expected a frozen object")
+	}
+	if val, _ := ctx.RunScript("a.x.y = 2; a.x.y", ""); val.Int32() != 2 {
+		t.Error("expected a shallow freeze")
+	}
+
+	obj = object("globalThis.b = {p: 1}")
+	fatalIf(t, obj.PreventExtensions())
+	if obj.IsExtensible() || obj.IsFrozen() {
+		t.Error("expected a non-extensible object")
+	}
+
+	obj = object(`globalThis.api = {
+		version: {major: 1},
+		list: [{}],
+		get config() { return {} },
+		[Symbol.for('s')]: {},
+		fn: function () {},
+	};
+	api.self = api;
+	api`)
+	fatalIf(t, obj.DeepFreeze())
+	tests := [...]string{
+		"Object.isFrozen(api)",
+		"Object.isFrozen(api.version)",
+		"Object.isFrozen(api.list) && Object.isFrozen(api.list[0])",
+		"Object.isFrozen(Object.getOwnPropertyDescriptor(api, 'config').get)",
+		"Object.isFrozen(api[Symbol.for('s')])",
+		"Object.isFrozen(api.fn) && Object.isFrozen(api.fn.prototype)",
+		"!Object.isFrozen(Object.prototype)",
+		"!Object.isFrozen(api.config)",
+		"api.version.major = 2; api.version.major === 1",
+	}
+	for _, script := range tests {
+		val, err := ctx.RunScript(script, "")
+		fatalIf(t, err)
+		if !val.Boolean() {
+			t.Errorf("%s: expected true", script)
+		}
+	}
+
+	obj = object("({bytes: new Uint8Array(4)})")
+	if err := obj.DeepFreeze(); err == nil {
+		t.Error("expected an error freezing a typed array")
+	}
+
+	// Tampering with the global Object doesn't affect the queries.
+	obj = object("Object.isFrozen = () => true; Object.isExtensible = () => false; ({})")
+	if obj.IsFrozen() || !obj.IsExtensible() {
+		t.Error("expected the original Object functions to be used")
+	}
+}
+
 func TestObjectGet(t *testing.T) {
 	t.Parallel()
 
@@ -223,6 +672,76 @@ func TestObjectGet(t *testing.T) {
 	}
 }
 
+func TestObjectGetInto(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("({name: 'foo', count: 3, big: 2n ** 64n})", "")
+	fatalIf(t, err)
+	obj, err := val.AsObject()
+	fatalIf(t, err)
+
+	var name string
+	var count int64
+	fatalIf(t, obj.GetInto("name", &name))
+	fatalIf(t, obj.GetInto("count", &count))
+	if name != "foo" || count != 3 {
+		t.Errorf("unexpected values %q, %d", name, count)
+	}
+
+	err = obj.GetInto("name", &count)
+	typeErr, ok := err.(*v8.UnmarshalTypeError)
+	if !ok {
+		t.Fatalf("expected an UnmarshalTypeError, got %T: %v", err, err)
+	}
+	if typeErr.Value != "string" || typeErr.Type != reflect.TypeOf(count) {
+		t.Errorf("unexpected error %+v", typeErr)
+	}
+	if err := obj.GetInto("big", &count); err == nil {
+		t.Error("expected an error for a BigInt that doesn't fit")
+	} else if _, ok := err.(*v8.UnmarshalTypeError); !ok {
+		t.Errorf("expected an UnmarshalTypeError, got %T", err)
+	}
+}
+
+func TestAsAndGet(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("({name: 'foo', tags: ['a', 'b']})", "")
+	fatalIf(t, err)
+	obj, err := val.AsObject()
+	fatalIf(t, err)
+
+	name, err := v8.Get[string](obj, "name")
+	fatalIf(t, err)
+	tags, err := v8.Get[[]string](obj, "tags")
+	fatalIf(t, err)
+	if name != "foo" || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("unexpected values %q, %v", name, tags)
+	}
+	if _, err := v8.Get[int64](obj, "name"); err == nil {
+		t.Error("expected an error getting a string as an int64")
+	} else if _, ok := err.(*v8.UnmarshalTypeError); !ok {
+		t.Errorf("expected an UnmarshalTypeError, got %T", err)
+	}
+
+	num, err := ctx.RunScript("6 * 7", "")
+	fatalIf(t, err)
+	n, err := v8.As[int64](num)
+	fatalIf(t, err)
+	if n != 42 {
+		t.Errorf("expected 42, got %d", n)
+	}
+	if _, err := v8.As[bool](val); err == nil {
+		t.Error("expected an error converting an object to a bool")
+	}
+}
+
 func TestObjectHas(t *testing.T) {
 	t.Parallel()
 
@@ -268,6 +787,150 @@ func TestObjectDelete(t *testing.T) {
 
 }
 
+func TestObjectOwnProperty(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		Object.prototype.isAdmin = true; // (prototype pollution)
+		({name: "alice", nothing: undefined})`, "")
+	fatalIf(t, err)
+	obj, _ := val.AsObject()
+
+	if !obj.Has("isAdmin") {
+		t.Error("expected Has to see the inherited property")
+	}
+	for key, want := range map[string]bool{"name": true, "nothing": true, "isAdmin": false} {
+		has, err := obj.HasOwnProperty(key)
+		fatalIf(t, err)
+		if has != want {
+			t.Errorf("HasOwnProperty(%q) = %v, want %v", key, has, want)
+		}
+	}
+
+	name, ok, err := obj.GetOwnProperty("name")
+	fatalIf(t, err)
+	if !ok || name.String() != "alice" {
+		t.Errorf("unexpected own property: %v, %v", name, ok)
+	}
+	admin, ok, err := obj.GetOwnProperty("isAdmin")
+	fatalIf(t, err)
+	if ok || !admin.IsUndefined() {
+		t.Errorf("expected no own isAdmin property, got %v, %v", admin, ok)
+	}
+	_, ok, err = obj.GetOwnProperty("nothing")
+	fatalIf(t, err)
+	if !ok {
+		t.Error("expected an own property whose value is undefined")
+	}
+
+	deleted, err := obj.DeleteOwnProperty("isAdmin")
+	fatalIf(t, err)
+	if deleted || !obj.Has("isAdmin") {
+		t.Error("expected the inherited property to be left alone")
+	}
+	deleted, err = obj.DeleteOwnProperty("name")
+	fatalIf(t, err)
+	if !deleted || obj.Has("name") {
+		t.Error("expected the own property to be deleted")
+	}
+
+	val, err = ctx.RunScript(`new Proxy({}, {
+		has() { throw new Error("has") },
+		getOwnPropertyDescriptor() { throw new Error("gopd") },
+		deleteProperty() { throw new Error("delete") },
+	})`, "")
+	fatalIf(t, err)
+	proxy, _ := val.AsObject()
+	if _, err := proxy.HasOwnProperty("x"); err == nil {
+		t.Error("expected HasOwnProperty to return the trap's error")
+	}
+	if _, _, err := proxy.GetOwnProperty("x"); err == nil {
+		t.Error("expected GetOwnProperty to return the trap's error")
+	}
+	if _, err := proxy.DeleteOwnProperty("x"); err == nil {
+		t.Error("expected DeleteOwnProperty to return the trap's error")
+	}
+}
+
+func TestObjectProperties(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		const big = {};
+		for (let i = 0; i < 150; i++) big["k" + i] = i;
+		Object.defineProperty(big, "hidden", {value: 1, enumerable: false});
+		big[Symbol("sym")] = 2;
+		big["héllo"] = "wörld";
+		big[7] = "seven";
+		big`, "")
+	fatalIf(t, err)
+	obj, _ := val.AsObject()
+
+	props, errFn := obj.Properties()
+	var keys []string
+	props(func(key string, value *v8.Value) bool {
+		keys = append(keys, key)
+		switch key {
+		case "k149":
+			if value.Int32() != 149 {
+				t.Errorf("unexpected value for %s: %v", key, value)
+			}
+		case "héllo":
+			if value.String() != "wörld" {
+				t.Errorf("unexpected value for %s: %v", key, value)
+			}
+		}
+		return true
+	})
+	fatalIf(t, errFn())
+	if len(keys) != 152 || keys[0] != "7" || keys[1] != "k0" || keys[151] != "héllo" {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	count := 0
+	props(func(key string, value *v8.Value) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("expected iteration to stop after 3 properties, got %d", count)
+	}
+
+	val, err = ctx.RunScript(`({a: 1, get b() { delete this.c; return 2 }, c: 3, d: 4})`, "")
+	fatalIf(t, err)
+	obj, _ = val.AsObject()
+	props, errFn = obj.Properties()
+	keys = nil
+	props(func(key string, value *v8.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	fatalIf(t, errFn())
+	if strings.Join(keys, ",") != "a,b,d" {
+		t.Errorf("expected deleted property to be skipped, got %v", keys)
+	}
+
+	val, err = ctx.RunScript(`({a: 1, get b() { throw new Error("oops") }})`, "")
+	fatalIf(t, err)
+	obj, _ = val.AsObject()
+	props, errFn = obj.Properties()
+	props(func(key string, value *v8.Value) bool {
+		t.Errorf("unexpected property %q before the getter threw", key)
+		return true
+	})
+	if err := errFn(); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("expected the getter's error, got %v", err)
+	}
+}
+
 func ExampleObject_global() {
 	iso := v8.NewIsolate()
 	defer iso.Dispose()
@@ -0,0 +1,124 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"runtime/cgo"
+)
+
+// Persistent is a handle to a JS value that, unlike a Value, isn't tied to the Context it
+// came from: it stays valid after that Context is closed or the Value's scope ends, until
+// it's released or the Isolate is disposed. It can be made weak, so that it no longer keeps
+// the value alive, and calls back when the value is garbage collected.
+type Persistent struct {
+	ptr      C.PersistentPtr
+	iso      *Isolate
+	callback func()     // Called when a weak handle's value is garbage collected
+	handle   cgo.Handle // Handle to this Persistent while it is weak
+}
+
+// NewPersistent creates a Persistent handle to a value.
+func NewPersistent(val Valuer) *Persistent {
+	v := val.value()
+	p := &Persistent{
+		ptr: C.NewPersistent(v.valuePtr()),
+		iso: v.ctx.iso,
+	}
+	p.iso.persistentsMutex.Lock()
+	if p.iso.persistents == nil {
+		p.iso.persistents = make(map[*Persistent]struct{})
+	}
+	p.iso.persistents[p] = struct{}{}
+	p.iso.persistentsMutex.Unlock()
+	return p
+}
+
+// Value returns the value in the given Context, which must belong to the same Isolate,
+// or nil if the handle has been released or its weak value has been garbage collected.
+func (p *Persistent) Value(ctx *Context) *Value {
+	if p.ptr == nil {
+		return nil
+	}
+	if ctx.iso != p.iso {
+		panic("v8go: Persistent used with a Context of a different Isolate")
+	}
+	return &Value{C.PersistentGet(p.ptr, ctx.ptr), ctx}
+}
+
+// IsEmpty returns true if the handle has been released or its weak value has been
+// garbage collected.
+func (p *Persistent) IsEmpty() bool {
+	return p.ptr == nil
+}
+
+// MakeWeak makes the handle weak, so that it doesn't keep its value alive. When the value
+// is garbage collected the handle becomes empty and the callback, if not nil, is called;
+// use it to release Go resources tied to the value. The callback is called during garbage
+// collection, so it must not use V8 in any way. Callbacks of handles that are still weak
+// when the Isolate is disposed are called then.
+//
+// Note that Values also keep their values alive, until their Context is closed or the
+// scope they were created in ends; see Context.WithTemporaryValues.
+func (p *Persistent) MakeWeak(callback func()) {
+	if p.ptr == nil {
+		return
+	}
+	p.callback = callback
+	if p.handle == 0 {
+		p.handle = cgo.NewHandle(p)
+		C.PersistentMakeWeak(p.ptr, C.uintptr_t(p.handle))
+	}
+}
+
+// Release releases the handle immediately, without calling its weak callback.
+func (p *Persistent) Release() {
+	if p.ptr == nil {
+		return
+	}
+	p.untrack()
+	C.PersistentRelease(p.ptr)
+	p.ptr = nil
+	if p.handle != 0 {
+		p.handle.Delete()
+		p.handle = 0
+	}
+}
+
+func (p *Persistent) untrack() {
+	p.iso.persistentsMutex.Lock()
+	delete(p.iso.persistents, p)
+	p.iso.persistentsMutex.Unlock()
+}
+
+// releasePersistents releases all the Isolate's handles before it is disposed, calling the
+// callbacks of weak ones.
+func (i *Isolate) releasePersistents() {
+	i.persistentsMutex.Lock()
+	persistents := i.persistents
+	i.persistents = nil
+	i.persistentsMutex.Unlock()
+	for p := range persistents {
+		weak, callback := p.handle != 0, p.callback
+		p.Release()
+		if weak && callback != nil {
+			callback()
+		}
+	}
+}
+
+//export goPersistentCollected
+func goPersistentCollected(handle C.uintptr_t) {
+	h := cgo.Handle(handle)
+	p := h.Value().(*Persistent)
+	h.Delete()
+	p.handle = 0
+	p.ptr = nil // (the C++ caller frees it)
+	p.untrack()
+	if p.callback != nil {
+		p.callback()
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestPersistent(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	ctx := v8.NewContext(iso)
+	val, err := ctx.RunScript("({name: 'kept'})", "")
+	fatalIf(t, err)
+	p := v8.NewPersistent(val)
+	ctx.Close()
+
+	// The handle outlives the Context it came from.
+	ctx2 := v8.NewContext(iso)
+	defer ctx2.Close()
+	if p.IsEmpty() {
+		t.Fatal("expected the handle not to be empty")
+	}
+	obj, err := p.Value(ctx2).AsObject()
+	fatalIf(t, err)
+	if name, _ := obj.Get("name"); name.String() != "kept" {
+		t.Errorf("unexpected name %q", name)
+	}
+
+	p.Release()
+	p.Release()
+	if !p.IsEmpty() || p.Value(ctx2) != nil {
+		t.Error("expected the released handle to be empty")
+	}
+}
+
+func TestPersistentWeak(t *testing.T) {
+	fatalIf(t, v8.SetFlagsChecked("--expose-gc"))
+	iso := v8.NewIsolate()
+	ctx := v8.NewContext(iso)
+
+	collected := 0
+	var weak, strong *v8.Persistent
+	ctx.WithTemporaryValues(func() {
+		val, err := ctx.RunScript("({})", "")
+		fatalIf(t, err)
+		weak = v8.NewPersistent(val)
+		weak.MakeWeak(func() { collected++ })
+		strong = v8.NewPersistent(val)
+	})
+
+	// The strong handle keeps the object alive.
+	_, err := ctx.RunScript("gc()", "")
+	fatalIf(t, err)
+	if collected != 0 || weak.IsEmpty() {
+		t.Fatal("expected the object not to be collected yet")
+	}
+
+	strong.Release()
+	_, err = ctx.RunScript("gc()", "")
+	fatalIf(t, err)
+	if collected != 1 || !weak.IsEmpty() {
+		t.Errorf("expected the object to be collected; callback called %d times", collected)
+	}
+
+	// Pending weak callbacks are called when the Isolate is disposed.
+	val, err := ctx.RunScript("({})", "")
+	fatalIf(t, err)
+	v8.NewPersistent(val).MakeWeak(func() { collected++ })
+	ctx.Close()
+	iso.Dispose()
+	if collected != 2 {
+		t.Errorf("expected the callback to be called on Dispose; called %d times", collected-1)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// IsolatePoolOptions are the options passed to NewIsolatePool.
+type IsolatePoolOptions struct {
+	// MaxIsolates is the maximum number of Isolates that can be checked out at once; Get
+	// waits for one to be returned if that many are. It must be positive.
+	MaxIsolates int
+
+	// Warm is the number of Isolates created by NewIsolatePool, so that the first calls
+	// to Get don't have to wait for them to be created. It is limited to MaxIsolates.
+	Warm int
+
+	// InitialHeap and MaxHeap are passed to NewIsolateWith to create each Isolate.
+	InitialHeap, MaxHeap uint64
+
+	// Snapshot, if not nil, is a snapshot created by CreateSnapshot to create each
+	// Isolate from, with NewIsolateFromSnapshot.
+	Snapshot []byte
+
+	// Callbacks implement the functions of a Snapshot created by
+	// CreateSnapshotWithFunctions, in the same order.
+	Callbacks []FunctionCallback
+
+	// Setup, if not nil, is called with each new Isolate before it is first checked out,
+	// e.g. to compile scripts that all users of the pool will run. If it returns an error,
+	// the Isolate is disposed and the error is returned by NewIsolatePool or Get.
+	Setup func(*Isolate) error
+
+	// MaxUses, if positive, is the number of times an Isolate can be checked out before
+	// it's disposed of when returned, instead of being reused.
+	MaxUses int
+
+	// MaxHeapUsed, if positive, is the heap size in bytes (see HeapStatistics.UsedHeapSize)
+	// above which a returned Isolate is disposed of instead of being reused.
+	MaxHeapUsed uint64
+}
+
+// IsolatePool keeps a set of Isolates that goroutines check out with Get and return with
+// Put, so that they don't pay for creating and setting up an Isolate each time, and so
+// that no more than a maximum number are in use at once. Isolates are disposed of
+// instead of being reused according to the pool's options. It is safe for concurrent use.
+type IsolatePool struct {
+	opts  IsolatePoolOptions
+	slots chan struct{} // Holds a token for each Isolate checked out
+
+	mutex  sync.Mutex
+	idle   []pooledIsolate  // Isolates waiting to be checked out, most recent last
+	out    map[*Isolate]int // Checked-out Isolates, and the times each has been checked out
+	closed bool
+}
+
+type pooledIsolate struct {
+	iso  *Isolate
+	uses int
+}
+
+// ErrPoolClosed is returned by IsolatePool.Get after the pool has been closed.
+var ErrPoolClosed = errors.New("v8go: IsolatePool is closed")
+
+// NewIsolatePool creates an IsolatePool, creating opts.Warm Isolates up front.
+func NewIsolatePool(opts IsolatePoolOptions) (*IsolatePool, error) {
+	if opts.MaxIsolates <= 0 {
+		return nil, errors.New("v8go: IsolatePoolOptions.MaxIsolates must be positive")
+	}
+	p := &IsolatePool{
+		opts:  opts,
+		slots: make(chan struct{}, opts.MaxIsolates),
+		out:   map[*Isolate]int{},
+	}
+	for i := 0; i < opts.Warm && i < opts.MaxIsolates; i++ {
+		iso, err := p.newIsolate()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.idle = append(p.idle, pooledIsolate{iso, 0})
+	}
+	return p, nil
+}
+
+func (p *IsolatePool) newIsolate() (*Isolate, error) {
+	var iso *Isolate
+	if p.opts.Snapshot != nil {
+		var err error
+		if iso, err = NewIsolateFromSnapshot(p.opts.Snapshot, p.opts.InitialHeap, p.opts.MaxHeap,
+			p.opts.Callbacks...); err != nil {
+			return nil, err
+		}
+	} else {
+		iso = NewIsolateWith(p.opts.InitialHeap, p.opts.MaxHeap)
+	}
+	if p.opts.Setup != nil {
+		if err := p.opts.Setup(iso); err != nil {
+			iso.Dispose()
+			return nil, err
+		}
+	}
+	return iso, nil
+}
+
+// Get checks out an Isolate, which must be returned with Put when done. It reuses an idle
+// Isolate if there is one, or else creates one, waiting first if MaxIsolates are already
+// checked out. It returns ctx's error if ctx is done before an Isolate is available.
+func (p *IsolatePool) Get(ctx context.Context) (*Isolate, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		<-p.slots
+		return nil, ErrPoolClosed
+	}
+	var pi pooledIsolate
+	if n := len(p.idle); n > 0 {
+		pi = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mutex.Unlock()
+
+	if pi.iso == nil {
+		iso, err := p.newIsolate()
+		if err != nil {
+			<-p.slots
+			return nil, err
+		}
+		pi.iso = iso
+	}
+
+	p.mutex.Lock()
+	p.out[pi.iso] = pi.uses + 1
+	p.mutex.Unlock()
+	return pi.iso, nil
+}
+
+// Put returns an Isolate checked out with Get to the pool, or disposes of it if the pool's
+// options say it shouldn't be reused, or if the pool has been closed. The caller must have
+// closed the Contexts it created in the Isolate, and must not use it afterwards.
+// It panics if iso isn't checked out from this pool.
+func (p *IsolatePool) Put(iso *Isolate) {
+	p.mutex.Lock()
+	uses, ok := p.out[iso]
+	delete(p.out, iso)
+	p.mutex.Unlock()
+	if !ok {
+		panic("v8go: IsolatePool.Put called with an Isolate not checked out from the pool")
+	}
+	defer func() { <-p.slots }()
+
+	if (p.opts.MaxUses > 0 && uses >= p.opts.MaxUses) || iso.IsExecutionTerminating() ||
+		(p.opts.MaxHeapUsed > 0 && iso.GetHeapStatistics().UsedHeapSize > p.opts.MaxHeapUsed) {
+		iso.Dispose()
+		return
+	}
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		iso.Dispose()
+		return
+	}
+	p.idle = append(p.idle, pooledIsolate{iso, uses})
+	p.mutex.Unlock()
+}
+
+// Close disposes of the pool's idle Isolates, and makes Put dispose of those checked out
+// when they are returned. Get then returns ErrPoolClosed.
+func (p *IsolatePool) Close() {
+	p.mutex.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mutex.Unlock()
+	for _, pi := range idle {
+		pi.iso.Dispose()
+	}
+}
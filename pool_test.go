@@ -0,0 +1,105 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestIsolatePool(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	setups := 0
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{
+		MaxIsolates: 2,
+		Warm:        1,
+		MaxUses:     2,
+		Setup: func(iso *v8.Isolate) error {
+			mutex.Lock()
+			setups++
+			mutex.Unlock()
+			return nil
+		},
+	})
+	fatalIf(t, err)
+	defer pool.Close()
+	if setups != 1 {
+		t.Errorf("expected 1 warm isolate, got %d", setups)
+	}
+
+	bg := context.Background()
+	iso1, err := pool.Get(bg)
+	fatalIf(t, err)
+	iso2, err := pool.Get(bg)
+	fatalIf(t, err)
+	if iso1 == iso2 || setups != 2 {
+		t.Fatalf("expected a second isolate to be created, got %d setups", setups)
+	}
+
+	// Both isolates are out, so Get waits.
+	timeout, cancel := context.WithTimeout(bg, 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(timeout); err != context.DeadlineExceeded {
+		t.Errorf("expected Get to time out, got %v", err)
+	}
+
+	// A script runs in a checked-out isolate.
+	ctx := v8.NewContext(iso1)
+	val, err := ctx.RunScript(`6 * 7`, "")
+	fatalIf(t, err)
+	if val.Integer() != 42 {
+		t.Errorf("unexpected result %v", val)
+	}
+	ctx.Close()
+
+	pool.Put(iso1)
+	again, err := pool.Get(bg)
+	fatalIf(t, err)
+	if again != iso1 {
+		t.Error("expected the returned isolate to be reused")
+	}
+	pool.Put(again) // (its second use, so it's disposed of)
+	iso3, err := pool.Get(bg)
+	fatalIf(t, err)
+	if iso3 == iso1 || setups != 3 {
+		t.Errorf("expected a used-up isolate to be replaced, got %d setups", setups)
+	}
+
+	pool.Put(iso2)
+	pool.Close()
+	pool.Put(iso3)
+	if _, err := pool.Get(bg); err != v8.ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic returning an isolate twice")
+		}
+	}()
+	pool.Put(iso3)
+}
+
+func TestIsolatePoolErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := v8.NewIsolatePool(v8.IsolatePoolOptions{}); err == nil {
+		t.Error("expected an error without MaxIsolates")
+	}
+	setupErr := errors.New("setup failed")
+	if _, err := v8.NewIsolatePool(v8.IsolatePoolOptions{
+		MaxIsolates: 1,
+		Warm:        1,
+		Setup:       func(*v8.Isolate) error { return setupErr },
+	}); err != setupErr {
+		t.Errorf("expected the setup error, got %v", err)
+	}
+}
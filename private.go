@@ -0,0 +1,90 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static PrivatePtr NewPrivateGo(IsolatePtr iso, _GoString_ name) {
+	return NewPrivate(iso, _GoStringPtr(name), _GoStringLen(name)); }
+*/
+import "C"
+import (
+	"errors"
+	"runtime"
+)
+
+// Private is a key for private properties, which Go code can attach to any object with
+// Object.SetPrivate. Unlike properties keyed by strings or symbols, private properties are
+// invisible to JavaScript, which has no way to read, change or enumerate them.
+// A Private can be used with the objects of any Context of the Isolate that created it.
+type Private struct {
+	ptr C.PrivatePtr
+	iso *Isolate
+}
+
+// NewPrivate creates a new, unique private key. The name is only used for debugging.
+func NewPrivate(iso *Isolate, name string) *Private {
+	p := &Private{
+		ptr: C.NewPrivateGo(iso.ptr, name),
+		iso: iso,
+	}
+	runtime.SetFinalizer(p, (*Private).finalizer)
+	return p
+}
+
+func (p *Private) finalizer() {
+	// As with templates, the V8 handle itself is cleaned up when the isolate is disposed.
+	C.PrivateFreeWrapper(p.ptr)
+	p.ptr = nil
+}
+
+func (o *Object) checkPrivate(key *Private) {
+	if key.iso != o.ctx.iso {
+		panic("v8go: Private used with an Object of a different Isolate")
+	}
+}
+
+// SetPrivate sets the value of the object's private property with the given key.
+// The value may be any Go type that can be passed to NewValue.
+func (o *Object) SetPrivate(key *Private, val interface{}) error {
+	o.checkPrivate(key)
+	value, err := o.ctx.NewValue(val)
+	if err != nil {
+		return err
+	}
+	ok := C.ObjectSetPrivate(o.valuePtr(), key.ptr, value.valuePtr()) != 0
+	runtime.KeepAlive(key)
+	if !ok {
+		return errors.New("v8go: unable to set private property")
+	}
+	return nil
+}
+
+// GetPrivate returns the value of the object's private property with the given key,
+// or `undefined` if it has none.
+func (o *Object) GetPrivate(key *Private) (*Value, error) {
+	o.checkPrivate(key)
+	rtn := C.ObjectGetPrivate(o.valuePtr(), key.ptr)
+	runtime.KeepAlive(key)
+	return valueResult(o.ctx, rtn)
+}
+
+// HasPrivate returns true if the object has a private property with the given key.
+func (o *Object) HasPrivate(key *Private) bool {
+	o.checkPrivate(key)
+	has := C.ObjectHasPrivate(o.valuePtr(), key.ptr) != 0
+	runtime.KeepAlive(key)
+	return has
+}
+
+// DeletePrivate removes the object's private property with the given key, returning
+// true if successful.
+func (o *Object) DeletePrivate(key *Private) bool {
+	o.checkPrivate(key)
+	deleted := C.ObjectDeletePrivate(o.valuePtr(), key.ptr) != 0
+	runtime.KeepAlive(key)
+	return deleted
+}
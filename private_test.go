@@ -0,0 +1,82 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestPrivate(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	key := v8.NewPrivate(iso, "docID")
+	other := v8.NewPrivate(iso, "docID")
+
+	val, err := ctx.RunScript("globalThis.obj = {a: 1}", "")
+	fatalIf(t, err)
+	obj, err := val.AsObject()
+	fatalIf(t, err)
+
+	if obj.HasPrivate(key) {
+		t.Error("expected no private property")
+	}
+	if v, err := obj.GetPrivate(key); err != nil || !v.IsUndefined() {
+		t.Errorf("expected undefined, got %v, %v", v, err)
+	}
+	fatalIf(t, obj.SetPrivate(key, "doc1"))
+	if !obj.HasPrivate(key) || obj.HasPrivate(other) {
+		t.Error("expected only the key's private property")
+	}
+	if v, err := obj.GetPrivate(key); err != nil || v.String() != "doc1" {
+		t.Errorf("unexpected private value %v, %v", v, err)
+	}
+
+	// Private properties are invisible to JS.
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"JSON.stringify(obj)", `{"a":1}`},
+		{"Reflect.ownKeys(obj).length", "1"},
+		{"Object.getOwnPropertySymbols(obj).length", "0"},
+		{"obj.docID", "undefined"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := val.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+
+	// The key works with objects of other Contexts of the Isolate.
+	ctx2 := v8.NewContext(iso)
+	defer ctx2.Close()
+	obj2 := ctx2.NewObject()
+	fatalIf(t, obj2.SetPrivate(key, int32(2)))
+	if v, _ := obj2.GetPrivate(key); v.Int32() != 2 {
+		t.Errorf("unexpected private value %v", v)
+	}
+
+	if !obj.DeletePrivate(key) || obj.HasPrivate(key) {
+		t.Error("expected the private property to be deleted")
+	}
+	if err := obj.SetPrivate(key, nil); err == nil {
+		t.Error("expected an error for a nil value")
+	}
+
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	if recoverPanic(func() { obj.SetPrivate(v8.NewPrivate(iso2, "x"), "y") }) == nil {
+		t.Error("expected a panic for a Private of another Isolate")
+	}
+}
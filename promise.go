@@ -9,6 +9,7 @@ package v8go
 import "C"
 import (
 	"errors"
+	"time"
 )
 
 // PromiseState is the state of the Promise.
@@ -125,3 +126,95 @@ func (p *Promise) Catch(cb FunctionCallback) *Promise {
 	}
 	return &Promise{obj}
 }
+
+// Finally invokes the given function, with no arguments, when the promise is settled,
+// whether it's fulfilled or rejected. The returned Promise settles the same way as this one,
+// unless the callback throws, in which case it's rejected with the exception. Unlike
+// `promise.finally(...)` in JS, it isn't affected by changes to `Promise.prototype`.
+// See Then for other details.
+func (p *Promise) Finally(cb FunctionCallback) (*Promise, error) {
+	cbID := p.ctx.iso.registerCallback(cb)
+	rtn := C.PromiseFinally(p.valuePtr(), C.int(cbID))
+	obj, err := objectResult(p.ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &Promise{obj}, nil
+}
+
+// AwaitOptions are the options passed to Context.Await.
+type AwaitOptions struct {
+	// Pump, if not nil, is called whenever the promise is still pending after the
+	// microtasks have run, to run other work that may settle it, such as an embedder's
+	// timer or I/O callbacks. It may block until it has run something. It returns false
+	// if it has nothing left to run, in which case the promise can no longer settle.
+	Pump func() bool
+
+	// Timeout is the maximum wall-clock time to wait for the promise. Zero means no limit.
+	Timeout time.Duration
+}
+
+// ErrAwaitTimeout is returned by Context.Await when the promise did not settle within
+// AwaitOptions.Timeout.
+var ErrAwaitTimeout = errors.New("v8go: timed out awaiting promise")
+
+// ErrPromiseStalled is returned by Context.Await when the promise is pending and nothing
+// is left that could settle it.
+var ErrPromiseStalled = errors.New("v8go: promise is pending with nothing left to settle it")
+
+// Await runs microtasks, and the Pump option if given, until the promise settles. It also
+// waits for async calls (see NewAsyncFunctionTemplate) to finish. If the promise is
+// fulfilled it returns its value; if it's rejected, the error is a JSError whose message
+// is the string form of the rejection reason, with its stack trace if it has one.
+func (c *Context) Await(p *Promise, opts AwaitOptions) (*Value, error) {
+	var timeout <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		c.PerformMicrotaskCheckpoint()
+		switch p.State() {
+		case Fulfilled:
+			return p.Result(), nil
+		case Rejected:
+			return nil, rejectionError(p.Result())
+		}
+
+		select {
+		case <-timeout:
+			return nil, ErrAwaitTimeout
+		default:
+		}
+		if opts.Pump != nil && opts.Pump() {
+			continue
+		}
+		c.iso.asyncMutex.Lock()
+		pending := c.iso.asyncPending
+		c.iso.asyncMutex.Unlock()
+		if pending == 0 {
+			return nil, ErrPromiseStalled
+		}
+		select {
+		case <-c.iso.asyncReady:
+		case <-timeout:
+			return nil, ErrAwaitTimeout
+		}
+	}
+}
+
+// rejectionError converts a promise's rejection reason to a JSError.
+func rejectionError(reason *Value) error {
+	return &JSError{Message: reason.String(), StackTrace: errorStack(reason)}
+}
+
+// errorStack returns the `stack` property of an Error, or "" if it has none.
+func errorStack(val *Value) string {
+	if val.IsObject() {
+		if stack, _ := val.Object().Get("stack"); stack != nil && stack.IsString() {
+			return stack.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// PromiseHooks are Go functions called at each step in the life of the promises created in
+// a Context, e.g. to track async context, account for async resources per request, or
+// stitch together async stack traces. Any of them may be nil. They are called
+// synchronously from V8, so they must not run scripts or create promises.
+type PromiseHooks struct {
+	// Init is called when a promise is created. parent is the promise it was chained
+	// from by `then`, `await`, etc., or nil.
+	Init func(promise, parent *Promise)
+	// Resolve is called when a promise is resolved or rejected.
+	Resolve func(promise *Promise)
+	// Before is called before a reaction to a promise, such as a `then` callback or the
+	// continuation of an `await`, runs. promise is the one the reaction belongs to.
+	Before func(promise *Promise)
+	// After is called after a reaction to a promise has run.
+	After func(promise *Promise)
+}
+
+// SetPromiseHooks sets the hooks called for the promises created in this Context.
+// Passing nil removes them. While any Context of an Isolate has hooks, all its promise
+// operations are slower.
+func (c *Context) SetPromiseHooks(hooks *PromiseHooks) {
+	iso := c.iso
+	was := iso.promiseHookCount
+	if c.promiseHooks != nil {
+		iso.promiseHookCount--
+	}
+	c.promiseHooks = hooks
+	if hooks != nil {
+		iso.promiseHookCount++
+	}
+	if (was == 0) != (iso.promiseHookCount == 0) {
+		C.IsolateSetPromiseHook(iso.ptr, boolToCBool(iso.promiseHookCount > 0))
+	}
+}
+
+//export goPromiseHook
+func goPromiseHook(ctxHandle C.uintptr_t, kind C.PromiseHookKind, promise C.ValueRef, parent C.ValueRef, hasParent C.Bool) {
+	ctx := contextFromHandle(ctxHandle)
+	hooks := ctx.promiseHooks
+	if hooks == nil {
+		return
+	}
+	p := &Promise{&Object{&Value{promise, ctx}}}
+	switch kind {
+	case C.PromiseInit_hook:
+		if hooks.Init != nil {
+			var pp *Promise
+			if hasParent != 0 {
+				pp = &Promise{&Object{&Value{parent, ctx}}}
+			}
+			hooks.Init(p, pp)
+		}
+	case C.PromiseResolve_hook:
+		if hooks.Resolve != nil {
+			hooks.Resolve(p)
+		}
+	case C.PromiseBefore_hook:
+		if hooks.Before != nil {
+			hooks.Before(p)
+		}
+	case C.PromiseAfter_hook:
+		if hooks.After != nil {
+			hooks.After(p)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// PromiseRejectEvent is the kind of event reported to a PromiseRejectCallback.
+type PromiseRejectEvent int
+
+const (
+	// PromiseRejectWithNoHandler: the promise was rejected and has no rejection handler.
+	PromiseRejectWithNoHandler PromiseRejectEvent = iota
+	// PromiseHandlerAddedAfterReject: a handler was attached to a promise that was
+	// previously reported with PromiseRejectWithNoHandler.
+	PromiseHandlerAddedAfterReject
+	// PromiseRejectAfterResolved: a promise's resolve function was called and then its
+	// reject function, which has no effect.
+	PromiseRejectAfterResolved
+	// PromiseResolveAfterResolved: a promise's resolve function was called more than once,
+	// which has no effect.
+	PromiseResolveAfterResolved
+)
+
+// PromiseRejectMessage describes an event reported to a PromiseRejectCallback.
+type PromiseRejectMessage struct {
+	Event   PromiseRejectEvent
+	Promise *Promise
+	// Reason is the value the promise was rejected or resolved with, or nil for a
+	// PromiseHandlerAddedAfterReject event.
+	Reason *Value
+	// StackTrace is the `stack` property of Reason, if it is an Error.
+	StackTrace string
+}
+
+// PromiseRejectCallback is called synchronously, while a promise is being rejected or
+// having a handler attached, so it must not run scripts. A promise is often rejected before
+// a handler is attached later in the same microtask checkpoint, so to find rejections that
+// are really unhandled, record the promises of PromiseRejectWithNoHandler events, forget them
+// on PromiseHandlerAddedAfterReject, and report those left once the microtasks have run.
+type PromiseRejectCallback func(msg PromiseRejectMessage)
+
+// SetPromiseRejectCallback sets the callback that is told about promise rejections that
+// have no handler, handlers attached to them later, and redundant resolutions of promises
+// in this Isolate's Contexts. Passing nil removes the callback.
+func (i *Isolate) SetPromiseRejectCallback(cb PromiseRejectCallback) {
+	i.rejectCallback = cb
+	C.IsolateSetPromiseRejectCallback(i.ptr, boolToCBool(cb != nil))
+}
+
+//export goPromiseRejectCallback
+func goPromiseRejectCallback(ctxHandle C.uintptr_t, event C.int, promise C.ValueRef, reason C.ValueRef) {
+	ctx := contextFromHandle(ctxHandle)
+	cb := ctx.iso.rejectCallback
+	if cb == nil {
+		return
+	}
+	msg := PromiseRejectMessage{
+		Event:   PromiseRejectEvent(event),
+		Promise: &Promise{&Object{&Value{promise, ctx}}},
+	}
+	if msg.Event != PromiseHandlerAddedAfterReject {
+		msg.Reason = &Value{reason, ctx}
+		msg.StackTrace = errorStack(msg.Reason)
+	}
+	cb(msg)
+}
@@ -5,7 +5,10 @@
 package v8go_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	v8 "github.com/couchbasedeps/v8go"
 )
@@ -133,3 +136,234 @@ func TestPromiseThenPanic(t *testing.T) {
 		t.Errorf("expected a panic")
 	})
 }
+
+func TestPromiseFinally(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	for _, fulfill := range []bool{true, false} {
+		res, _ := v8.NewPromiseResolver(ctx)
+		var calls int
+		var nArgs int
+		next, err := res.GetPromise().Finally(func(info *v8.FunctionCallbackInfo) *v8.Value {
+			calls++
+			nArgs = len(info.Args())
+			return nil
+		})
+		fatalIf(t, err)
+		val, _ := v8.NewValue(iso, "settled")
+		if fulfill {
+			res.Resolve(val)
+		} else {
+			res.Reject(val)
+		}
+		ctx.PerformMicrotaskCheckpoint()
+		if calls != 1 || nArgs != 0 {
+			t.Errorf("expected one call with no arguments, got %d calls with %d arguments", calls, nArgs)
+		}
+		want := v8.Fulfilled
+		if !fulfill {
+			want = v8.Rejected
+		}
+		if next.State() != want || next.Result().String() != "settled" {
+			t.Errorf("expected the chained promise to settle like the original, got %v %q", next.State(), next.Result())
+		}
+	}
+
+	res, _ := v8.NewPromiseResolver(ctx)
+	next, err := res.GetPromise().Finally(func(info *v8.FunctionCallbackInfo) *v8.Value {
+		msg, _ := v8.NewValue(iso, "cleanup failed")
+		return iso.ThrowException(msg)
+	})
+	fatalIf(t, err)
+	res.Resolve(v8.Undefined(iso))
+	ctx.PerformMicrotaskCheckpoint()
+	if next.State() != v8.Rejected || next.Result().String() != "cleanup failed" {
+		t.Errorf("expected the callback's exception to reject the chained promise, got %v %q", next.State(), next.Result())
+	}
+
+	// Scripts can't change what Finally does.
+	_, err = ctx.RunScript(`Promise.prototype.finally = () => { throw new Error('hijacked') }`, "")
+	fatalIf(t, err)
+	res, _ = v8.NewPromiseResolver(ctx)
+	called := false
+	next, err = res.GetPromise().Finally(func(info *v8.FunctionCallbackInfo) *v8.Value {
+		called = true
+		return nil
+	})
+	fatalIf(t, err)
+	res.Resolve(v8.Undefined(iso))
+	ctx.PerformMicrotaskCheckpoint()
+	if !called || next.State() != v8.Fulfilled {
+		t.Errorf("expected the callback to be called and the promise fulfilled, got %v %v", called, next.State())
+	}
+}
+
+func TestContextAwait(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	await := func(script string, opts v8.AwaitOptions) (*v8.Value, error) {
+		val, err := ctx.RunScript(script, "await.js")
+		fatalIf(t, err)
+		prom, err := val.AsPromise()
+		fatalIf(t, err)
+		return ctx.Await(prom, opts)
+	}
+
+	val, err := await(`(async () => { await null; return 42 })()`, v8.AwaitOptions{})
+	fatalIf(t, err)
+	if val.Integer() != 42 {
+		t.Errorf("expected 42, got %v", val)
+	}
+
+	_, err = await(`(async () => { throw new RangeError("too big") })()`, v8.AwaitOptions{})
+	var jsErr *v8.JSError
+	if !errors.As(err, &jsErr) || jsErr.Message != "RangeError: too big" || !strings.Contains(jsErr.StackTrace, "await.js") {
+		t.Errorf("expected the rejection as a JSError, got %#v", err)
+	}
+
+	if _, err = await(`new Promise(() => {})`, v8.AwaitOptions{}); err != v8.ErrPromiseStalled {
+		t.Errorf("expected ErrPromiseStalled, got %v", err)
+	}
+
+	// A pump that settles the promise on its third run, like a timer firing.
+	runs := 0
+	pump := func() bool {
+		runs++
+		if runs == 3 {
+			_, err := ctx.RunScript(`settle("pumped")`, "")
+			fatalIf(t, err)
+		}
+		return true
+	}
+	val, err = await(`new Promise(resolve => settle = resolve)`, v8.AwaitOptions{Pump: pump})
+	fatalIf(t, err)
+	if val.String() != "pumped" || runs != 3 {
+		t.Errorf("expected the pump to settle the promise, got %q after %d runs", val, runs)
+	}
+
+	_, err = await(`new Promise(() => {})`, v8.AwaitOptions{
+		Pump:    func() bool { time.Sleep(time.Millisecond); return true },
+		Timeout: 20 * time.Millisecond,
+	})
+	if err != v8.ErrAwaitTimeout {
+		t.Errorf("expected ErrAwaitTimeout, got %v", err)
+	}
+
+	fatalIf(t, ctx.RegisterAsyncFunction("later", func(args []interface{}) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "async", nil
+	}))
+	val, err = await(`later()`, v8.AwaitOptions{})
+	fatalIf(t, err)
+	if val.String() != "async" {
+		t.Errorf("expected the async call's result, got %q", val)
+	}
+}
+
+func TestPromiseRejectCallback(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	var msgs []v8.PromiseRejectMessage
+	iso.SetPromiseRejectCallback(func(msg v8.PromiseRejectMessage) {
+		msgs = append(msgs, msg)
+	})
+
+	_, err := ctx.RunScript(`
+		function fail() { throw new Error("oops") }
+		var p = (async () => fail())();
+		var handled = Promise.reject(1);
+		handled.catch(() => {});`, "reject.js")
+	fatalIf(t, err)
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(msgs))
+	}
+	if msgs[0].Event != v8.PromiseRejectWithNoHandler || msgs[0].Reason.String() != "Error: oops" ||
+		!strings.Contains(msgs[0].StackTrace, "at fail (reject.js") {
+		t.Errorf("unexpected first event %+v", msgs[0])
+	}
+	p, _ := ctx.Global().Get("p")
+	if !p.SameValue(msgs[0].Promise.Value) || msgs[0].Promise.State() != v8.Rejected {
+		t.Error("expected the first event to be for the rejected promise")
+	}
+	if msgs[1].Event != v8.PromiseRejectWithNoHandler || msgs[1].Reason.Integer() != 1 || msgs[1].StackTrace != "" {
+		t.Errorf("unexpected second event %+v", msgs[1])
+	}
+	if msgs[2].Event != v8.PromiseHandlerAddedAfterReject || msgs[2].Reason != nil ||
+		!msgs[2].Promise.SameValue(msgs[1].Promise.Value) {
+		t.Errorf("unexpected third event %+v", msgs[2])
+	}
+
+	msgs = nil
+	iso.SetPromiseRejectCallback(nil)
+	_, err = ctx.RunScript(`Promise.reject(2)`, "")
+	fatalIf(t, err)
+	if len(msgs) != 0 {
+		t.Errorf("expected no events after removing the callback, got %d", len(msgs))
+	}
+}
+
+func TestContextPromiseHooks(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	other := v8.NewContext(iso)
+	defer other.Close()
+
+	var events []string
+	var inits []*v8.Promise
+	var parents []*v8.Promise
+	ctx.SetPromiseHooks(&v8.PromiseHooks{
+		Init: func(promise, parent *v8.Promise) {
+			events = append(events, "init")
+			inits = append(inits, promise)
+			parents = append(parents, parent)
+		},
+		Resolve: func(*v8.Promise) { events = append(events, "resolve") },
+		Before:  func(*v8.Promise) { events = append(events, "before") },
+		After:   func(*v8.Promise) { events = append(events, "after") },
+	})
+
+	_, err := ctx.RunScript(`var a = Promise.resolve(1); var b = a.then(x => x + 1)`, "")
+	fatalIf(t, err)
+	if got := strings.Join(events, ","); got != "init,resolve,init,before,resolve,after" {
+		t.Errorf("unexpected events %s", got)
+	}
+	a, _ := ctx.Global().Get("a")
+	b, _ := ctx.Global().Get("b")
+	if len(inits) != 2 || !inits[0].SameValue(a) || parents[0] != nil ||
+		!inits[1].SameValue(b) || parents[1] == nil || !parents[1].SameValue(a) {
+		t.Error("expected b's parent to be a")
+	}
+
+	events = nil
+	_, err = other.RunScript(`Promise.resolve(1).then(() => {})`, "")
+	fatalIf(t, err)
+	if len(events) != 0 {
+		t.Errorf("expected no events for another Context's promises, got %v", events)
+	}
+
+	ctx.SetPromiseHooks(nil)
+	_, err = ctx.RunScript(`Promise.resolve(1)`, "")
+	fatalIf(t, err)
+	if len(events) != 0 {
+		t.Errorf("expected no events after removing the hooks, got %v", events)
+	}
+}
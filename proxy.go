@@ -0,0 +1,55 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+)
+
+// Proxy is a JavaScript Proxy object, a subtype of Object, whose operations are
+// intercepted by the functions of its handler object.
+type Proxy struct {
+	*Object
+}
+
+// NewProxy creates a Proxy for the target object, as `new Proxy(target, handler)` would in JS.
+func (c *Context) NewProxy(target, handler *Object) (*Proxy, error) {
+	rtn := C.NewProxy(c.ptr, target.valuePtr(), handler.valuePtr())
+	obj, err := objectResult(c, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &Proxy{obj}, nil
+}
+
+// AsProxy returns the value as a Proxy, or an error if it is not a Proxy.
+func (v *Value) AsProxy() (*Proxy, error) {
+	if !v.IsProxy() {
+		return nil, errors.New("v8go: value is not a Proxy")
+	}
+	return &Proxy{&Object{v}}, nil
+}
+
+// Target returns the object the proxy was created for, or null if it has been revoked.
+func (p *Proxy) Target() *Value {
+	return &Value{C.ProxyGetTarget(p.valuePtr()), p.ctx}
+}
+
+// Handler returns the proxy's handler object, or null if it has been revoked.
+func (p *Proxy) Handler() *Value {
+	return &Value{C.ProxyGetHandler(p.valuePtr()), p.ctx}
+}
+
+// IsRevoked returns true if the proxy has been revoked.
+func (p *Proxy) IsRevoked() bool {
+	return C.ProxyIsRevoked(p.valuePtr()) != 0
+}
+
+// Revoke revokes the proxy, after which any operation on it throws a TypeError.
+func (p *Proxy) Revoke() {
+	C.ProxyRevoke(p.valuePtr())
+}
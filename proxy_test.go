@@ -0,0 +1,59 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	targetVal, err := ctx.RunScript("({a: 1})", "")
+	fatalIf(t, err)
+	target, _ := targetVal.AsObject()
+	handlerVal, err := ctx.RunScript("({get: (t, key) => key in t ? t[key] : 'missing ' + key})", "")
+	fatalIf(t, err)
+	handler, _ := handlerVal.AsObject()
+
+	proxy, err := ctx.NewProxy(target, handler)
+	fatalIf(t, err)
+	if !proxy.IsProxy() {
+		t.Error("expected IsProxy to be true")
+	}
+	fatalIf(t, ctx.Global().Set("p", proxy))
+	val, err := ctx.RunScript("p.a + ', ' + p.b", "")
+	fatalIf(t, err)
+	if s := val.String(); s != "1, missing b" {
+		t.Errorf("unexpected result %q", s)
+	}
+
+	if !proxy.Target().SameValue(targetVal) || !proxy.Handler().SameValue(handlerVal) {
+		t.Error("expected the proxy's target and handler")
+	}
+
+	val, err = ctx.RunScript("p", "")
+	fatalIf(t, err)
+	proxy2, err := val.AsProxy()
+	fatalIf(t, err)
+	if proxy2.IsRevoked() {
+		t.Error("expected the proxy not to be revoked")
+	}
+	proxy2.Revoke()
+	if !proxy.IsRevoked() || !proxy.Target().IsNull() || !proxy.Handler().IsNull() {
+		t.Error("expected the proxy to be revoked")
+	}
+	if _, err := ctx.RunScript("p.a", ""); err == nil {
+		t.Error("expected an error using a revoked proxy")
+	}
+	if _, err := targetVal.AsProxy(); err == nil {
+		t.Error("expected an error for a non-Proxy")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static RtnValue NewRegExpGo(ContextPtr ctx, _GoString_ pattern, _GoString_ flags) {
+	return NewRegExp(ctx, _GoStringPtr(pattern), _GoStringLen(pattern),
+	                 _GoStringPtr(flags), _GoStringLen(flags)); }
+static RtnValue RegExpExecGo(ValuePtr ptr, _GoString_ subject) {
+	return RegExpExec(ptr, _GoStringPtr(subject), _GoStringLen(subject)); }
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// RegExp is a JavaScript regular expression object, a subtype of Object.
+type RegExp struct {
+	*Object
+}
+
+// RegExpMatch is the result of a successful RegExp.Exec.
+type RegExpMatch struct {
+	Index  int               // Index of the match in the string, in UTF-16 code units
+	Groups []string          // The matched string, then each capture group; "" if unmatched
+	Named  map[string]string // The named capture groups, if the RegExp has any
+}
+
+// NewRegExp creates a regular expression, as `new RegExp(pattern, flags)` would in JS.
+// flags is a string of the flags "dgimsuy". error will be of type `JSError` if the
+// pattern or flags are invalid.
+func (c *Context) NewRegExp(pattern, flags string) (*RegExp, error) {
+	rtn := C.NewRegExpGo(c.ptr, pattern, flags)
+	obj, err := objectResult(c, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &RegExp{obj}, nil
+}
+
+// AsRegExp returns the value as a RegExp, or an error if it is not a regular expression.
+func (v *Value) AsRegExp() (*RegExp, error) {
+	if !v.IsRegExp() {
+		return nil, errors.New("v8go: value is not a RegExp")
+	}
+	return &RegExp{&Object{v}}, nil
+}
+
+// Source returns the regular expression's pattern, like its `source` property.
+func (r *RegExp) Source() string {
+	rtn := C.RegExpSource(r.valuePtr())
+	defer C.free(unsafe.Pointer(rtn.data))
+	return C.GoStringN(rtn.data, rtn.length)
+}
+
+// Flags returns the regular expression's flags, like its `flags` property, e.g. "gi".
+func (r *RegExp) Flags() string {
+	bits := C.RegExpFlags(r.valuePtr())
+	var flags []byte
+	for _, f := range []struct {
+		bit  C.int
+		flag byte
+	}{
+		{1 << 7, 'd'}, // kHasIndices
+		{1 << 0, 'g'}, // kGlobal
+		{1 << 1, 'i'}, // kIgnoreCase
+		{1 << 2, 'm'}, // kMultiline
+		{1 << 5, 's'}, // kDotAll
+		{1 << 4, 'u'}, // kUnicode
+		{1 << 3, 'y'}, // kSticky
+	} {
+		if bits&f.bit != 0 {
+			flags = append(flags, f.flag)
+		}
+	}
+	return string(flags)
+}
+
+// Exec searches for a match in s, like the RegExp's `exec` method, returning nil if there
+// is none. As in JS, if the RegExp is global or sticky the search starts at its `lastIndex`
+// property, which is then updated.
+func (r *RegExp) Exec(s string) (*RegExpMatch, error) {
+	rtn := C.RegExpExecGo(r.valuePtr(), s)
+	result, err := valueResult(r.ctx, rtn)
+	if err != nil || result.IsNull() {
+		return nil, err
+	}
+	obj, err := result.AsObject()
+	if err != nil {
+		return nil, err
+	}
+	match := &RegExpMatch{}
+	if err := result.Unmarshal(&match.Groups); err != nil {
+		return nil, err
+	}
+	if err := obj.GetInto("index", &match.Index); err != nil {
+		return nil, err
+	}
+	if err := obj.GetInto("groups", &match.Named); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
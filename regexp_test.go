@@ -0,0 +1,75 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"reflect"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestRegExp(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	re, err := ctx.NewRegExp(`(?<year>\d{4})-(\d\d)(x)?`, "ig")
+	fatalIf(t, err)
+	if src := re.Source(); src != `(?<year>\d{4})-(\d\d)(x)?` {
+		t.Errorf("unexpected source %q", src)
+	}
+	if flags := re.Flags(); flags != "gi" {
+		t.Errorf("unexpected flags %q", flags)
+	}
+
+	match, err := re.Exec("in 2021-03 and 1999-12")
+	fatalIf(t, err)
+	expected := &v8.RegExpMatch{
+		Index:  3,
+		Groups: []string{"2021-03", "2021", "03", ""},
+		Named:  map[string]string{"year": "2021"},
+	}
+	if !reflect.DeepEqual(match, expected) {
+		t.Errorf("expected %+v, got %+v", expected, match)
+	}
+	// A global RegExp continues from lastIndex.
+	match, err = re.Exec("in 2021-03 and 1999-12")
+	fatalIf(t, err)
+	if match == nil || match.Index != 15 || match.Groups[1] != "1999" {
+		t.Errorf("unexpected second match %+v", match)
+	}
+	match, err = re.Exec("in 2021-03 and 1999-12")
+	fatalIf(t, err)
+	if match != nil {
+		t.Errorf("expected no match, got %+v", match)
+	}
+
+	// A RegExp created by a script.
+	val, err := ctx.RunScript("/a(b)?/y", "")
+	fatalIf(t, err)
+	re, err = val.AsRegExp()
+	fatalIf(t, err)
+	if re.Flags() != "y" || re.Source() != "a(b)?" {
+		t.Errorf("unexpected RegExp /%s/%s", re.Source(), re.Flags())
+	}
+	match, err = re.Exec("a")
+	fatalIf(t, err)
+	if match == nil || match.Named != nil || !reflect.DeepEqual(match.Groups, []string{"a", ""}) {
+		t.Errorf("unexpected match %+v", match)
+	}
+	if _, err := ctx.Global().AsRegExp(); err == nil {
+		t.Error("expected an error for a non-RegExp")
+	}
+
+	for _, tt := range []struct{ pattern, flags string }{{"(", ""}, {"a", "q"}, {"a", "gg"}} {
+		if _, err := ctx.NewRegExp(tt.pattern, tt.flags); err == nil {
+			t.Errorf("/%s/%s: expected an error", tt.pattern, tt.flags)
+		} else if _, ok := err.(*v8.JSError); !ok {
+			t.Errorf("/%s/%s: expected a JSError, got %T", tt.pattern, tt.flags, err)
+		}
+	}
+}
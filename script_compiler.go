@@ -4,17 +4,110 @@
 
 package v8go
 
+// #include <stdlib.h>
 // #include "v8go.h"
 import "C"
+import (
+	"io"
+	"unsafe"
+)
 
+// CompileMode controls how eagerly V8 compiles a script.
 type CompileMode C.int
 
 var (
+	// CompileModeDefault compiles the top-level code of a script up front, and each
+	// function lazily when it is first called. This gives the fastest startup.
 	CompileModeDefault = CompileMode(C.ScriptCompilerNoCompileOptions)
-	CompileModeEager   = CompileMode(C.ScriptCompilerEagerCompile)
+	// CompileModeEager compiles every function in a script up front. This is slower
+	// to start but avoids compilation pauses when the functions are first called,
+	// so it suits hot scripts whose functions are all expected to run.
+	CompileModeEager = CompileMode(C.ScriptCompilerEagerCompile)
 )
 
+// CompilerCachedData is a V8 code cache, as produced by UnboundScript.CreateCodeCache
+// or Module.CreateCodeCache.
+// Bytes may be persisted and passed back in CompileOptions.CachedData, even by a later
+// process, to skip parsing and compiling the same source again. Rejected is set by
+// Isolate.CompileUnboundScript if V8 could not use the cache, e.g. because it was
+// produced from a different source or by a different V8 version.
 type CompilerCachedData struct {
 	Bytes    []byte
 	Rejected bool
 }
+
+// ScriptOrigin describes where a script's source came from. It is used for the
+// locations in error messages and stack traces, and by debugging tools.
+type ScriptOrigin struct {
+	// ResourceName is the name of the script, a.k.a. its filename.
+	ResourceName string
+	// LineOffset and ColumnOffset are the (zero-based) position of the script within
+	// its resource, e.g. when it is a snippet embedded in a larger file.
+	LineOffset   int
+	ColumnOffset int
+	// SourceMapURL is the URL of the source map for the script, if any.
+	SourceMapURL string
+	// ScriptID is an embedder-defined identifier for the script; zero means none.
+	ScriptID int
+	// IsSharedCrossOrigin marks the script as allowed to expose its errors
+	// to scripts from other origins.
+	IsSharedCrossOrigin bool
+	// IsOpaque marks the script as opaque, so its details are hidden from errors.
+	IsOpaque bool
+}
+
+// newCScriptOrigin converts the origin for passing to C;
+// the result must be released with freeCScriptOrigin.
+func newCScriptOrigin(o ScriptOrigin) C.ScriptOriginData {
+	cOrigin := C.ScriptOriginData{
+		resourceName:        C.CString(o.ResourceName),
+		resourceNameLen:     C.int(len(o.ResourceName)),
+		lineOffset:          C.int(o.LineOffset),
+		columnOffset:        C.int(o.ColumnOffset),
+		scriptId:            C.int(o.ScriptID),
+		isSharedCrossOrigin: boolToCBool(o.IsSharedCrossOrigin),
+		isOpaque:            boolToCBool(o.IsOpaque),
+	}
+	if o.ScriptID == 0 {
+		cOrigin.scriptId = -1
+	}
+	if o.SourceMapURL != "" {
+		cOrigin.sourceMapUrl = C.CString(o.SourceMapURL)
+		cOrigin.sourceMapUrlLen = C.int(len(o.SourceMapURL))
+	}
+	return cOrigin
+}
+
+func freeCScriptOrigin(cOrigin C.ScriptOriginData) {
+	C.free(unsafe.Pointer(cOrigin.resourceName))
+	C.free(unsafe.Pointer(cOrigin.sourceMapUrl))
+}
+
+func boolToCBool(b bool) C.Bool {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+const kScriptStreamChunkSize = 64 * 1024
+
+// streamScript starts V8's streaming parser on a background thread and feeds it the
+// UTF-8 source read from r. On success the caller must compile the script with one
+// of the ScriptStreamer functions and then free the returned streamer.
+func streamScript(iso *Isolate, r io.Reader) (C.ScriptStreamerPtr, error) {
+	streamer := C.NewScriptStreamer(iso.ptr)
+	buf := make([]byte, kScriptStreamChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			C.ScriptStreamerPush(streamer, (*C.char)(unsafe.Pointer(&buf[0])), C.int(n))
+		}
+		if err == io.EOF {
+			return streamer, nil
+		} else if err != nil {
+			C.ScriptStreamerFree(streamer)
+			return nil, err
+		}
+	}
+}
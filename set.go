@@ -0,0 +1,86 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+)
+
+// Set is a JavaScript Set object, a subtype of Object.
+// Its Has and Delete methods operate on the set's elements; the object's properties
+// can still be accessed through its Object.
+type Set struct {
+	*Object
+}
+
+// NewSet creates a new, empty Set.
+func (c *Context) NewSet() *Set {
+	return &Set{&Object{&Value{C.NewSet(c.ptr), c}}}
+}
+
+// AsSet returns the value as a Set, or an error if it is not a Set.
+func (v *Value) AsSet() (*Set, error) {
+	if !v.IsSet() {
+		return nil, errors.New("v8go: value is not a Set")
+	}
+	return &Set{&Object{v}}, nil
+}
+
+// Size returns the number of elements in the set.
+func (s *Set) Size() int {
+	return int(C.SetSize(s.valuePtr()))
+}
+
+// Add adds the value to the set, if it isn't already in it.
+// The value may be a Value or any Go type that can be passed to NewValue.
+func (s *Set) Add(val interface{}) error {
+	v, err := s.ctx.NewValue(val)
+	if err != nil {
+		return err
+	}
+	_, err = valueResult(s.ctx, C.SetAdd(s.valuePtr(), v.valuePtr()))
+	return err
+}
+
+// Has returns true if the value is in the set.
+func (s *Set) Has(val interface{}) (bool, error) {
+	v, err := s.ctx.NewValue(val)
+	if err != nil {
+		return false, err
+	}
+	return C.SetHas(s.valuePtr(), v.valuePtr()) != 0, nil
+}
+
+// Delete removes the value from the set, returning true if it was in it.
+func (s *Set) Delete(val interface{}) (bool, error) {
+	v, err := s.ctx.NewValue(val)
+	if err != nil {
+		return false, err
+	}
+	return C.SetDelete(s.valuePtr(), v.valuePtr()) != 0, nil
+}
+
+// Clear removes all elements from the set.
+func (s *Set) Clear() {
+	C.SetClear(s.valuePtr())
+}
+
+// Values returns the set's elements, in insertion order.
+func (s *Set) Values() []*Value {
+	arr := &Array{Object{&Value{C.SetAsArray(s.valuePtr()), s.ctx}}}
+	return arr.values()
+}
+
+// ToGoSlice converts the set to a Go slice of its elements, in insertion order,
+// converting them deeply as described for Value.Export.
+func (s *Set) ToGoSlice() ([]interface{}, error) {
+	gv, err := s.Value.export(nil)
+	if err != nil {
+		return nil, err
+	}
+	return gv.([]interface{}), nil
+}
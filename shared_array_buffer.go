@@ -0,0 +1,98 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+)
+
+// SharedArrayBuffer is a JavaScript SharedArrayBuffer, a subtype of Object: an ArrayBuffer
+// whose memory can be shared by several Contexts and Isolates, which may access it
+// concurrently, using `Atomics` to synchronize. Its memory can be shared by getting its
+// BackingStore and passing that to Context.NewSharedArrayBufferFromStore.
+//
+// The SharedArrayBuffer constructor is available to JavaScript by default; it can be
+// removed by calling SetFlags("--no-harmony-sharedarraybuffer") before creating Isolates.
+type SharedArrayBuffer struct {
+	*Object
+}
+
+// NewSharedArrayBuffer creates a SharedArrayBuffer of the given size, filled with zeros.
+func (c *Context) NewSharedArrayBuffer(byteLength int) *SharedArrayBuffer {
+	return &SharedArrayBuffer{&Object{&Value{C.NewSharedArrayBuffer(c.ptr, C.size_t(byteLength)), c}}}
+}
+
+// NewSharedArrayBufferFromStore creates a SharedArrayBuffer that shares the memory of
+// the given BackingStore, which may come from a SharedArrayBuffer in another Context or
+// Isolate. It returns an error if the BackingStore has been released.
+func (c *Context) NewSharedArrayBufferFromStore(bs *BackingStore) (*SharedArrayBuffer, error) {
+	if bs.ptr == nil {
+		return nil, errors.New("v8go: BackingStore has been released")
+	}
+	return &SharedArrayBuffer{&Object{&Value{C.NewSharedArrayBufferFromStore(c.ptr, bs.ptr), c}}}, nil
+}
+
+// AsSharedArrayBuffer returns the value as a SharedArrayBuffer, or an error if it is not one.
+func (v *Value) AsSharedArrayBuffer() (*SharedArrayBuffer, error) {
+	if !v.IsSharedArrayBuffer() {
+		return nil, errors.New("v8go: value is not a SharedArrayBuffer")
+	}
+	return &SharedArrayBuffer{&Object{v}}, nil
+}
+
+// ByteLength returns the size of the buffer in bytes.
+func (b *SharedArrayBuffer) ByteLength() int {
+	return int(C.SharedArrayBufferByteLength(b.valuePtr()))
+}
+
+// Bytes returns the buffer's memory; see Value.ArrayBufferBytes. As JavaScript in other
+// Isolates may access the memory concurrently, use the sync/atomic package, or another
+// form of synchronization, where needed.
+func (b *SharedArrayBuffer) Bytes() []byte {
+	bytes, _ := b.ArrayBufferBytes()
+	return bytes
+}
+
+// BackingStore returns a reference to the buffer's memory, which keeps it alive, even once
+// the buffer has been garbage collected or its Isolate disposed, until it is released.
+func (b *SharedArrayBuffer) BackingStore() *BackingStore {
+	return &BackingStore{C.SharedArrayBufferGetBackingStore(b.valuePtr())}
+}
+
+// BackingStore is a reference to the memory of a SharedArrayBuffer. It isn't tied to any
+// Isolate, so it can be used to share the memory with other Isolates. It must be released
+// with Release once it is no longer needed.
+type BackingStore struct {
+	ptr C.BackingStorePtr
+}
+
+// Bytes returns the memory, without copying it. The slice must not be used once the
+// BackingStore is released, unless a SharedArrayBuffer still uses the memory.
+func (bs *BackingStore) Bytes() []byte {
+	if bs.ptr == nil {
+		return nil
+	}
+	rtn := C.BackingStoreContents(bs.ptr)
+	if rtn.data == nil {
+		return []byte{}
+	}
+	return (*[1 << 34]byte)(rtn.data)[:rtn.length:rtn.length]
+}
+
+// ByteLength returns the size of the memory in bytes.
+func (bs *BackingStore) ByteLength() int {
+	return len(bs.Bytes())
+}
+
+// Release releases the reference to the memory, which is freed once no SharedArrayBuffer
+// uses it either. Calling Release more than once has no effect.
+func (bs *BackingStore) Release() {
+	if bs.ptr != nil {
+		C.BackingStoreRelease(bs.ptr)
+		bs.ptr = nil
+	}
+}
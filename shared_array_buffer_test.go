@@ -0,0 +1,89 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestSharedArrayBuffer(t *testing.T) {
+	t.Parallel()
+	ctx1 := v8.NewContext()
+	defer ctx1.Isolate().Dispose()
+	defer ctx1.Close()
+
+	sab := ctx1.NewSharedArrayBuffer(8)
+	if n := sab.ByteLength(); n != 8 {
+		t.Errorf("unexpected byte length: %d", n)
+	}
+	fatalIf(t, ctx1.Global().Set("sab", sab))
+	_, err := ctx1.RunScript("Atomics.store(new Int32Array(sab), 0, 42)", "test.js")
+	fatalIf(t, err)
+	if b := sab.Bytes(); b[0] != 42 {
+		t.Errorf("expected Go to see the value stored by JS, got %v", b)
+	}
+
+	bs := sab.BackingStore()
+	defer bs.Release()
+
+	// Share the memory with a Context in another Isolate, which outlives the first.
+	ctx2 := v8.NewContext()
+	defer ctx2.Isolate().Dispose()
+	defer ctx2.Close()
+	sab2, err := ctx2.NewSharedArrayBufferFromStore(bs)
+	fatalIf(t, err)
+	fatalIf(t, ctx2.Global().Set("sab", sab2))
+	val, err := ctx2.RunScript("Atomics.add(new Int32Array(sab), 0, 1); new Int32Array(sab)[0]", "test.js")
+	fatalIf(t, err)
+	if val.Int32() != 43 {
+		t.Errorf("unexpected value in the other isolate: %v", val)
+	}
+	val, err = ctx1.RunScript("new Int32Array(sab)[0]", "test.js")
+	fatalIf(t, err)
+	if val.Int32() != 43 {
+		t.Errorf("expected the first isolate to see the change, got %v", val)
+	}
+
+	bs.Release()
+	bs.Release()
+	if _, err := ctx2.NewSharedArrayBufferFromStore(bs); err == nil {
+		t.Error("expected an error for a released BackingStore")
+	}
+	if bs.Bytes() != nil {
+		t.Error("expected no bytes from a released BackingStore")
+	}
+
+	val, err = ctx1.RunScript("new Int32Array(sab)", "test.js")
+	fatalIf(t, err)
+	if b, _ := val.ArrayBufferBytes(); len(b) != 8 || b[0] != 43 {
+		t.Errorf("unexpected bytes of a view of a SharedArrayBuffer: %v", b)
+	}
+	if _, err := val.AsSharedArrayBuffer(); err == nil {
+		t.Error("expected an error for a typed array")
+	}
+}
+
+func TestBackingStoreOutlivesIsolate(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	ctx := v8.NewContext(iso)
+	val, err := ctx.RunScript("const sab = new SharedArrayBuffer(4); new Uint8Array(sab).fill(7); sab", "test.js")
+	fatalIf(t, err)
+	sab, err := val.AsSharedArrayBuffer()
+	fatalIf(t, err)
+	bs := sab.BackingStore()
+	defer bs.Release()
+	ctx.Close()
+	iso.Dispose()
+
+	if b := bs.Bytes(); len(b) != 4 || b[3] != 7 {
+		t.Errorf("unexpected bytes: %v", b)
+	}
+	if n := bs.ByteLength(); n != 4 {
+		t.Errorf("unexpected byte length: %d", n)
+	}
+}
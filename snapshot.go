@@ -0,0 +1,86 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"strings"
+	"unsafe"
+)
+
+// CreateSnapshot runs the scripts in a new Isolate, in order, and returns a startup
+// snapshot of the resulting heap, which NewIsolateFromSnapshot can create Isolates from.
+// The global objects of the Contexts created in those Isolates then start out with
+// whatever the scripts defined, such as polyfills or the functions of a framework, so the
+// scripts don't have to be run again in each. If keepCode is true the snapshot includes
+// the compiled code of the functions the scripts ran, which makes it larger but saves
+// compiling them again.
+//
+// The scripts can only use JavaScript built-ins; CreateSnapshotWithFunctions also gives
+// them Go functions. A snapshot can only be used by the same build of v8go, with the same
+// V8 flags (see SetFlags.)
+// The error will be of type `JSError` if a script throws.
+func CreateSnapshot(scripts []ScriptSource, keepCode bool) ([]byte, error) {
+	return createSnapshot(scripts, keepCode, nil)
+}
+
+// CreateSnapshotWithFunctions is like CreateSnapshot, but first defines global functions
+// with the given names, which the scripts can refer to (e.g. in closures) but not call.
+// The Isolates created from the snapshot, with NewIsolate(WithStartupData(snapshot, ...)),
+// provide a FunctionCallback for each of them, in the same order.
+func CreateSnapshotWithFunctions(scripts []ScriptSource, keepCode bool, functions ...string) ([]byte, error) {
+	return createSnapshot(scripts, keepCode, functions)
+}
+
+func createSnapshot(scripts []ScriptSource, keepCode bool, functions []string) ([]byte, error) {
+	v8once.Do(func() {
+		C.Init()
+	})
+	cScripts := make([]C.ScriptSourceData, len(scripts)+1)
+	for i, script := range scripts {
+		cScripts[i] = C.ScriptSourceData{
+			source:    C.CString(script.Source),
+			sourceLen: C.int(len(script.Source)),
+			origin:    newCScriptOrigin(ScriptOrigin{ResourceName: script.Origin}),
+		}
+	}
+	defer func() {
+		for _, cScript := range cScripts[:len(scripts)] {
+			C.free(unsafe.Pointer(cScript.source))
+			freeCScriptOrigin(cScript.origin)
+		}
+	}()
+
+	names := strings.Join(functions, "")
+	nameLens := make([]C.int, len(functions)+1)
+	for i, name := range functions {
+		nameLens[i] = C.int(len(name))
+	}
+	cNames := C.CString(names)
+	defer C.free(unsafe.Pointer(cNames))
+
+	rtn := C.CreateSnapshot(C.int(len(scripts)), &cScripts[0], boolToCBool(keepCode),
+		C.int(len(functions)), cNames, &nameLens[0])
+	if rtn.error.msg != nil {
+		return nil, newJSError(nil, rtn.error)
+	}
+	defer C.free(unsafe.Pointer(rtn.data))
+	return C.GoBytes(unsafe.Pointer(rtn.data), rtn.length), nil
+}
+
+// NewIsolateFromSnapshot is like NewIsolateWith, but creates the Isolate from a snapshot
+// returned by CreateSnapshot or CreateSnapshotWithFunctions, with a FunctionCallback for
+// each of the functions the snapshot defines, as with WithStartupData. It returns an error
+// if the snapshot is not valid for this build of v8go, or if the number of callbacks
+// doesn't match.
+func NewIsolateFromSnapshot(snapshot []byte, initialHeap, maxHeap uint64, callbacks ...FunctionCallback) (*Isolate, error) {
+	if len(snapshot) == 0 {
+		return nil, errors.New("v8go: empty snapshot")
+	}
+	return newIsolate(initialHeap, maxHeap, snapshot, callbacks)
+}
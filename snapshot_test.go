@@ -0,0 +1,178 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"context"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestCreateSnapshot(t *testing.T) {
+	t.Parallel()
+
+	for _, keepCode := range []bool{false, true} {
+		snapshot, err := v8.CreateSnapshot([]v8.ScriptSource{
+			{Source: `function greet(name) { return "hello " + name }`, Origin: "greet.js"},
+			{Source: `var counter = 41; Array.prototype.last = function() { return this[this.length - 1] }`},
+		}, keepCode)
+		fatalIf(t, err)
+
+		iso, err := v8.NewIsolateFromSnapshot(snapshot, 0, 0)
+		fatalIf(t, err)
+		global := v8.NewObjectTemplate(iso)
+		fatalIf(t, global.Set("fromTemplate", "yes"))
+		for _, opts := range [][]v8.ContextOption{{iso}, {iso, global}} {
+			ctx := v8.NewContext(opts...)
+			val, err := ctx.RunScript(`[greet("snapshot"), ++counter, [1, 2, 3].last()].join()`, "")
+			fatalIf(t, err)
+			if got := val.String(); got != "hello snapshot,42,3" {
+				t.Errorf("keepCode=%v: unexpected result %q", keepCode, got)
+			}
+			ctx.Close()
+		}
+		ctx := v8.NewContext(iso, global)
+		val, err := ctx.RunScript(`fromTemplate`, "")
+		fatalIf(t, err)
+		if val.String() != "yes" {
+			t.Errorf("expected the global template to apply to a snapshot context, got %q", val)
+		}
+		ctx.Close()
+		iso.Dispose()
+	}
+}
+
+func TestCreateSnapshotErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := v8.CreateSnapshot([]v8.ScriptSource{{Source: `ok = 1`}, {Source: `throw new Error("nope")`, Origin: "bad.js"}}, false)
+	if jsErr, ok := err.(*v8.JSError); !ok || jsErr.Message != "Error: nope" || jsErr.Location != "bad.js:1:1" {
+		t.Errorf("expected the script's error, got %#v", err)
+	}
+	if _, err := v8.NewIsolateFromSnapshot([]byte("not a snapshot"), 0, 0); err == nil {
+		t.Error("expected an error for an invalid snapshot")
+	}
+	if _, err := v8.NewIsolateFromSnapshot(nil, 0, 0); err == nil {
+		t.Error("expected an error for an empty snapshot")
+	}
+}
+
+func TestIsolatePoolSnapshot(t *testing.T) {
+	t.Parallel()
+
+	snapshot, err := v8.CreateSnapshot([]v8.ScriptSource{{Source: `var answer = 42`}}, false)
+	fatalIf(t, err)
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{MaxIsolates: 1, Snapshot: snapshot})
+	fatalIf(t, err)
+	defer pool.Close()
+	iso, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	defer pool.Put(iso)
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	val, err := ctx.RunScript(`answer`, "")
+	fatalIf(t, err)
+	if val.Integer() != 42 {
+		t.Errorf("expected the snapshot's global, got %v", val)
+	}
+}
+
+func TestIsolatePoolSnapshotWithFunctions(t *testing.T) {
+	t.Parallel()
+
+	snapshot, err := v8.CreateSnapshotWithFunctions([]v8.ScriptSource{
+		{Source: `function answer() { return double(21) }`},
+	}, false, "double")
+	fatalIf(t, err)
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{
+		MaxIsolates: 1,
+		Snapshot:    snapshot,
+		Callbacks: []v8.FunctionCallback{func(info *v8.FunctionCallbackInfo) *v8.Value {
+			val, _ := v8.NewValue(info.Context().Isolate(), info.Args()[0].Integer()*2)
+			return val
+		}},
+	})
+	fatalIf(t, err)
+	defer pool.Close()
+	iso, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	defer pool.Put(iso)
+
+	// A FunctionTemplate's callback doesn't replace the snapshot's.
+	triple := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		val, _ := v8.NewValue(iso, info.Args()[0].Integer()*3)
+		return val
+	})
+	global := v8.NewObjectTemplate(iso)
+	fatalIf(t, global.Set("triple", triple))
+	ctx := v8.NewContext(iso, global)
+	defer ctx.Close()
+	val, err := ctx.RunScript(`answer() + triple(1)`, "")
+	fatalIf(t, err)
+	if val.Integer() != 45 {
+		t.Errorf("expected 45, got %v", val)
+	}
+}
+
+func TestNewIsolateWithStartupData(t *testing.T) {
+	t.Parallel()
+
+	snapshot, err := v8.CreateSnapshotWithFunctions([]v8.ScriptSource{
+		{Source: `function quadruple(n) { return double(double(n)) }`},
+	}, false, "log", "double")
+	fatalIf(t, err)
+
+	var logged []string
+	iso := v8.NewIsolate(v8.WithStartupData(snapshot,
+		func(info *v8.FunctionCallbackInfo) *v8.Value {
+			logged = append(logged, info.Args()[0].String())
+			return nil
+		},
+		func(info *v8.FunctionCallbackInfo) *v8.Value {
+			val, _ := v8.NewValue(info.Context().Isolate(), info.Args()[0].Integer()*2)
+			return val
+		},
+	))
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	val, err := ctx.RunScript(`log("hi"); quadruple(5)`, "")
+	fatalIf(t, err)
+	if val.Integer() != 20 {
+		t.Errorf("expected 20, got %v", val)
+	}
+	if len(logged) != 1 || logged[0] != "hi" {
+		t.Errorf("unexpected log: %v", logged)
+	}
+
+	_, err = v8.CreateSnapshotWithFunctions([]v8.ScriptSource{{Source: `double(1)`}}, false, "double")
+	if err == nil {
+		t.Error("expected an error calling a Go function while creating a snapshot")
+	}
+
+	// The snapshot's functions must all be given callbacks.
+	if _, err := v8.NewIsolateFromSnapshot(snapshot, 0, 0); err == nil {
+		t.Error("expected an error creating an Isolate without the snapshot's callbacks")
+	}
+	if _, err := v8.NewIsolatePool(v8.IsolatePoolOptions{MaxIsolates: 1, Warm: 1, Snapshot: snapshot}); err == nil {
+		t.Error("expected an error creating an IsolatePool without the snapshot's callbacks")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic for a missing callback")
+			}
+		}()
+		v8.NewIsolate(v8.WithStartupData(snapshot, func(*v8.FunctionCallbackInfo) *v8.Value { return nil }))
+	}()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for invalid startup data")
+		}
+	}()
+	v8.NewIsolate(v8.WithStartupData(make([]byte, 2048)))
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// StackFrame is a frame of a JavaScript stack trace.
+type StackFrame struct {
+	FunctionName  string // "" for an anonymous function or top-level code
+	ResourceName  string // The script's origin or `//# sourceURL`, or ""
+	Line, Column  int    // 1-based, or 0 if unknown
+	ScriptID      int
+	IsEval        bool // The frame is in code run by `eval`
+	IsConstructor bool // The function was called with `new`
+}
+
+// String formats the frame as V8 does in an Error's stack, e.g. "handler (app.js:3:7)".
+func (f StackFrame) String() string {
+	loc := f.ResourceName
+	if loc == "" {
+		loc = "<anonymous>"
+	}
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", loc, f.Line, f.Column)
+	}
+	switch {
+	case f.FunctionName == "":
+		return loc
+	case f.IsConstructor:
+		return fmt.Sprintf("new %s (%s)", f.FunctionName, loc)
+	default:
+		return fmt.Sprintf("%s (%s)", f.FunctionName, loc)
+	}
+}
+
+// CurrentStackTrace returns the JavaScript frames on the stack that led to this call of the
+// Go function, innermost first, up to at most limit frames; e.g. its first frame is the
+// script code that called the function. Frames of other Go callbacks are not included.
+func (i *FunctionCallbackInfo) CurrentStackTrace(limit int) []StackFrame {
+	return i.ctx.iso.currentStackTrace(limit)
+}
+
+func (i *Isolate) currentStackTrace(limit int) []StackFrame {
+	if limit <= 0 {
+		return nil
+	}
+	var count C.int
+	cFrames := C.IsolateCurrentStackTrace(i.ptr, C.int(limit), &count)
+	if cFrames == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(cFrames))
+	frames := make([]StackFrame, int(count))
+	for n, cFrame := range (*[1 << 20]C.StackFrameData)(unsafe.Pointer(cFrames))[:count:count] {
+		frames[n] = StackFrame{
+			FunctionName:  takeRtnString(cFrame.functionName),
+			ResourceName:  takeRtnString(cFrame.resourceName),
+			Line:          int(cFrame.line),
+			Column:        int(cFrame.column),
+			ScriptID:      int(cFrame.scriptID),
+			IsEval:        cFrame.isEval != 0,
+			IsConstructor: cFrame.isConstructor != 0,
+		}
+	}
+	return frames
+}
+
+// takeRtnString converts a RtnString to a Go string and frees its data.
+func takeRtnString(rtn C.RtnString) string {
+	if rtn.data == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(rtn.data))
+	return C.GoStringN(rtn.data, rtn.length)
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestFunctionCallbackInfoCurrentStackTrace(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	var frames []v8.StackFrame
+	limit := 10
+	fatalIf(t, ctx.Global().Set("audit", v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		frames = info.CurrentStackTrace(limit)
+		return nil
+	}).GetFunction(ctx)))
+
+	_, err := ctx.RunScript(`function Handler() {
+  audit();
+}
+function run() { new Handler() }
+run();`, "tenant.js")
+	fatalIf(t, err)
+
+	want := []string{"new Handler (tenant.js:2:3)", "run (tenant.js:4:18)", "tenant.js:5:1"}
+	if len(frames) != len(want) {
+		t.Fatalf("expected %d frames, got %v", len(want), frames)
+	}
+	for i, f := range frames {
+		if f.String() != want[i] {
+			t.Errorf("frame %d: expected %q, got %q", i, want[i], f)
+		}
+	}
+	if frames[0].FunctionName != "Handler" || !frames[0].IsConstructor || frames[0].ScriptID == 0 ||
+		frames[0].ScriptID != frames[2].ScriptID {
+		t.Errorf("unexpected first frame %+v", frames[0])
+	}
+
+	limit = 1
+	_, err = ctx.RunScript(`eval("(function inEval() { audit() })()")`, "outer.js")
+	fatalIf(t, err)
+	if len(frames) != 1 || frames[0].FunctionName != "inEval" || !frames[0].IsEval {
+		t.Errorf("expected one frame in eval, got %+v", frames)
+	}
+
+	limit = 0
+	_, err = ctx.RunScript(`audit()`, "")
+	fatalIf(t, err)
+	if frames != nil {
+		t.Errorf("expected no frames for a zero limit, got %v", frames)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+/*
+#include <stdlib.h>
+#include "v8go.h"
+static ValueRef NewSymbolGo(ContextPtr ctx, _GoString_ desc) {
+	return NewSymbol(ctx, _GoStringPtr(desc), _GoStringLen(desc)); }
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// Symbol is a JavaScript symbol (ECMA-262, 4.3.25), a unique primitive value that can be
+// used as a property key with Object.GetKey, Object.SetKey, etc.
+type Symbol struct {
+	*Value
+}
+
+// NewSymbol creates a new, unique symbol with the given description.
+// Like primitives created by NewValue, it can be used in any Context of the Isolate.
+func NewSymbol(iso *Isolate, description string) *Symbol {
+	return iso.internalContext.NewSymbol(description)
+}
+
+// NewSymbol creates a new, unique symbol with the given description.
+func (c *Context) NewSymbol(description string) *Symbol {
+	return &Symbol{&Value{C.NewSymbolGo(c.ptr, description), c}}
+}
+
+func wellKnownSymbol(iso *Isolate, which C.WellKnownSymbol) *Symbol {
+	ctx := iso.internalContext
+	return &Symbol{&Value{C.SymbolWellKnown(ctx.ptr, C.int(which)), ctx}}
+}
+
+// SymbolAsyncIterator returns the well-known symbol `Symbol.asyncIterator`.
+func SymbolAsyncIterator(iso *Isolate) *Symbol {
+	return wellKnownSymbol(iso, C.AsyncIterator_sym)
+}
+
+// SymbolHasInstance returns the well-known symbol `Symbol.hasInstance`.
+func SymbolHasInstance(iso *Isolate) *Symbol {
+	return wellKnownSymbol(iso, C.HasInstance_sym)
+}
+
+// SymbolIterator returns the well-known symbol `Symbol.iterator`.
+func SymbolIterator(iso *Isolate) *Symbol {
+	return wellKnownSymbol(iso, C.Iterator_sym)
+}
+
+// SymbolToPrimitive returns the well-known symbol `Symbol.toPrimitive`.
+func SymbolToPrimitive(iso *Isolate) *Symbol {
+	return wellKnownSymbol(iso, C.ToPrimitive_sym)
+}
+
+// SymbolToStringTag returns the well-known symbol `Symbol.toStringTag`.
+func SymbolToStringTag(iso *Isolate) *Symbol {
+	return wellKnownSymbol(iso, C.ToStringTag_sym)
+}
+
+// Description returns the symbol's description, e.g. "foo" for `Symbol("foo")`,
+// or "" if it has none.
+func (s *Symbol) Description() string {
+	rtn := C.SymbolDescription(s.valuePtr())
+	if rtn.data == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(rtn.data))
+	return C.GoStringN(rtn.data, rtn.length)
+}
+
+// AsSymbol returns the value as a Symbol, or an error if it is not a symbol.
+func (v *Value) AsSymbol() (*Symbol, error) {
+	if !v.IsSymbol() {
+		return nil, errors.New("v8go: value is not a Symbol")
+	}
+	return &Symbol{v}, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestNewSymbol(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	sym := v8.NewSymbol(iso, "foo")
+	if !sym.IsSymbol() {
+		t.Fatalf("expected a symbol, got %v", sym.Value)
+	}
+	if d := sym.Description(); d != "foo" {
+		t.Errorf("unexpected description: %q", d)
+	}
+	if sym.SameValue(v8.NewSymbol(iso, "foo").Value) {
+		t.Error("expected symbols with the same description to be distinct")
+	}
+
+	obj := ctx.NewObject()
+	fatalIf(t, obj.SetKey(sym.Value, "bar"))
+	if !obj.HasKey(sym.Value) || obj.Has("foo") {
+		t.Error("expected the property to be keyed by the symbol")
+	}
+	val, err := obj.GetKey(sym.Value)
+	fatalIf(t, err)
+	if val.String() != "bar" {
+		t.Errorf("unexpected property value: %v", val)
+	}
+	if !obj.DeleteKey(sym.Value) || obj.HasKey(sym.Value) {
+		t.Error("expected the property to be deleted")
+	}
+
+	fatalIf(t, ctx.Global().Set("sym", sym))
+	val, err = ctx.RunScript("typeof sym === 'symbol' && sym.description", "test.js")
+	fatalIf(t, err)
+	if val.String() != "foo" {
+		t.Errorf("unexpected description in JS: %v", val)
+	}
+}
+
+func TestSymbolWellKnown(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	tests := [...]struct {
+		name string
+		sym  *v8.Symbol
+	}{
+		{"asyncIterator", v8.SymbolAsyncIterator(iso)},
+		{"hasInstance", v8.SymbolHasInstance(iso)},
+		{"iterator", v8.SymbolIterator(iso)},
+		{"toPrimitive", v8.SymbolToPrimitive(iso)},
+		{"toStringTag", v8.SymbolToStringTag(iso)},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript("Symbol."+tt.name, "test.js")
+		fatalIf(t, err)
+		if !tt.sym.SameValue(val) {
+			t.Errorf("%s: expected the same symbol as in JS", tt.name)
+		}
+		if d := tt.sym.Description(); d != "Symbol."+tt.name {
+			t.Errorf("%s: unexpected description %q", tt.name, d)
+		}
+	}
+
+	obj := ctx.NewObject()
+	fatalIf(t, obj.SetKey(v8.SymbolToStringTag(iso).Value, "Thing"))
+	fatalIf(t, ctx.Global().Set("obj", obj))
+	val, err := ctx.RunScript("Object.prototype.toString.call(obj)", "test.js")
+	fatalIf(t, err)
+	if val.String() != "[object Thing]" {
+		t.Errorf("unexpected toString: %v", val)
+	}
+}
+
+func TestValueAsSymbol(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, _ := ctx.RunScript("Symbol()", "test.js")
+	sym, err := val.AsSymbol()
+	fatalIf(t, err)
+	if d := sym.Description(); d != "" {
+		t.Errorf("expected no description, got %q", d)
+	}
+
+	val, _ = ctx.RunScript("'foo'", "test.js")
+	if _, err := val.AsSymbol(); err == nil {
+		t.Error("expected an error for a string")
+	}
+}
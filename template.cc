@@ -3,6 +3,7 @@
 // found in the LICENSE file.
 
 #include "v8go.hh"
+#include "v8-fast-api-calls.h"
 
 
 struct WithTemplate : public WithIsolate {
@@ -46,6 +47,20 @@ void TemplateSetTemplate(TemplatePtr ptr,
   _with.tmpl->Set(prop_name, obj->ptr.Get(_with.iso), (PropertyAttribute)attributes);
 }
 
+void TemplateSetAccessorProperty(TemplatePtr ptr,
+                                 const char* name, int nameLen,
+                                 TemplatePtr getter, TemplatePtr setter,
+                                 int attributes) {
+  WithTemplate _with(ptr);
+
+  Local<String> prop_name =
+      String::NewFromUtf8(_with.iso, name, NewStringType::kNormal, nameLen).ToLocalChecked();
+  Local<FunctionTemplate> get, set;
+  if (getter) get = getter->ptr.Get(_with.iso).As<FunctionTemplate>();
+  if (setter) set = setter->ptr.Get(_with.iso).As<FunctionTemplate>();
+  _with.tmpl->SetAccessorProperty(prop_name, get, set, (PropertyAttribute)attributes);
+}
+
 /********** ObjectTemplate **********/
 
 TemplatePtr NewObjectTemplate(IsolatePtr iso) {
@@ -78,6 +93,112 @@ int ObjectTemplateInternalFieldCount(TemplatePtr ptr) {
   return obj_tmpl->InternalFieldCount();
 }
 
+// Calls the Go NamedPropertyHandler for an interceptor. Returns false if the handler
+// doesn't intercept the access, in which case the return value is left unset.
+template <typename T>
+static bool callNamedHandler(InterceptorOp op, Local<Name> property, Local<Value> value,
+                             const PropertyCallbackInfo<T>& info, InterceptorResult* result) {
+  Isolate* iso = info.GetIsolate();
+  V8GoContext* ctx = V8GoContext::fromContext(iso->GetCurrentContext());
+  int handler_ref = info.Data().template As<Integer>()->Value();
+  ValueRef name = property.IsEmpty() ? ValueRef{} : ctx->addValue(property);
+  ValueRef val = value.IsEmpty() ? ValueRef{} : ctx->addValue(value);
+  *result = goNamedPropertyCallback(ctx->goRef, handler_ref, op,
+                                    ctx->addValue(info.This()), name, val);
+  return result->intercepted;
+}
+
+static void namedGetter(Local<Name> property, const PropertyCallbackInfo<Value>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  InterceptorResult result;
+  if (callNamedHandler(NamedGetter_op, property, Local<Value>(), info, &result)) {
+    info.GetReturnValue().Set(Deref(result.value));
+  }
+}
+
+static void namedSetter(Local<Name> property, Local<Value> value,
+                        const PropertyCallbackInfo<Value>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  InterceptorResult result;
+  if (callNamedHandler(NamedSetter_op, property, value, info, &result)) {
+    info.GetReturnValue().Set(value);
+  }
+}
+
+static void namedQuery(Local<Name> property, const PropertyCallbackInfo<Integer>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  InterceptorResult result;
+  if (callNamedHandler(NamedQuery_op, property, Local<Value>(), info, &result)) {
+    info.GetReturnValue().Set(result.result);
+  }
+}
+
+static void namedDeleter(Local<Name> property, const PropertyCallbackInfo<Boolean>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  InterceptorResult result;
+  if (callNamedHandler(NamedDeleter_op, property, Local<Value>(), info, &result)) {
+    info.GetReturnValue().Set(result.result != 0);
+  }
+}
+
+static void namedEnumerator(const PropertyCallbackInfo<Array>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  InterceptorResult result;
+  if (callNamedHandler(NamedEnumerator_op, Local<Name>(), Local<Value>(), info, &result)) {
+    Local<Value> names = Deref(result.value);
+    if (names->IsArray()) {
+      info.GetReturnValue().Set(names.As<Array>());
+    }
+  }
+}
+
+void ObjectTemplateSetNamedHandler(TemplatePtr ptr, int handler_ref, int ops) {
+  WithTemplate _with(ptr);
+  Local<ObjectTemplate> obj_tmpl = _with.tmpl.As<ObjectTemplate>();
+
+  auto has = [ops](InterceptorOp op) { return (ops & (1 << op)) != 0; };
+  NamedPropertyHandlerConfiguration config(
+      has(NamedGetter_op) ? namedGetter : nullptr,
+      has(NamedSetter_op) ? namedSetter : nullptr,
+      has(NamedQuery_op) ? namedQuery : nullptr,
+      has(NamedDeleter_op) ? namedDeleter : nullptr,
+      has(NamedEnumerator_op) ? namedEnumerator : nullptr,
+      Integer::New(_with.iso, handler_ref),
+      PropertyHandlerFlags::kOnlyInterceptStrings);
+  obj_tmpl->SetHandler(config);
+}
+
+void ObjectTemplateSetImmutableProto(TemplatePtr ptr) {
+  WithTemplate _with(ptr);
+  _with.tmpl.As<ObjectTemplate>()->SetImmutableProto();
+}
+
+// A Template's lazy data property is computed by calling the Go getter of a
+// NamedPropertyHandler, the first time it's read.
+static void lazyDataGetter(Local<Name> property, const PropertyCallbackInfo<Value>& info) {
+  WithIsolate _withiso(info.GetIsolate());
+  InterceptorResult result;
+  if (callNamedHandler(NamedGetter_op, property, Local<Value>(), info, &result)) {
+    info.GetReturnValue().Set(Deref(result.value));
+  }
+}
+
+void TemplateSetLazyDataProperty(TemplatePtr ptr, const char* name, int nameLen,
+                                 int handler_ref, int attributes) {
+  WithTemplate _with(ptr);
+
+  Local<String> prop_name =
+      String::NewFromUtf8(_with.iso, name, NewStringType::kNormal, nameLen).ToLocalChecked();
+  _with.tmpl->SetLazyDataProperty(prop_name, lazyDataGetter, Integer::New(_with.iso, handler_ref),
+                                  (PropertyAttribute)attributes);
+}
+
+void ObjectTemplateSetCallAsFunctionHandler(TemplatePtr ptr, int callback_ref) {
+  WithTemplate _with(ptr);
+  Local<ObjectTemplate> obj_tmpl = _with.tmpl.As<ObjectTemplate>();
+  obj_tmpl->SetCallAsFunctionHandler(FunctionTemplateCallback, Integer::New(_with.iso, callback_ref));
+}
+
 /********** FunctionTemplate **********/
 
 namespace v8go {
@@ -95,6 +216,9 @@ namespace v8go {
     int callback_ref = info.Data().As<Integer>()->Value();
 
     ValueRef _this = ctx->addValue(info.This());
+    // (The holder is almost always `this`, so avoid adding another value for it.)
+    ValueRef holder = info.Holder() == info.This() ? _this : ctx->addValue(info.Holder());
+    ValueRef new_target = info.IsConstructCall() ? ctx->addValue(info.NewTarget()) : ValueRef{};
 
     int args_count = info.Length();
     ValueRef thisAndArgs[args_count + 1];
@@ -103,16 +227,30 @@ namespace v8go {
       thisAndArgs[1+i] = ctx->addValue(info[i]);
     }
 
-    ValuePtr val = goFunctionCallback(ctx->goRef, callback_ref, thisAndArgs, args_count);
-    if (val.ctx != nullptr) {
-      info.GetReturnValue().Set(Deref(val));
-    } else {
-      info.GetReturnValue().SetUndefined();
+    CallbackResult rtn = goFunctionCallback(ctx->goRef, callback_ref, thisAndArgs, args_count,
+                                            info.IsConstructCall(), holder, new_target);
+    SetCallbackResult(info.GetReturnValue(), rtn);
+  }
+
+  // declared in v8go.hh
+  void SetCallbackResult(ReturnValue<Value> result, CallbackResult const& rtn) {
+    switch (rtn.kind) {
+      case ReturnInt32_kind:  result.Set(int32_t(rtn.number)); break;
+      case ReturnUint32_kind: result.Set(uint32_t(rtn.number)); break;
+      case ReturnDouble_kind: result.Set(rtn.number); break;
+      case ReturnBool_kind:   result.Set(rtn.number != 0); break;
+      case ReturnNull_kind:   result.SetNull(); break;
+      default:
+        if (rtn.value.ctx != nullptr) {
+          result.Set(Deref(rtn.value));
+        } else {
+          result.SetUndefined();
+        }
     }
   }
 }
 
-TemplatePtr NewFunctionTemplate(IsolatePtr iso, int callback_ref) {
+TemplatePtr NewFunctionTemplate(IsolatePtr iso, int callback_ref, TemplatePtr receiver) {
   WithIsolate _withiso(iso);
 
   // (rogchap) We only need to store one value, callback_ref, into the
@@ -121,10 +259,51 @@ TemplatePtr NewFunctionTemplate(IsolatePtr iso, int callback_ref) {
   // iso->GetData(0)
   Local<Integer> cbData = Integer::New(iso, callback_ref);
 
+  // With a signature, V8 throws a TypeError if the receiver isn't an instance of `receiver`.
+  Local<Signature> signature;
+  if (receiver != nullptr) {
+    signature = Signature::New(iso, receiver->ptr.Get(iso).As<FunctionTemplate>());
+  }
+
   V8GoTemplate* ot = new V8GoTemplate;
   ot->iso = iso;
   ot->ptr.Reset(iso,
-                FunctionTemplate::New(iso, FunctionTemplateCallback, cbData));
+                FunctionTemplate::New(iso, FunctionTemplateCallback, cbData, signature));
+  return ot;
+}
+
+static CTypeInfo fastTypeInfo(FastType type) {
+  switch (type) {
+    case FastBool_type:    return CTypeInfo(CTypeInfo::Type::kBool);
+    case FastInt32_type:   return CTypeInfo(CTypeInfo::Type::kInt32);
+    case FastUint32_type:  return CTypeInfo(CTypeInfo::Type::kUint32);
+    case FastFloat32_type: return CTypeInfo(CTypeInfo::Type::kFloat32);
+    case FastFloat64_type: return CTypeInfo(CTypeInfo::Type::kFloat64);
+    default:               return CTypeInfo(CTypeInfo::Type::kVoid);
+  }
+}
+
+TemplatePtr NewFastFunctionTemplate(IsolatePtr iso, int callback_ref,
+                                    const void* address, FastType result,
+                                    int argCount, const FastType* args) {
+  WithIsolate _withiso(iso);
+  Local<Integer> cbData = Integer::New(iso, callback_ref);
+
+  // V8 keeps pointers to the type info in the functions created from the template, which
+  // can outlive it, so it is never freed. The first argument is the receiver.
+  auto argInfo = new std::vector<CTypeInfo>{CTypeInfo(CTypeInfo::Type::kV8Value)};
+  for (int i = 0; i < argCount; i++) {
+    argInfo->push_back(fastTypeInfo(args[i]));
+  }
+  auto info = new CFunctionInfo(fastTypeInfo(result), argCount + 1, argInfo->data());
+  CFunction cFunction(address, info);
+
+  V8GoTemplate* ot = new V8GoTemplate;
+  ot->iso = iso;
+  ot->ptr.Reset(iso, FunctionTemplate::New(iso, FunctionTemplateCallback, cbData,
+                                           Local<Signature>(), argCount,
+                                           ConstructorBehavior::kThrow,
+                                           SideEffectType::kHasSideEffect, &cFunction));
   return ot;
 }
 
@@ -135,3 +314,36 @@ RtnValue FunctionTemplateGetFunction(TemplatePtr ptr, ContextPtr ctx) {
   Local<FunctionTemplate> fn_tmpl = tmpl.As<FunctionTemplate>();
   return _with.returnValue(fn_tmpl->GetFunction(_with.local_ctx));
 }
+
+void FunctionTemplateInherit(TemplatePtr ptr, TemplatePtr parent) {
+  WithTemplate _with(ptr);
+  Local<FunctionTemplate> fn_tmpl = _with.tmpl.As<FunctionTemplate>();
+  fn_tmpl->Inherit(parent->ptr.Get(_with.iso).As<FunctionTemplate>());
+}
+
+static TemplatePtr newTemplateWrapper(Isolate* iso, Local<Template> tmpl) {
+  V8GoTemplate* ot = new V8GoTemplate;
+  ot->iso = iso;
+  ot->ptr.Reset(iso, tmpl);
+  return ot;
+}
+
+TemplatePtr FunctionTemplatePrototypeTemplate(TemplatePtr ptr) {
+  WithTemplate _with(ptr);
+  Local<FunctionTemplate> fn_tmpl = _with.tmpl.As<FunctionTemplate>();
+  return newTemplateWrapper(_with.iso, fn_tmpl->PrototypeTemplate());
+}
+
+TemplatePtr FunctionTemplateInstanceTemplate(TemplatePtr ptr) {
+  WithTemplate _with(ptr);
+  Local<FunctionTemplate> fn_tmpl = _with.tmpl.As<FunctionTemplate>();
+  return newTemplateWrapper(_with.iso, fn_tmpl->InstanceTemplate());
+}
+
+void FunctionTemplateSetClassName(TemplatePtr ptr, const char* name, int nameLen) {
+  WithTemplate _with(ptr);
+  Local<FunctionTemplate> fn_tmpl = _with.tmpl.As<FunctionTemplate>();
+  Local<String> class_name =
+      String::NewFromUtf8(_with.iso, name, NewStringType::kNormal, nameLen).ToLocalChecked();
+  fn_tmpl->SetClassName(class_name);
+}
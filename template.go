@@ -18,6 +18,19 @@ static void TemplateSetTemplateGo(TemplatePtr ptr,
 	   							int attributes) {
 	return TemplateSetTemplate(ptr, _GoStringPtr(name), _GoStringLen(name),
 							   obj_ptr, attributes); }
+static void TemplateSetAccessorPropertyGo(TemplatePtr ptr,
+										_GoString_ name,
+										TemplatePtr getter,
+										TemplatePtr setter,
+										int attributes) {
+	TemplateSetAccessorProperty(ptr, _GoStringPtr(name), _GoStringLen(name),
+								getter, setter, attributes); }
+static void TemplateSetLazyDataPropertyGo(TemplatePtr ptr,
+										_GoString_ name,
+										int handler_ref,
+										int attributes) {
+	TemplateSetLazyDataProperty(ptr, _GoStringPtr(name), _GoStringLen(name),
+								handler_ref, attributes); }
 */
 import "C"
 import (
@@ -65,6 +78,60 @@ func (t *template) Set(name string, val interface{}, attributes ...PropertyAttri
 	return nil
 }
 
+// SetAccessorProperty adds an accessor property to each instance created by this template,
+// whose getter and setter are functions created from the given templates. Either may be
+// nil; without a setter, assignments are ignored, or throw a TypeError in strict mode.
+// ReadOnly has no effect on an accessor, so omit the setter instead.
+func (t *template) SetAccessorProperty(name string, getter, setter *FunctionTemplate, attributes ...PropertyAttribute) {
+	var attrs PropertyAttribute
+	for _, a := range attributes {
+		attrs |= a
+	}
+	var getPtr, setPtr C.TemplatePtr
+	if getter != nil {
+		getPtr = getter.ptr
+	}
+	if setter != nil {
+		setPtr = setter.ptr
+	}
+	C.TemplateSetAccessorPropertyGo(t.ptr, name, getPtr, setPtr, C.int(attrs))
+	runtime.KeepAlive(t)
+	runtime.KeepAlive(getter)
+	runtime.KeepAlive(setter)
+}
+
+// SetLazyDataProperty adds a data property to each instance created by this template, whose
+// value is computed by calling getter the first time the property is read; after that it's
+// an ordinary property with that value. This avoids the cost of creating values that scripts
+// may never use. If getter returns nil, the value is undefined. The getter can throw
+// exceptions with Isolate.ThrowException.
+func (t *template) SetLazyDataProperty(name string, getter func(info *PropertyCallbackInfo) *Value, attributes ...PropertyAttribute) {
+	var attrs PropertyAttribute
+	for _, a := range attributes {
+		attrs |= a
+	}
+	ref := t.iso.registerHandler(&NamedPropertyHandler{Getter: getter})
+	C.TemplateSetLazyDataPropertyGo(t.ptr, name, C.int(ref), C.int(attrs))
+	runtime.KeepAlive(t)
+}
+
+// cachedInstance returns the instance of t cached in the Context, calling create to create
+// it the first time.
+func (c *Context) cachedInstance(t *template, create func() (*Value, error)) (*Value, error) {
+	if p, ok := c.templateInstances[t]; ok {
+		return p.Value(c), nil
+	}
+	val, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if c.templateInstances == nil {
+		c.templateInstances = map[*template]*Persistent{}
+	}
+	c.templateInstances[t] = NewPersistent(val)
+	return val, nil
+}
+
 func (t *template) finalizer() {
 	// Using v8::PersistentBase::Reset() wouldn't be thread-safe to do from
 	// this finalizer goroutine so just free the wrapper and let the template
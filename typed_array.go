@@ -0,0 +1,129 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// TypedArray is a JavaScript typed array, such as a Uint8Array, a subtype of Object.
+type TypedArray struct {
+	*Object
+}
+
+// AsTypedArray returns the value as a TypedArray, or an error if it is not a typed array.
+func (v *Value) AsTypedArray() (*TypedArray, error) {
+	if !v.IsTypedArray() {
+		return nil, errors.New("v8go: value is not a TypedArray")
+	}
+	return &TypedArray{&Object{v}}, nil
+}
+
+// Length returns the number of elements in the array.
+func (a *TypedArray) Length() int {
+	return int(C.TypedArrayLength(a.valuePtr()))
+}
+
+// Release detaches the array's ArrayBuffer, so that JavaScript can no longer access its
+// memory; the array and any other views of the buffer become empty. Once Release returns,
+// the Go slice that an array created by NewUint8Array etc. is over can be used freely again.
+func (a *TypedArray) Release() {
+	C.TypedArrayRelease(a.valuePtr())
+}
+
+// newExternalTypedArray creates a typed array of the given type over the memory of a
+// Go slice. The memory is pinned, so that V8 can keep using it, until V8 releases it.
+func newExternalTypedArray(ctx *Context, typ C.TypedArrayType, slice interface{}) (*TypedArray, error) {
+	rv := reflect.ValueOf(slice)
+	length := rv.Len()
+	var data unsafe.Pointer
+	var handle cgo.Handle
+	if length > 0 {
+		data = rv.Index(0).Addr().UnsafePointer()
+		handle = pinExternalMemory(data)
+	}
+	byteLength := length * int(rv.Type().Elem().Size())
+	rtn := C.NewExternalTypedArray(ctx.ptr, C.int(typ), data, C.size_t(length),
+		C.size_t(byteLength), C.uintptr_t(handle))
+	obj, err := objectResult(ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedArray{obj}, nil
+}
+
+// pinExternalMemory pins the Go memory that data points to, so that V8 can keep using it,
+// and returns a handle that V8 passes to goReleaseExternalMemory when it no longer needs it.
+func pinExternalMemory(data unsafe.Pointer) cgo.Handle {
+	pinner := &runtime.Pinner{}
+	pinner.Pin(data)
+	return cgo.NewHandle(pinner)
+}
+
+//export goReleaseExternalMemory
+func goReleaseExternalMemory(handle C.uintptr_t) {
+	h := cgo.Handle(handle)
+	h.Value().(*runtime.Pinner).Unpin()
+	h.Delete()
+}
+
+// NewUint8Array creates a Uint8Array whose elements are the bytes of data, without
+// copying them: changes made by JavaScript are visible in data, and vice versa.
+// V8 keeps using the slice's memory until the array's Release method is called, or
+// the array is garbage collected; until then, the slice must not be used for anything else.
+func NewUint8Array(ctx *Context, data []byte) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Uint8Array_type, data)
+}
+
+// NewInt8Array creates an Int8Array over the memory of data; see NewUint8Array.
+func NewInt8Array(ctx *Context, data []int8) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Int8Array_type, data)
+}
+
+// NewUint16Array creates a Uint16Array over the memory of data; see NewUint8Array.
+func NewUint16Array(ctx *Context, data []uint16) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Uint16Array_type, data)
+}
+
+// NewInt16Array creates an Int16Array over the memory of data; see NewUint8Array.
+func NewInt16Array(ctx *Context, data []int16) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Int16Array_type, data)
+}
+
+// NewUint32Array creates a Uint32Array over the memory of data; see NewUint8Array.
+func NewUint32Array(ctx *Context, data []uint32) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Uint32Array_type, data)
+}
+
+// NewInt32Array creates an Int32Array over the memory of data; see NewUint8Array.
+func NewInt32Array(ctx *Context, data []int32) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Int32Array_type, data)
+}
+
+// NewFloat32Array creates a Float32Array over the memory of data; see NewUint8Array.
+func NewFloat32Array(ctx *Context, data []float32) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Float32Array_type, data)
+}
+
+// NewFloat64Array creates a Float64Array over the memory of data; see NewUint8Array.
+func NewFloat64Array(ctx *Context, data []float64) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.Float64Array_type, data)
+}
+
+// NewBigInt64Array creates a BigInt64Array over the memory of data; see NewUint8Array.
+func NewBigInt64Array(ctx *Context, data []int64) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.BigInt64Array_type, data)
+}
+
+// NewBigUint64Array creates a BigUint64Array over the memory of data; see NewUint8Array.
+func NewBigUint64Array(ctx *Context, data []uint64) (*TypedArray, error) {
+	return newExternalTypedArray(ctx, C.BigUint64Array_type, data)
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestNewUint8Array(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	data := []byte{1, 2, 3}
+	arr, err := v8.NewUint8Array(ctx, data)
+	fatalIf(t, err)
+	if !arr.IsUint8Array() || arr.Length() != 3 {
+		t.Fatalf("unexpected array: %v", arr.DetailString())
+	}
+
+	fatalIf(t, ctx.Global().Set("arr", arr))
+	val, err := ctx.RunScript("arr[0] = 42; arr[1] + arr[2]", "test.js")
+	fatalIf(t, err)
+	if val.Int32() != 5 {
+		t.Errorf("unexpected sum: %v", val)
+	}
+	if data[0] != 42 {
+		t.Errorf("expected JS to write to the Go slice, got %v", data)
+	}
+	data[2] = 7
+	val, err = ctx.RunScript("arr[2]", "test.js")
+	fatalIf(t, err)
+	if val.Int32() != 7 {
+		t.Errorf("expected JS to see the Go slice change, got %v", val)
+	}
+
+	arr.Release()
+	if arr.Length() != 0 {
+		t.Errorf("expected the released array to be empty, got length %d", arr.Length())
+	}
+	val, err = ctx.RunScript("arr.length === 0 && arr.buffer.byteLength === 0 && arr[0] === undefined", "test.js")
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Error("expected the released array to be empty in JS")
+	}
+}
+
+func TestNewTypedArrays(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	newArray := func(arr *v8.TypedArray, err error) *v8.TypedArray {
+		fatalIf(t, err)
+		return arr
+	}
+	tests := [...]struct {
+		arr  *v8.TypedArray
+		is   func(*v8.Value) bool
+		want string
+	}{
+		{newArray(v8.NewInt8Array(ctx, []int8{-1, 2})), (*v8.Value).IsInt8Array, "-1,2"},
+		{newArray(v8.NewUint16Array(ctx, []uint16{65535})), (*v8.Value).IsUint16Array, "65535"},
+		{newArray(v8.NewInt16Array(ctx, []int16{-300})), (*v8.Value).IsInt16Array, "-300"},
+		{newArray(v8.NewUint32Array(ctx, []uint32{1 << 31})), (*v8.Value).IsUint32Array, "2147483648"},
+		{newArray(v8.NewInt32Array(ctx, []int32{-1 << 31, 5})), (*v8.Value).IsInt32Array, "-2147483648,5"},
+		{newArray(v8.NewFloat32Array(ctx, []float32{1.5})), (*v8.Value).IsFloat32Array, "1.5"},
+		{newArray(v8.NewFloat64Array(ctx, []float64{0.1, 2})), (*v8.Value).IsFloat64Array, "0.1,2"},
+		{newArray(v8.NewBigInt64Array(ctx, []int64{-1 << 63})), (*v8.Value).IsBigInt64Array, "-9223372036854775808"},
+		{newArray(v8.NewBigUint64Array(ctx, []uint64{1<<64 - 1})), (*v8.Value).IsBigUint64Array, "18446744073709551615"},
+		{newArray(v8.NewUint8Array(ctx, nil)), (*v8.Value).IsUint8Array, ""},
+	}
+	for _, tt := range tests {
+		if !tt.is(tt.arr.Value) {
+			t.Errorf("%s: unexpected type", tt.arr.DetailString())
+		}
+		if s := tt.arr.String(); s != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, s)
+		}
+	}
+
+	val, _ := ctx.RunScript("new Uint8Array(4)", "test.js")
+	arr, err := val.AsTypedArray()
+	fatalIf(t, err)
+	if arr.Length() != 4 {
+		t.Errorf("unexpected length: %d", arr.Length())
+	}
+	if _, err := ctx.Global().Value.AsTypedArray(); err == nil {
+		t.Error("expected an error for an object that is not a typed array")
+	}
+}
@@ -7,8 +7,15 @@ package v8go
 // #include <stdlib.h>
 // #include "v8go.h"
 import "C"
-import "unsafe"
+import (
+	"encoding/binary"
+	"errors"
+	"unsafe"
+)
 
+// UnboundScript is a compiled script that is not bound to any Context.
+// It can be run in any Context that belongs to the Isolate it was compiled in,
+// which avoids recompiling the same source for every Context.
 type UnboundScript struct {
 	ptr C.UnboundScriptPtr
 	iso *Isolate
@@ -37,3 +44,107 @@ func (u *UnboundScript) CreateCodeCache() *CompilerCachedData {
 	C.ScriptCompilerCachedDataDelete(rtn)
 	return cachedData
 }
+
+// serializedScriptMagic starts the data returned by UnboundScript.Serialize;
+// the last byte is the version of the format.
+const serializedScriptMagic = "v8go:us\x01"
+
+// Serialize returns the script's source and origin together with a code cache for it,
+// as a blob that can be written to disk and loaded into any Isolate, even by a later
+// process, with Isolate.DeserializeUnboundScript. V8 needs the source to use a code
+// cache, and the script doesn't keep it, so source and origin must be the ones the
+// script was compiled with; otherwise V8 will reject the code cache when it is loaded.
+func (u *UnboundScript) Serialize(source string, origin ScriptOrigin) []byte {
+	cache := u.CreateCodeCache()
+	o := origin
+	buf := []byte(serializedScriptMagic)
+	buf = appendBytes(buf, []byte(source))
+	buf = appendBytes(buf, []byte(o.ResourceName))
+	buf = appendBytes(buf, []byte(o.SourceMapURL))
+	buf = appendVarint(buf, int64(o.LineOffset))
+	buf = appendVarint(buf, int64(o.ColumnOffset))
+	buf = appendVarint(buf, int64(o.ScriptID))
+	var flags int64
+	if o.IsSharedCrossOrigin {
+		flags |= 1
+	}
+	if o.IsOpaque {
+		flags |= 2
+	}
+	buf = appendVarint(buf, flags)
+	return appendBytes(buf, cache.Bytes)
+}
+
+var errInvalidSerializedScript = errors.New("v8go: invalid serialized UnboundScript")
+
+// DeserializeUnboundScript loads a script serialized with UnboundScript.Serialize.
+// If V8 rejects the code cache, e.g. because it was created by a different V8
+// version or with different flags, the script is compiled from its source instead
+// and cacheRejected is true.
+// error will be of type `JSError` if the script fails to compile.
+func (i *Isolate) DeserializeUnboundScript(data []byte) (us *UnboundScript, cacheRejected bool, err error) {
+	if len(data) < len(serializedScriptMagic) || string(data[:len(serializedScriptMagic)]) != serializedScriptMagic {
+		return nil, false, errInvalidSerializedScript
+	}
+	d := scriptDecoder{data: data[len(serializedScriptMagic):]}
+	source := string(d.bytes())
+	var o ScriptOrigin
+	o.ResourceName = string(d.bytes())
+	o.SourceMapURL = string(d.bytes())
+	o.LineOffset = int(d.varint())
+	o.ColumnOffset = int(d.varint())
+	o.ScriptID = int(d.varint())
+	flags := d.varint()
+	o.IsSharedCrossOrigin = flags&1 != 0
+	o.IsOpaque = flags&2 != 0
+	cache := d.bytes()
+	if d.err != nil || len(d.data) > 0 {
+		return nil, false, errInvalidSerializedScript
+	}
+	cachedData := &CompilerCachedData{Bytes: cache}
+	us, err = i.CompileUnboundScript(source, o.ResourceName, CompileOptions{
+		CachedData: cachedData,
+		Origin:     &o,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return us, cachedData.Rejected, nil
+}
+
+func appendVarint(buf []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutVarint(tmp[:], v)]...)
+}
+
+func appendBytes(buf []byte, b []byte) []byte {
+	return append(appendVarint(buf, int64(len(b))), b...)
+}
+
+// scriptDecoder reads the fields written by UnboundScript.Serialize,
+// recording the first error.
+type scriptDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *scriptDecoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = errInvalidSerializedScript
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *scriptDecoder) bytes() []byte {
+	n := d.varint()
+	if n < 0 || n > int64(len(d.data)) {
+		d.err = errInvalidSerializedScript
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
@@ -5,6 +5,9 @@
 package v8go_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	v8 "github.com/couchbasedeps/v8go"
@@ -45,3 +48,108 @@ func TestUnboundScriptRun_OnlyInTheSameIsolate(t *testing.T) {
 		t.Error("expected panic running unbound script in a context belonging to a different isolate")
 	}
 }
+
+func TestUnboundScriptSerialize(t *testing.T) {
+	t.Parallel()
+	i1 := v8.NewIsolate()
+	defer i1.Dispose()
+
+	source := "function fail() { throw new Error('x') }; fail()"
+	origin := v8.ScriptOrigin{ResourceName: "lib.js", LineOffset: 9}
+	us, err := i1.CompileUnboundScript(source, "", v8.CompileOptions{Origin: &origin})
+	fatalIf(t, err)
+
+	path := filepath.Join(t.TempDir(), "lib.js.bin")
+	fatalIf(t, os.WriteFile(path, us.Serialize(source, origin), 0644))
+
+	i2 := v8.NewIsolate()
+	defer i2.Dispose()
+	data, err := os.ReadFile(path)
+	fatalIf(t, err)
+	loaded, _, err := i2.DeserializeUnboundScript(data)
+	fatalIf(t, err)
+
+	ctx := v8.NewContext(i2)
+	defer ctx.Close()
+	_, err = loaded.Run(ctx)
+	if e, ok := err.(*v8.JSError); !ok || e.Location != "lib.js:10:19" {
+		t.Errorf("expected error from the original origin, got %v", err)
+	}
+
+	// A loaded script can be serialized again.
+	if _, _, err := i1.DeserializeUnboundScript(loaded.Serialize(source, origin)); err != nil {
+		t.Error(err)
+	}
+}
+
+// Not parallel, as a V8 flag changed by another test while it runs would make V8
+// reject the code cache.
+func TestUnboundScriptSerialize_CacheAccepted(t *testing.T) {
+	i1 := v8.NewIsolate()
+	defer i1.Dispose()
+
+	source := "function add(a, b) { return a + b }; add(40, 2)"
+	origin := v8.ScriptOrigin{ResourceName: "add.js"}
+	us, err := i1.CompileUnboundScript(source, "add.js", v8.CompileOptions{})
+	fatalIf(t, err)
+
+	i2 := v8.NewIsolate()
+	defer i2.Dispose()
+	loaded, rejected, err := i2.DeserializeUnboundScript(us.Serialize(source, origin))
+	fatalIf(t, err)
+	if rejected {
+		t.Error("expected the code cache to be accepted")
+	}
+	ctx := v8.NewContext(i2)
+	defer ctx.Close()
+	if val, err := loaded.Run(ctx); err != nil || val.Int32() != 42 {
+		t.Errorf("expected 42, got %v, %v", val, err)
+	}
+
+	// The code cache doesn't match a different source, so the script is compiled from
+	// the source instead.
+	loaded, rejected, err = i2.DeserializeUnboundScript(us.Serialize("add(1, 2)", origin))
+	fatalIf(t, err)
+	if !rejected {
+		t.Error("expected the code cache to be rejected")
+	}
+	if val, err := loaded.Run(ctx); err != nil || val.Int32() != 3 {
+		t.Errorf("expected 3, got %v, %v", val, err)
+	}
+}
+
+func TestUnboundScriptSerialize_Stream(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	source := "6 * 7"
+	us, err := iso.CompileUnboundScriptStream(strings.NewReader(source), "stream.js")
+	fatalIf(t, err)
+	loaded, _, err := iso.DeserializeUnboundScript(us.Serialize(source, v8.ScriptOrigin{ResourceName: "stream.js"}))
+	fatalIf(t, err)
+
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	val, err := loaded.Run(ctx)
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("expected 42, got %v", val)
+	}
+}
+
+func TestIsolateDeserializeUnboundScript_Invalid(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	us, err := iso.CompileUnboundScript("1", "one.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	data := us.Serialize("1", v8.ScriptOrigin{ResourceName: "one.js"})
+
+	for _, invalid := range [][]byte{nil, []byte("6 * 7"), data[:len(data)-1], append(data, 0)} {
+		if _, _, err := iso.DeserializeUnboundScript(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -96,6 +96,18 @@ namespace v8go {
     return rtn;
   }
 
+  ScriptOrigin NewScriptOrigin(Isolate* iso, ScriptOriginData const& o, bool isModule) {
+    Local<String> name = String::NewFromUtf8(iso, o.resourceName, NewStringType::kNormal,
+                                             o.resourceNameLen).ToLocalChecked();
+    Local<Value> sourceMapUrl;
+    if (o.sourceMapUrlLen > 0) {
+      sourceMapUrl = String::NewFromUtf8(iso, o.sourceMapUrl, NewStringType::kNormal,
+                                         o.sourceMapUrlLen).ToLocalChecked();
+    }
+    return ScriptOrigin(iso, name, o.lineOffset, o.columnOffset, o.isSharedCrossOrigin,
+                        o.scriptId, sourceMapUrl, o.isOpaque, false, isModule);
+  }
+
 }
 
 
@@ -105,6 +117,9 @@ const char* V8Version() {
   return V8::GetVersion();
 }
 
-void SetV8Flags(const char* flags) {
-  V8::SetFlagsFromString(flags);
+// Sets the flags in argv[1...], and moves any that V8 does not recognize to the
+// front of argv, returning how many of them there are.
+int SetV8FlagsFromCommandLine(int argc, char** argv) {
+  V8::SetFlagsFromCommandLine(&argc, argv, true);
+  return argc - 1;
 }
@@ -11,6 +11,7 @@ package v8go
 // #include <stdlib.h>
 import "C"
 import (
+	"fmt"
 	"strings"
 	"unsafe"
 )
@@ -23,9 +24,41 @@ func Version() string {
 // SetFlags sets flags for V8. For possible flags: https://github.com/v8/v8/blob/master/src/flags/flag-definitions.h
 // Flags are expected to be prefixed with `--`, for example: `--harmony`.
 // Flags can be reverted using the `--no` prefix equivalent, for example: `--use_strict` vs `--nouse_strict`.
-// Flags will affect all Isolates created, even after creation.
+// A flag that takes a value is given as `--name=value` or `--name value`, for example: `--max-old-space-size=512`.
+// Flags will affect all Isolates created, even after creation, though flags that configure an
+// Isolate when it is created (such as heap sizes) only apply to Isolates created afterwards.
+// Unrecognized flags are ignored; use SetFlagsChecked to detect them.
 func SetFlags(flags ...string) {
-	cflags := C.CString(strings.Join(flags, " "))
-	C.SetV8Flags(cflags)
-	C.free(unsafe.Pointer(cflags))
+	_ = SetFlagsChecked(flags...)
+}
+
+// SetFlagsChecked sets flags for V8 like SetFlags, but returns an error listing the
+// arguments V8 did not recognize, such as unknown flags or values without a flag. All the
+// recognized flags are still set.
+func SetFlagsChecked(flags ...string) error {
+	args := strings.Fields(strings.Join(flags, " "))
+	argv := make([]*C.char, len(args)+1)
+	argv[0] = C.CString("v8go")
+	for i, arg := range args {
+		argv[i+1] = C.CString(arg)
+	}
+	cArgv := (**C.char)(C.malloc(C.size_t(len(argv)) * C.size_t(unsafe.Sizeof(argv[0]))))
+	copy((*[1 << 28]*C.char)(unsafe.Pointer(cArgv))[:len(argv):len(argv)], argv)
+	defer func() {
+		for _, arg := range argv {
+			C.free(unsafe.Pointer(arg))
+		}
+		C.free(unsafe.Pointer(cArgv))
+	}()
+
+	unknown := int(C.SetV8FlagsFromCommandLine(C.int(len(argv)), cArgv))
+	if unknown > 0 {
+		remaining := (*[1 << 28]*C.char)(unsafe.Pointer(cArgv))[1 : unknown+1 : unknown+1]
+		names := make([]string, unknown)
+		for i, arg := range remaining {
+			names[i] = C.GoString(arg)
+		}
+		return fmt.Errorf("v8go: unrecognized V8 flags: %s", strings.Join(names, " "))
+	}
+	return nil
 }
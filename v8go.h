@@ -28,6 +28,12 @@ typedef struct WithIsolate* WithIsolatePtr;
 typedef struct V8GoContext* ContextPtr;
 typedef struct V8GoTemplate* TemplatePtr;
 typedef struct V8GoUnboundScript* UnboundScriptPtr;
+typedef struct V8GoScriptStreamer* ScriptStreamerPtr;
+typedef struct V8GoInspectorSession* InspectorSessionPtr;
+typedef struct V8GoModule* ModulePtr;
+typedef struct V8GoBackingStore* BackingStorePtr;
+typedef struct V8GoPersistent* PersistentPtr;
+typedef struct V8GoPrivate* PrivatePtr;
 
 #endif
 
@@ -62,12 +68,30 @@ typedef struct {
   const char* stack;
 } RtnError;
 
+typedef struct {
+  const char* resourceName;
+  int resourceNameLen;
+  int lineOffset;
+  int columnOffset;
+  int scriptId;
+  Bool isSharedCrossOrigin;
+  Bool isOpaque;
+  const char* sourceMapUrl;
+  int sourceMapUrlLen;
+} ScriptOriginData;
+
 typedef struct {
   UnboundScriptPtr ptr;
   int cachedDataRejected;
   RtnError error;
 } RtnUnboundScript;
 
+typedef struct {
+  ModulePtr ptr;
+  int cachedDataRejected;
+  RtnError error;
+} RtnModule;
+
 typedef struct {
   ScriptCompilerCachedDataPtr ptr;
   const uint8_t* data;
@@ -80,6 +104,14 @@ typedef struct {
   int compileOption;
 } CompileOptions;
 
+typedef struct {
+  const char* msg;
+  int line;
+  int startColumn;
+  int endColumn;
+  const char* sourceLine;
+} RtnSyntaxError;
+
 typedef struct {
   CpuProfilerPtr ptr;
   IsolatePtr iso;
@@ -118,6 +150,16 @@ typedef struct {
   RtnError error;
 } RtnString;
 
+typedef struct {  // A frame of the stack, returned by IsolateCurrentStackTrace
+  RtnString functionName;
+  RtnString resourceName;
+  int line;         // 1-based, or 0 if unknown
+  int column;       // 1-based, or 0 if unknown
+  int scriptID;
+  Bool isEval;
+  Bool isConstructor;
+} StackFrameData;
+
 typedef struct {
   size_t total_heap_size;
   size_t total_heap_size_executable;
@@ -132,6 +174,14 @@ typedef struct {
   size_t number_of_detached_contexts;
 } IsolateHStatistics;
 
+typedef struct {
+  const char* space_name;  // (static; not to be freed)
+  size_t space_size;
+  size_t space_used_size;
+  size_t space_available_size;
+  size_t physical_space_size;
+} HeapSpaceStatisticsData;
+
 typedef struct {
   const uint64_t* word_array;
   int word_count;
@@ -152,28 +202,154 @@ typedef enum {    // This MUST be kept in sync with `ValueType` in value.go!
   Object_val,
 } ValueType;
 
+typedef struct {
+  void* data;
+  size_t length;
+} ByteArray;
+
+typedef struct {
+  void* data;
+  size_t length;
+  RtnError error;
+} RtnBytes;
+
+typedef enum {    // This MUST be kept in sync with `ArrayBufferViewKind` in value.go!
+  Int8Array_type = 0,
+  Uint8Array_type,
+  Int16Array_type,
+  Uint16Array_type,
+  Int32Array_type,
+  Uint32Array_type,
+  Float32Array_type,
+  Float64Array_type,
+  BigInt64Array_type,
+  BigUint64Array_type,
+  Uint8ClampedArray_type,
+  DataView_type,
+} TypedArrayType;
+
+typedef enum {    // The well-known symbols available through SymbolWellKnown
+  AsyncIterator_sym = 0,
+  HasInstance_sym,
+  Iterator_sym,
+  ToPrimitive_sym,
+  ToStringTag_sym,
+} WellKnownSymbol;
+
+typedef enum {    // The functions of `Object` that ObjectCallBuiltin can call
+  ObjectIsFrozen_fn = 0,
+  ObjectIsSealed_fn,
+  ObjectIsExtensible_fn,
+  ObjectPreventExtensions_fn,
+} ObjectBuiltin;
+
+typedef enum {    // The kinds of error ContextNewError and IsolateNewError can create
+  Error_err = 0,
+  TypeError_err,
+  RangeError_err,
+  SyntaxError_err,
+  ReferenceError_err,
+} ErrorKind;
+
+typedef enum {    // The flags passed to ObjectDefineProperty
+  PropertyDescriptorWritable = 1 << 0,
+  PropertyDescriptorEnumerable = 1 << 1,
+  PropertyDescriptorConfigurable = 1 << 2,
+} PropertyDescriptorFlags;
+
+typedef struct {
+  int allowed;
+  ValuePtr modifiedSource;
+} CodeGenerationResult;
+
+typedef enum {    // The kinds of named property interceptor, as in NamedPropertyHandler
+  NamedGetter_op = 0,
+  NamedSetter_op,
+  NamedQuery_op,
+  NamedDeleter_op,
+  NamedEnumerator_op,
+} InterceptorOp;
+
+typedef enum {    // The steps in the life of a promise, as in v8::PromiseHookType
+  PromiseInit_hook = 0,
+  PromiseResolve_hook,
+  PromiseBefore_hook,
+  PromiseAfter_hook,
+} PromiseHookKind;
+
+typedef enum {    // How a FunctionCallback's result is given, as in CallbackResult
+  ReturnValue_kind = 0,   // `value`, or undefined if it's empty
+  ReturnInt32_kind,       // `number` as an int32
+  ReturnUint32_kind,      // `number` as a uint32
+  ReturnDouble_kind,      // `number`
+  ReturnBool_kind,        // `number` != 0
+  ReturnNull_kind,
+} ReturnKind;
+
+typedef struct {  // The result of goFunctionCallback
+  ReturnKind kind;
+  ValuePtr value;
+  double number;
+} CallbackResult;
+
+typedef enum {    // The argument and result types of fast API calls, as in FastType
+  FastVoid_type = 0,
+  FastBool_type,
+  FastInt32_type,
+  FastUint32_type,
+  FastFloat32_type,
+  FastFloat64_type,
+} FastType;
+
+typedef struct {
+  Bool intercepted;   // If false, the property access proceeds as usual
+  ValuePtr value;     // The result of a getter or enumerator
+  int result;         // The result of a query (attributes) or deleter (success)
+} InterceptorResult;
+
 typedef struct {
   IsolatePtr isolate;
   ContextPtr internalContext;
   ValueRef undefinedVal, nullVal, falseVal, trueVal;
+  int snapshotFunctions;  // The number of Go functions the snapshot defines
 } NewIsolateResult;
 
 extern void Init();
-extern NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit);
+extern NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit,
+                                   const char* snapshot, int snapshotLen);
 extern void IsolatePerformMicrotaskCheckpoint(IsolatePtr ptr);
+extern void IsolateClearKeptObjects(IsolatePtr ptr);
+extern void IsolateCollectGarbage(IsolatePtr ptr);
+extern void IsolateMemoryPressureNotification(IsolatePtr ptr, int level);
+extern Bool IsolatePumpMessageLoop(IsolatePtr ptr);
 extern void IsolateDispose(IsolatePtr ptr);
 extern WithIsolatePtr IsolateLock(IsolatePtr);
 extern void IsolateUnlock(WithIsolatePtr);
 extern void IsolateTerminateExecution(IsolatePtr ptr);
+extern void IsolateCancelTerminateExecution(IsolatePtr ptr);
 extern int IsolateIsExecutionTerminating(IsolatePtr ptr);
 extern IsolateHStatistics IsolationGetHeapStatistics(IsolatePtr ptr);
+extern HeapSpaceStatisticsData* IsolateGetHeapSpaceStatistics(IsolatePtr ptr, int* count);
+extern void IsolateTakeHeapSnapshot(IsolatePtr ptr, uintptr_t writerHandle);
+extern void IsolateSetCodeGenerationFromStringsCallback(IsolatePtr ptr, Bool enable);
+extern void IsolateSetPromiseRejectCallback(IsolatePtr ptr, Bool enable);
+extern void IsolateSetPromiseHook(IsolatePtr ptr, Bool enable);
+extern void IsolateSetGCCallbacks(IsolatePtr ptr, uintptr_t handle, Bool enable);
+extern void IsolateSetNearHeapLimitCallback(IsolatePtr ptr, uintptr_t handle, Bool enable);
+extern StackFrameData* IsolateCurrentStackTrace(IsolatePtr ptr, int limit, int* count);
 
 extern ValueRef IsolateThrowException(IsolatePtr iso, ValuePtr value);
+extern ValueRef ContextNewError(ContextPtr ctx, ErrorKind kind, const char* msg, int msgLen);
+extern ValueRef IsolateNewError(IsolatePtr iso, ErrorKind kind, const char* msg, int msgLen,
+                                uintptr_t* ctx_ref);
 
 extern RtnUnboundScript IsolateCompileUnboundScript(IsolatePtr iso_ptr,
                                                     const char* source, int sourceLen,
-                                                    const char* origin, int originLen,
+                                                    ScriptOriginData origin,
                                                     CompileOptions options);
+extern RtnSyntaxError IsolateCheckSyntax(IsolatePtr iso_ptr,
+                                         const char* source, int sourceLen,
+                                         ScriptOriginData origin);
 extern ScriptCompilerCachedData* UnboundScriptCreateCodeCache(
     IsolatePtr iso_ptr,
     UnboundScriptPtr us_ptr);
@@ -181,8 +357,23 @@ extern void ScriptCompilerCachedDataDelete(
     ScriptCompilerCachedData* cached_data);
 extern RtnValue UnboundScriptRun(ContextPtr ctx_ptr, UnboundScriptPtr us_ptr);
 
+extern ScriptStreamerPtr NewScriptStreamer(IsolatePtr iso_ptr);
+extern void ScriptStreamerPush(ScriptStreamerPtr ptr, const char* data, int length);
+extern RtnValue ScriptStreamerRun(ScriptStreamerPtr ptr,
+                                  ContextPtr ctx_ptr,
+                                  const char* origin, int originLen);
+extern RtnUnboundScript ScriptStreamerCompileUnboundScript(ScriptStreamerPtr ptr,
+                                                           const char* origin, int originLen);
+extern void ScriptStreamerFree(ScriptStreamerPtr ptr);
+
+extern InspectorSessionPtr NewInspectorSession(IsolatePtr iso_ptr);
+extern RtnString InspectorSessionDispatch(InspectorSessionPtr ptr,
+                                          const char* message, int messageLen);
+extern void InspectorSessionDispose(InspectorSessionPtr ptr);
+
 extern CPUProfiler* NewCPUProfiler(IsolatePtr iso_ptr);
 extern void CPUProfilerDispose(CPUProfiler* ptr);
+extern void CPUProfilerSetSamplingInterval(CPUProfiler* ptr, int us);
 extern void CPUProfilerStartProfiling(CPUProfiler* ptr, const char* title);
 extern CPUProfile* CPUProfilerStopProfiling(CPUProfiler* ptr,
                                             const char* title);
@@ -194,10 +385,56 @@ extern ContextPtr NewContext(IsolatePtr iso_ptr,
 extern void ContextFree(ContextPtr ptr);
 extern RtnValue RunScript(ContextPtr ctx_ptr,
                           const char* source, int sourceLen,
-                          const char* origin, int originLen);
+                          ScriptOriginData origin,
+                          int compileOption);
+typedef struct {
+  const char* source;
+  int sourceLen;
+  ScriptOriginData origin;
+} ScriptSourceData;
+
+extern void RunScripts(ContextPtr ctx_ptr,
+                       int count,
+                       ScriptSourceData* scripts,
+                       RtnValue* results);
+extern RtnString CreateSnapshot(int count, ScriptSourceData* scripts, Bool keepCode,
+                                int functionCount, const char* functionNames, const int* nameLens);
+extern RtnValue ContextCompileFunction(ContextPtr ctx_ptr,
+                                       const char* body, int bodyLen,
+                                       ScriptOriginData origin,
+                                       int paramCount,
+                                       const char* params, const int* paramLens,
+                                       int extensionCount,
+                                       ValuePtr extensions[]);
+extern RtnValue ContextCallByPath(ContextPtr ctx_ptr,
+                                  const char* path, int pathLen,
+                                  Bool hasRecv, ValuePtr recv,
+                                  int argc, ValuePtr args[]);
 extern RtnValue JSONParse(ContextPtr ctx_ptr, const char* str, int len);
 extern RtnString JSONStringify(ValuePtr, void *buffer, int bufferSize);
 extern ValueRef ContextGlobal(ContextPtr ctx_ptr);
+extern void ContextSetAllowCodeGenerationFromStrings(ContextPtr ctx_ptr, Bool allow);
+extern Bool ContextIsCodeGenerationFromStringsAllowed(ContextPtr ctx_ptr);
+
+extern RtnModule CompileModule(ContextPtr ctx_ptr,
+                               const char* source, int sourceLen,
+                               ScriptOriginData origin,
+                               CompileOptions options);
+extern ScriptCompilerCachedData* ModuleCreateCodeCache(ContextPtr ctx_ptr, ModulePtr ptr);
+extern RtnModule NewSyntheticModule(ContextPtr ctx_ptr,
+                                    const char* name, int nameLen,
+                                    int exportCount,
+                                    const char* exportNames, const int* exportNameLens,
+                                    ValuePtr exportValues[]);
+extern RtnError ModuleInstantiate(ContextPtr ctx_ptr, ModulePtr ptr);
+extern RtnValue ModuleEvaluate(ContextPtr ctx_ptr, ModulePtr ptr);
+extern RtnError ModuleGetError(ContextPtr ctx_ptr, ModulePtr ptr);
+extern int ModuleGetStatus(ContextPtr ctx_ptr, ModulePtr ptr);
+extern ValueRef ModuleGetException(ContextPtr ctx_ptr, ModulePtr ptr);
+extern ValueRef ModuleGetModuleNamespace(ContextPtr ctx_ptr, ModulePtr ptr);
+extern int ModuleScriptId(ContextPtr ctx_ptr, ModulePtr ptr);
+extern int ModuleGetRequestCount(ContextPtr ctx_ptr, ModulePtr ptr);
+extern RtnString ModuleGetRequestSpecifier(ContextPtr ctx_ptr, ModulePtr ptr, int index);
 
 extern void TemplateFreeWrapper(TemplatePtr ptr);
 extern void TemplateSetValue(TemplatePtr ptr,
@@ -208,19 +445,36 @@ extern void TemplateSetTemplate(TemplatePtr ptr,
                                 const char* name, int nameLen,
                                 TemplatePtr obj_ptr,
                                 int attributes);
+extern void TemplateSetAccessorProperty(TemplatePtr ptr,
+                                        const char* name, int nameLen,
+                                        TemplatePtr getter, TemplatePtr setter,
+                                        int attributes);
 
 extern TemplatePtr NewObjectTemplate(IsolatePtr iso_ptr);
 extern RtnValue ObjectTemplateNewInstance(TemplatePtr ptr, ContextPtr ctx_ptr);
 extern void ObjectTemplateSetInternalFieldCount(TemplatePtr ptr,
                                                 int field_count);
 extern int ObjectTemplateInternalFieldCount(TemplatePtr ptr);
-
-extern TemplatePtr NewFunctionTemplate(IsolatePtr iso_ptr, int callback_ref);
+extern void ObjectTemplateSetNamedHandler(TemplatePtr ptr, int handler_ref, int ops);
+extern void ObjectTemplateSetCallAsFunctionHandler(TemplatePtr ptr, int callback_ref);
+extern void ObjectTemplateSetImmutableProto(TemplatePtr ptr);
+extern void TemplateSetLazyDataProperty(TemplatePtr ptr, const char* name, int nameLen,
+                                        int handler_ref, int attributes);
+
+extern TemplatePtr NewFunctionTemplate(IsolatePtr iso_ptr, int callback_ref, TemplatePtr receiver);
+extern TemplatePtr NewFastFunctionTemplate(IsolatePtr iso_ptr, int callback_ref,
+                                           const void* address, FastType result,
+                                           int argCount, const FastType* args);
 extern RtnValue FunctionTemplateGetFunction(TemplatePtr ptr,
                                             ContextPtr ctx_ptr);
+extern void FunctionTemplateInherit(TemplatePtr ptr, TemplatePtr parent);
+extern TemplatePtr FunctionTemplatePrototypeTemplate(TemplatePtr ptr);
+extern TemplatePtr FunctionTemplateInstanceTemplate(TemplatePtr ptr);
+extern void FunctionTemplateSetClassName(TemplatePtr ptr, const char* name, int nameLen);
 
 extern ValueScope PushValueScope(ContextPtr);
 extern Bool PopValueScope(ContextPtr, ValueScope);
+extern Bool ReleaseValue(ValuePtr);
 
 extern ValueRef NewValueInteger(ContextPtr, int32_t v);
 extern ValueRef NewValueIntegerFromUnsigned(ContextPtr, uint32_t v);
@@ -232,6 +486,7 @@ extern RtnValue NewValueBigIntFromWords(ContextPtr,
                                         int sign_bit,
                                         int word_count,
                                         const uint64_t* words);
+extern RtnValue NewValueDate(ContextPtr, double time);
 extern RtnString ValueToString(ValuePtr ptr, void *buffer, int bufferSize);
 const uint32_t* ValueToArrayIndex(ValuePtr ptr);
 int ValueToBoolean(ValuePtr ptr);
@@ -241,8 +496,13 @@ double ValueToNumber(ValuePtr ptr);
 RtnString ValueToDetailString(ValuePtr ptr);
 uint32_t ValueToUint32(ValuePtr ptr);
 extern ValueBigInt ValueToBigInt(ValuePtr ptr);
+extern int64_t ValueBigIntToInt64(ValuePtr ptr, Bool* lossless);
+extern uint64_t ValueBigIntToUint64(ValuePtr ptr, Bool* lossless);
+extern double ValueDateValue(ValuePtr ptr);
 extern RtnValue ValueToObject(ValuePtr ptr);
 int ValueSameValue(ValuePtr ptr, ValuePtr otherPtr);
+int ValueStrictEquals(ValuePtr ptr, ValuePtr otherPtr);
+RtnValue ValueInstanceOf(ValuePtr ptr, ValuePtr ctor);
 int ValueIsUndefined(ValuePtr ptr);
 int ValueIsNull(ValuePtr ptr);
 int ValueIsNullOrUndefined(ValuePtr ptr);
@@ -298,13 +558,28 @@ int ValueIsProxy(ValuePtr ptr);
 int ValueIsWasmModuleObject(ValuePtr ptr);
 int ValueIsModuleNamespaceObject(ValuePtr ptr);
 int /*ValueType*/ ValueGetType(ValuePtr ptr);
+int /*TypedArrayType*/ ValueGetArrayBufferViewType(ValuePtr ptr);
 
 extern ValueRef NewObject(ContextPtr);
 extern void ObjectSet(ValuePtr obj, const char* key, int keyLen, ValuePtr val_ptr);
 extern int ObjectSetKey(ValuePtr obj, ValuePtr key, ValuePtr val_ptr);
 extern void ObjectSetIdx(ValuePtr obj, uint32_t idx, ValuePtr val_ptr);
+extern RtnError ObjectDefineProperty(ValuePtr obj, ValuePtr key, ValuePtr value,
+                                     ValuePtr getter, ValuePtr setter, int flags);
+extern RtnValue ObjectSetAccessor(ValuePtr obj, const char* name, int nameLen, uintptr_t handle,
+                                  Bool getter, Bool setter, int attributes);
 extern int ObjectSetInternalField(ValuePtr obj, int idx, ValuePtr val_ptr);
+extern int ObjectSetInternalFieldHandle(ValuePtr obj, int idx, uintptr_t handle);
+extern uintptr_t ObjectGetInternalFieldHandle(ValuePtr obj, int idx);
 extern int ObjectInternalFieldCount(ValuePtr obj);
+extern int ObjectGetIdentityHash(ValuePtr obj);
+extern ValueRef ObjectGetPrototype(ValuePtr obj);
+extern RtnError ObjectSetPrototype(ValuePtr obj, ValuePtr proto);
+extern ValueRef ObjectClone(ValuePtr obj);
+extern uintptr_t ObjectGetCreationContext(ValuePtr obj);
+extern RtnError ObjectSetIntegrityLevel(ValuePtr obj, int level);
+extern RtnError ObjectDeepFreeze(ValuePtr obj);
+extern RtnError ObjectCallBuiltin(ValuePtr obj, ObjectBuiltin fn, Bool* result);
 extern RtnValue ObjectGet(ValuePtr obj, const char* key, int keyLen);
 extern RtnValue ObjectGetKey(ValuePtr obj, ValuePtr key);
 extern RtnValue ObjectGetIdx(ValuePtr obj, uint32_t idx);
@@ -312,12 +587,98 @@ extern ValuePtr ObjectGetInternalField(ValuePtr obj, int idx);
 extern int ObjectHas(ValuePtr obj, const char* key, int keyLen);
 extern int ObjectHasKey(ValuePtr obj, ValuePtr key);
 extern int ObjectHasIdx(ValuePtr obj, uint32_t idx);
+extern RtnError ObjectHasOwn(ValuePtr ptr, const char* key, int keyLen, Bool* result);
+extern RtnValue ObjectGetOwn(ValuePtr ptr, const char* key, int keyLen, Bool* found);
+extern RtnError ObjectDeleteOwn(ValuePtr ptr, const char* key, int keyLen, Bool* deleted);
 extern int ObjectDelete(ValuePtr obj, const char* key, int keyLen);
 extern int ObjectDeleteKey(ValuePtr obj, ValuePtr key);
 extern int ObjectDeleteIdx(ValuePtr obj, uint32_t idx);
+extern RtnValue ObjectGetOwnPropertyNames(ValuePtr obj);
+extern RtnError ObjectGetProperties(ValuePtr obj, ValuePtr names, uint32_t start, uint32_t end,
+                                    ValueRef* values, char** keys, int* key_lengths, uint32_t* count);
+
+extern RtnValue NewValueFromEncoded(ContextPtr ctx, const void* data, size_t length,
+                                    ValuePtr* refs);
+extern RtnBytes ValueEncode(ValuePtr ptr, ValueRef* refs, Bool* hasRefs);
+extern RtnBytes ValueSerialize(ValuePtr ptr);
+extern RtnValue ValueDeserialize(ContextPtr ctx, const void* data, size_t length);
+extern RtnValue ValueCloneInto(ValuePtr ptr, ContextPtr dst);
+
+extern PersistentPtr NewPersistent(ValuePtr ptr);
+extern ValueRef PersistentGet(PersistentPtr ptr, ContextPtr ctx);
+extern void PersistentMakeWeak(PersistentPtr ptr, uintptr_t handle);
+extern void PersistentRelease(PersistentPtr ptr);
+
+extern PrivatePtr NewPrivate(IsolatePtr iso_ptr, const char* name, int nameLen);
+extern void PrivateFreeWrapper(PrivatePtr ptr);
+extern int ObjectSetPrivate(ValuePtr obj, PrivatePtr key, ValuePtr val_ptr);
+extern RtnValue ObjectGetPrivate(ValuePtr obj, PrivatePtr key);
+extern int ObjectHasPrivate(ValuePtr obj, PrivatePtr key);
+extern int ObjectDeletePrivate(ValuePtr obj, PrivatePtr key);
+
+extern RtnValue NewExternalString(ContextPtr ctx, int twoByte, const void* data, size_t length,
+                                  uintptr_t handle);
+extern ValueRef NewSymbol(ContextPtr, const char* description, int descriptionLen);
+extern ValueRef SymbolWellKnown(ContextPtr, int /*WellKnownSymbol*/ which);
+extern RtnString SymbolDescription(ValuePtr ptr);
+
+extern RtnValue NewRegExp(ContextPtr ctx, const char* pattern, int patternLen,
+                          const char* flags, int flagsLen);
+extern RtnString RegExpSource(ValuePtr ptr);
+extern int RegExpFlags(ValuePtr ptr);
+extern RtnValue RegExpExec(ValuePtr ptr, const char* subject, int subjectLen);
+
+extern RtnValue NewProxy(ContextPtr ctx, ValuePtr target, ValuePtr handler);
+extern ValueRef ProxyGetTarget(ValuePtr ptr);
+extern ValueRef ProxyGetHandler(ValuePtr ptr);
+extern int ProxyIsRevoked(ValuePtr ptr);
+extern void ProxyRevoke(ValuePtr ptr);
+
+extern RtnValue ValueGetIterator(ValuePtr ptr);
+extern RtnValue IteratorNext(ValuePtr ptr, Bool* done);
+extern RtnError IteratorReturn(ValuePtr ptr);
+
+extern ValueRef NewMap(ContextPtr);
+extern uint32_t MapSize(ValuePtr ptr);
+extern RtnValue MapGet(ValuePtr ptr, ValuePtr key);
+extern RtnValue MapSet(ValuePtr ptr, ValuePtr key, ValuePtr val);
+extern int MapHas(ValuePtr ptr, ValuePtr key);
+extern int MapDelete(ValuePtr ptr, ValuePtr key);
+extern void MapClear(ValuePtr ptr);
+extern ValueRef MapAsArray(ValuePtr ptr);
+
+extern ValueRef NewSet(ContextPtr);
+extern uint32_t SetSize(ValuePtr ptr);
+extern RtnValue SetAdd(ValuePtr ptr, ValuePtr val);
+extern int SetHas(ValuePtr ptr, ValuePtr val);
+extern int SetDelete(ValuePtr ptr, ValuePtr val);
+extern void SetClear(ValuePtr ptr);
+extern ValueRef SetAsArray(ValuePtr ptr);
 
 extern ValueRef NewArray(ContextPtr, uint32_t length);
 extern uint32_t ArrayLength(ValuePtr ptr);
+extern RtnError ArrayGetValues(ValuePtr ptr, uint32_t start, uint32_t end, ValueRef* values);
+extern RtnError ArrayPush(ValuePtr ptr, int count, ValuePtr* values);
+
+extern RtnValue NewExternalTypedArray(ContextPtr ctx, int /*TypedArrayType*/ type,
+                                      void* data, size_t length, size_t byteLength,
+                                      uintptr_t handle);
+extern size_t TypedArrayLength(ValuePtr ptr);
+extern void TypedArrayRelease(ValuePtr ptr);
+
+extern ValueRef NewArrayBuffer(ContextPtr ctx, size_t byteLength);
+extern ByteArray ArrayBufferContents(ValuePtr ptr);
+extern size_t ArrayBufferByteLength(ValuePtr ptr);
+extern int ArrayBufferDetach(ValuePtr ptr);
+
+extern ValueRef NewDataView(ValuePtr buffer, size_t byteOffset, size_t byteLength);
+
+extern ValueRef NewSharedArrayBuffer(ContextPtr ctx, size_t byteLength);
+extern ValueRef NewSharedArrayBufferFromStore(ContextPtr ctx, BackingStorePtr ptr);
+extern size_t SharedArrayBufferByteLength(ValuePtr ptr);
+extern BackingStorePtr SharedArrayBufferGetBackingStore(ValuePtr ptr);
+extern ByteArray BackingStoreContents(BackingStorePtr ptr);
+extern void BackingStoreRelease(BackingStorePtr ptr);
 
 extern RtnValue NewPromiseResolver(ContextPtr ctx_ptr);
 extern ValueRef PromiseResolverGetPromise(ValuePtr ptr);
@@ -327,17 +688,24 @@ int PromiseState(ValuePtr ptr);
 RtnValue PromiseThen(ValuePtr ptr, int callback_ref);
 RtnValue PromiseThen2(ValuePtr ptr, int on_fulfilled_ref, int on_rejected_ref);
 RtnValue PromiseCatch(ValuePtr ptr, int callback_ref);
+RtnValue PromiseFinally(ValuePtr ptr, int callback_ref);
 extern ValueRef PromiseResult(ValuePtr ptr);
 
 extern RtnValue FunctionCall(ValuePtr ptr,
                              ValuePtr recv,
                              int argc,
                              ValuePtr argv[]);
+extern void FunctionCallBatch(ValuePtr ptr, ValuePtr recv, int count, const int* argCounts,
+                              ValuePtr args[], RtnValue* results);
 RtnValue FunctionNewInstance(ValuePtr ptr, int argc, ValuePtr args[]);
+extern Bool FunctionIsConstructor(ValuePtr ptr);
+extern RtnString FunctionName(ValuePtr ptr);
+extern int FunctionScriptID(ValuePtr ptr);
+extern RtnString FunctionLocation(ValuePtr ptr, int* line, int* column);
 ValueRef FunctionSourceMapUrl(ValuePtr ptr);
 
 const char* V8Version();
-extern void SetV8Flags(const char* flags);
+extern int SetV8FlagsFromCommandLine(int argc, char** argv);
 
 #ifdef __cplusplus
 }  // extern "C"
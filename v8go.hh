@@ -7,15 +7,20 @@
 
 #include "libplatform/libplatform.h"
 #include "v8.h"
+#include "v8-inspector.h"
 #include "v8-profiler.h"
 
 #include <cstdio>
 #include <cstdlib>
+#include <condition_variable>
 #include <cstring>
 #include <deque>
 #include <iostream>
+#include <mutex>
 #include <sstream>
 #include <string>
+#include <thread>
+#include <unordered_map>
 #include <vector>
 
 
@@ -32,11 +37,23 @@ namespace v8go {
   struct V8GoContext;
   struct V8GoTemplate;
   struct V8GoUnboundScript;
+  struct V8GoScriptStreamer;
+  struct V8GoInspectorSession;
+  struct V8GoModule;
+  struct V8GoBackingStore;
+  struct V8GoPersistent;
+  struct V8GoPrivate;
 }
 typedef struct v8go::WithIsolate* WithIsolatePtr;
 typedef struct v8go::V8GoContext* ContextPtr;
 typedef struct v8go::V8GoTemplate* TemplatePtr;
 typedef struct v8go::V8GoUnboundScript* UnboundScriptPtr;
+typedef struct v8go::V8GoScriptStreamer* ScriptStreamerPtr;
+typedef struct v8go::V8GoInspectorSession* InspectorSessionPtr;
+typedef struct v8go::V8GoModule* ModulePtr;
+typedef struct v8go::V8GoBackingStore* BackingStorePtr;
+typedef struct v8go::V8GoPersistent* PersistentPtr;
+typedef struct v8go::V8GoPrivate* PrivatePtr;
 
 
 #include "v8go.h"
@@ -56,8 +73,19 @@ namespace v8go {
 
   RtnError ExceptionError(TryCatch&, Isolate*, Local<Context>);
 
+  ScriptOrigin NewScriptOrigin(Isolate*, ScriptOriginData const&, bool isModule = false);
+
   void FunctionTemplateCallback(const FunctionCallbackInfo<Value>& info);
 
+  void SetCallbackResult(ReturnValue<Value> result, CallbackResult const& rtn);
+
+  void InitializeImportMetaObject(Local<Context>, Local<Module>, Local<Object> meta);
+
+  MaybeLocal<Promise> ImportModuleDynamically(Local<Context>,
+                                              Local<ScriptOrModule> referrer,
+                                              Local<String> specifier,
+                                              Local<FixedArray> import_assertions);
+
 
   /********** Internal Types **********/
 
@@ -73,6 +101,43 @@ namespace v8go {
   };
 
 
+  struct V8GoModule {
+    Persistent<Module, CopyablePersistentTraits<Module>> const ptr;
+
+    // The exports of a synthetic module, set when it is evaluated.
+    std::vector<std::pair<Persistent<String, CopyablePersistentTraits<String>>,
+                          Persistent<Value, CopyablePersistentTraits<Value>>>> syntheticExports;
+
+    V8GoModule(Isolate *iso, Local<Module> module)
+    :ptr(iso, module)
+    { }
+
+    // Prevents `new V8GoModule()` -- call m_ctx::newModule() instead.
+    static void* operator new(size_t) = delete;
+  };
+
+
+  // A reference to the backing store of a SharedArrayBuffer, which keeps it alive
+  // while Go holds it, so it can be shared with other Contexts and Isolates.
+  struct V8GoBackingStore {
+    std::shared_ptr<BackingStore> const store;
+  };
+
+
+  // A handle to a value that isn't tied to a Context's value table. If it's made weak,
+  // `weakHandle` is passed back to Go when the value is garbage collected.
+  struct V8GoPersistent {
+    Isolate* const iso;
+    Persistent<Value> handle;
+    uintptr_t weakHandle = 0;
+
+    V8GoPersistent(Isolate *iso, Local<Value> value)
+    :iso(iso)
+    ,handle(iso, value)
+    { }
+  };
+
+
   struct V8GoContext {
     V8GoContext(Isolate*, Local<Context>, uintptr_t goRef);
     ~V8GoContext();
@@ -84,16 +149,22 @@ namespace v8go {
     ValueRef addValue(Local<Value>);
 
     Local<Value> getValue(ValueRef);
+    bool releaseValue(ValueRef);
 
     uint32_t pushValueScope();
     bool popValueScope(uint32_t scopeID);
 
     V8GoUnboundScript* newUnboundScript(Local<UnboundScript>);
 
+    V8GoModule* newModule(Local<Module>);
+    V8GoModule* findModule(Local<Module>);
+
     Isolate* const iso;
     uintptr_t goRef;      // a runtime.cgo.Handle pointing to the Go Context
 
   private:
+    bool isLiveValue(ValueRef);
+
     using PersistentValue = Persistent<Value, CopyablePersistentTraits<Value>>;
 
     Persistent<Context> _ptr;
@@ -101,6 +172,8 @@ namespace v8go {
     std::vector<ValueRef> _savedScopes;
     ValueScope _latestScope = 1, _curScope = 1;
     std::deque<V8GoUnboundScript> _unboundScripts; // (deque does not invalidate refs when it grows)
+    std::deque<V8GoModule> _modules;
+    std::unordered_multimap<int, V8GoModule*> _modulesByHash; // Keyed by identity hash
   #ifdef CTX_LOG_VALUES
     size_t _nValues = 0, _maxValues = 0;
   #endif
@@ -113,10 +186,25 @@ namespace v8go {
   };
 
 
+  struct V8GoPrivate {
+    Isolate* iso;
+    Persistent<Private> ptr;
+  };
+
+
   static inline Local<Value> Deref(ValuePtr const& ptr) {
     return ptr.ctx->getValue(ptr.ref);
   }
 
+  static inline void buildCallArguments(Isolate* iso,
+                                        Local<Value>* argv,
+                                        int argc,
+                                        ValuePtr args[]) {
+    for (int i = 0; i < argc; i++) {
+      argv[i] = Deref(args[i]);
+    }
+  }
+
 
   /********** "With..." Scope Classes **********/
 
@@ -37,3 +37,28 @@ func TestSetFlag(t *testing.T) {
 		t.Errorf("expected <nil> error, but got: %v", err)
 	}
 }
+
+func TestSetFlagsChecked(t *testing.T) {
+	t.Parallel()
+	if err := v8.SetFlagsChecked("--stack-size=984"); err != nil {
+		t.Errorf("expected <nil> error, but got: %v", err)
+	}
+
+	// A flag's value can be a separate argument.
+	if err := v8.SetFlagsChecked("--stack-size", "984"); err != nil {
+		t.Errorf("expected <nil> error, but got: %v", err)
+	}
+	if err := v8.SetFlagsChecked("--stack-size 984"); err != nil {
+		t.Errorf("expected <nil> error, but got: %v", err)
+	}
+
+	err := v8.SetFlagsChecked("use_strict")
+	if err == nil || err.Error() != "v8go: unrecognized V8 flags: use_strict" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = v8.SetFlagsChecked("--stack-size=984 --no-such-flag", "--nor-this-one")
+	if err == nil || err.Error() != "v8go: unrecognized V8 flags: --no-such-flag --nor-this-one" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -45,6 +45,11 @@ RtnValue NewValueBigIntFromWords(ContextPtr ctx,
   return _with.returnValue(BigInt::NewFromWords(_with.local_ctx, sign_bit, word_count, words));
 }
 
+RtnValue NewValueDate(ContextPtr ctx, double time) {
+  WithContext _with(ctx);
+  return _with.returnValue(Date::New(_with.local_ctx, time));
+}
+
 
 /********** Value Conversion **********/
 
@@ -65,21 +70,50 @@ int ValueToBoolean(ValuePtr ptr) {
   return _with.value->BooleanValue(_with.iso());
 }
 
+// The numeric conversions below would throw a TypeError for a BigInt, so BigInts
+// are converted to their low 64 bits, or for ValueToNumber, to the nearest double.
+
 int32_t ValueToInt32(ValuePtr ptr) {
   WithValue _with(ptr);
+  if (_with.value->IsBigInt()) {
+    return static_cast<int32_t>(_with.value.As<BigInt>()->Int64Value());
+  }
   return _with.value->Int32Value(_with.local_ctx).ToChecked();
 }
 
 int64_t ValueToInteger(ValuePtr ptr) {
   WithValue _with(ptr);
+  if (_with.value->IsBigInt()) {
+    return _with.value.As<BigInt>()->Int64Value();
+  }
   return _with.value->IntegerValue(_with.local_ctx).ToChecked();
 }
 
 double ValueToNumber(ValuePtr ptr) {
   WithValue _with(ptr);
+  if (_with.value->IsBigInt()) {
+    String::Utf8Value str(_with.iso(), _with.value);
+    return strtod(*str, nullptr);
+  }
   return _with.value->NumberValue(_with.local_ctx).ToChecked();
 }
 
+int64_t ValueBigIntToInt64(ValuePtr ptr, Bool* lossless) {
+  WithValue _with(ptr);
+  bool ok = false;
+  int64_t v = _with.value->IsBigInt() ? _with.value.As<BigInt>()->Int64Value(&ok) : 0;
+  *lossless = ok;
+  return v;
+}
+
+uint64_t ValueBigIntToUint64(ValuePtr ptr, Bool* lossless) {
+  WithValue _with(ptr);
+  bool ok = false;
+  uint64_t v = _with.value->IsBigInt() ? _with.value.As<BigInt>()->Uint64Value(&ok) : 0;
+  *lossless = ok;
+  return v;
+}
+
 RtnString ValueToDetailString(ValuePtr ptr) {
   WithValue _with(ptr);
   RtnString rtn = {0};
@@ -104,6 +138,9 @@ RtnString ValueToString(ValuePtr ptr, void *buffer, int bufferSize) {
 
 uint32_t ValueToUint32(ValuePtr ptr) {
   WithValue _with(ptr);
+  if (_with.value->IsBigInt()) {
+    return static_cast<uint32_t>(_with.value.As<BigInt>()->Uint64Value());
+  }
   return _with.value->Uint32Value(_with.local_ctx).ToChecked();
 }
 
@@ -122,6 +159,14 @@ ValueBigInt ValueToBigInt(ValuePtr ptr) {
   return rtn;
 }
 
+double ValueDateValue(ValuePtr ptr) {
+  WithValue _with(ptr);
+  if (!_with.value->IsDate()) {
+    return std::numeric_limits<double>::quiet_NaN();
+  }
+  return _with.value.As<Date>()->ValueOf();
+}
+
 RtnValue ValueToObject(ValuePtr ptr) {
   WithValue _with(ptr);
   return _with.returnValue(_with.value->ToObject(_with.local_ctx));
@@ -142,6 +187,31 @@ int ValueSameValue(ValuePtr val1, ValuePtr val2) {
   return value1->SameValue(value2);
 }
 
+int ValueStrictEquals(ValuePtr val1, ValuePtr val2) {
+  Isolate* iso = val1.ctx->iso;
+  if (iso != val2.ctx->iso) {
+    return false;
+  }
+  WithIsolate _withiso(iso);
+  Local<Value> value1 = Deref(val1);
+  Local<Value> value2 = Deref(val2);
+
+  return value1->StrictEquals(value2);
+}
+
+// Returns a boolean value, or an error if the check throws, e.g. if ctor is not callable.
+RtnValue ValueInstanceOf(ValuePtr ptr, ValuePtr ctor) {
+  WithValue _with(ptr);
+  RtnValue rtn = {};
+  bool result;
+  if (_with.value->InstanceOf(_with.local_ctx, Deref(ctor).As<Object>()).To(&result)) {
+    rtn.value = _with.returnValue(Boolean::New(_with.iso(), result));
+  } else {
+    rtn.error = _with.exceptionError();
+  }
+  return rtn;
+}
+
 using ValuePredicate = bool (Value::*)() const;
 
 // The guts of ValueIsXXXX(). Takes a pointer to Value::IsXXXX().
@@ -224,3 +294,482 @@ int /*ValueType*/ ValueGetType(ValuePtr ptr) {
     return Other_val;
   }
 }
+
+// Returns the TypedArrayType of an ArrayBufferView, or -1 if the value is not one.
+int /*TypedArrayType*/ ValueGetArrayBufferViewType(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<Value> val = _with.value;
+  if (!val->IsArrayBufferView())   return -1;
+  if (val->IsInt8Array())          return Int8Array_type;
+  if (val->IsUint8Array())         return Uint8Array_type;
+  if (val->IsInt16Array())         return Int16Array_type;
+  if (val->IsUint16Array())        return Uint16Array_type;
+  if (val->IsInt32Array())         return Int32Array_type;
+  if (val->IsUint32Array())        return Uint32Array_type;
+  if (val->IsFloat32Array())       return Float32Array_type;
+  if (val->IsFloat64Array())       return Float64Array_type;
+  if (val->IsBigInt64Array())      return BigInt64Array_type;
+  if (val->IsBigUint64Array())     return BigUint64Array_type;
+  if (val->IsUint8ClampedArray())  return Uint8ClampedArray_type;
+  return DataView_type;
+}
+
+
+/********** Persistent Handles **********/
+
+PersistentPtr NewPersistent(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return new V8GoPersistent(_with.iso(), _with.value);
+}
+
+ValueRef PersistentGet(PersistentPtr ptr, ContextPtr ctx) {
+  WithContext _with(ctx);
+  return _with.returnValue(ptr->handle.Get(ptr->iso));
+}
+
+// Called during garbage collection, so it must not use V8; the Go callback mustn't either.
+static void persistentWeakCallback(const WeakCallbackInfo<V8GoPersistent>& info) {
+  V8GoPersistent* ptr = info.GetParameter();
+  ptr->handle.Reset();
+  uintptr_t weakHandle = ptr->weakHandle;
+  ptr->weakHandle = 0;
+  goPersistentCollected(weakHandle);
+  delete ptr;
+}
+
+void PersistentMakeWeak(PersistentPtr ptr, uintptr_t handle) {
+  WithIsolate _withiso(ptr->iso);
+  ptr->weakHandle = handle;
+  ptr->handle.SetWeak(ptr, persistentWeakCallback, WeakCallbackType::kParameter);
+}
+
+void PersistentRelease(PersistentPtr ptr) {
+  {
+    WithIsolate _withiso(ptr->iso);
+    ptr->handle.Reset();
+  }
+  delete ptr;
+}
+
+
+/********** External Strings **********/
+
+namespace {
+  // A string resource over external memory. When V8 no longer needs the memory, it
+  // passes `handle` back to Go to release it.
+  template <class Base, typename Char>
+  class ExternalString : public Base {
+   public:
+    ExternalString(const Char* data, size_t length, uintptr_t handle)
+    :_data(data), _length(length), _handle(handle)
+    { }
+
+    const Char* data() const override {return _data;}
+    size_t length() const override {return _length;}
+
+    // Releases a resource that V8 didn't take ownership of.
+    void release() {Dispose();}
+
+   protected:
+    void Dispose() override {
+      goReleaseExternalMemory(_handle);
+      delete this;
+    }
+
+   private:
+    const Char* const _data;
+    size_t const _length;
+    uintptr_t const _handle;
+  };
+
+  using ExternalOneByteString = ExternalString<String::ExternalOneByteStringResource, char>;
+  using ExternalTwoByteString = ExternalString<String::ExternalStringResource, uint16_t>;
+}
+
+// Creates a string over external memory, without copying it. `length` is the number of
+// characters, which are Latin-1 bytes if `twoByte` is false, else UTF-16 code units.
+RtnValue NewExternalString(ContextPtr ctx, int twoByte, const void* data, size_t length,
+                           uintptr_t handle) {
+  WithContext _with(ctx);
+  MaybeLocal<String> str;
+  if (twoByte) {
+    auto resource = new ExternalTwoByteString(static_cast<const uint16_t*>(data), length, handle);
+    str = String::NewExternalTwoByte(_with.iso(), resource);
+    if (str.IsEmpty()) resource->release();
+  } else {
+    auto resource = new ExternalOneByteString(static_cast<const char*>(data), length, handle);
+    str = String::NewExternalOneByte(_with.iso(), resource);
+    if (str.IsEmpty()) resource->release();
+  }
+  if (str.IsEmpty() && !_with.try_catch.HasCaught()) {
+    _with.iso()->ThrowException(Exception::RangeError(
+        String::NewFromUtf8Literal(_with.iso(), "Invalid string length")));
+  }
+  return _with.returnValue(str);
+}
+
+
+/********** Symbol **********/
+
+ValueRef NewSymbol(ContextPtr ctx, const char* description, int descriptionLen) {
+  WithContext _with(ctx);
+  Local<String> desc = _with.makeString(description, NewStringType::kNormal, descriptionLen);
+  return ctx->addValue(Symbol::New(ctx->iso, desc));
+}
+
+ValueRef SymbolWellKnown(ContextPtr ctx, int /*WellKnownSymbol*/ which) {
+  WithContext _with(ctx);
+  Local<Symbol> sym;
+  switch (which) {
+    case AsyncIterator_sym: sym = Symbol::GetAsyncIterator(ctx->iso); break;
+    case HasInstance_sym:   sym = Symbol::GetHasInstance(ctx->iso); break;
+    case Iterator_sym:      sym = Symbol::GetIterator(ctx->iso); break;
+    case ToPrimitive_sym:   sym = Symbol::GetToPrimitive(ctx->iso); break;
+    case ToStringTag_sym:   sym = Symbol::GetToStringTag(ctx->iso); break;
+  }
+  return ctx->addValue(sym);
+}
+
+RtnString SymbolDescription(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<Value> desc = _with.value.As<Symbol>()->Description(_with.iso());
+  if (!desc->IsString()) {
+    return RtnString{};
+  }
+  return CopyString(_with.iso(), desc.As<String>());
+}
+
+
+/********** ValueSerializer **********/
+
+namespace {
+  class SerializerDelegate : public ValueSerializer::Delegate {
+   public:
+    explicit SerializerDelegate(Isolate* iso) :_iso(iso) { }
+
+    void ThrowDataCloneError(Local<String> message) override {
+      _iso->ThrowException(Exception::Error(message));
+    }
+
+   private:
+    Isolate* const _iso;
+  };
+}
+
+RtnBytes ValueSerialize(ValuePtr ptr) {
+  WithValue _with(ptr);
+  SerializerDelegate delegate(_with.iso());
+  ValueSerializer serializer(_with.iso(), &delegate);
+  serializer.WriteHeader();
+
+  RtnBytes rtn = {};
+  if (serializer.WriteValue(_with.local_ctx, _with.value).IsNothing()) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  // The buffer is allocated with realloc, so Go frees it with free.
+  std::pair<uint8_t*, size_t> buffer = serializer.Release();
+  rtn.data = buffer.first;
+  rtn.length = buffer.second;
+  return rtn;
+}
+
+RtnValue ValueDeserialize(ContextPtr ctx, const void* data, size_t length) {
+  WithContext _with(ctx);
+  ValueDeserializer deserializer(_with.iso(), static_cast<const uint8_t*>(data), length);
+  Local<Value> value;
+  if (deserializer.ReadHeader(_with.local_ctx).IsNothing() ||
+      !deserializer.ReadValue(_with.local_ctx).ToLocal(&value)) {
+    if (!_with.try_catch.HasCaught()) {
+      _with.iso()->ThrowException(Exception::Error(
+          String::NewFromUtf8Literal(_with.iso(), "Unable to deserialize cloned data.")));
+    }
+    RtnValue rtn = {};
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  RtnValue rtn = {};
+  rtn.value = ctx->addValue(value);
+  return rtn;
+}
+
+
+// Copies a value into another Context, which may be in another Isolate, using the
+// structured clone algorithm.
+RtnValue ValueCloneInto(ValuePtr ptr, ContextPtr dst) {
+  RtnBytes bytes = ValueSerialize(ptr);
+  if (bytes.data == nullptr) {
+    RtnValue rtn = {};
+    rtn.error = bytes.error;
+    return rtn;
+  }
+  RtnValue rtn = ValueDeserialize(dst, bytes.data, bytes.length);
+  free(bytes.data);
+  return rtn;
+}
+
+
+/********** Value Encoding **********/
+
+// Decodes the values encoded by the valueEncoder in value_of.go, which describe a tree of
+// JS values so that it can be created with a single call from Go.
+namespace {
+  class ValueDecoder {
+   public:
+    ValueDecoder(Local<Context> context, const uint8_t* data, size_t length, ValuePtr* refs)
+    :_context(context), _iso(context->GetIsolate()), _pos(data), _end(data + length), _refs(refs)
+    { }
+
+    MaybeLocal<Value> decode() {
+      switch (readByte()) {
+        case 'u': return Undefined(_iso);
+        case 'n': return Null(_iso);
+        case 't': return True(_iso);
+        case 'f': return False(_iso);
+        case 'd': return Number::New(_iso, read<double>());
+        case 'D': return Date::New(_context, read<double>());
+        case 's': return readString().FromMaybe(Local<String>());
+        case 'b': {
+          int sign = readByte();
+          uint32_t count = read<uint32_t>();
+          const uint64_t* words = reinterpret_cast<const uint64_t*>(take(count * sizeof(uint64_t)));
+          return BigInt::NewFromWords(_context, sign, count, words).FromMaybe(Local<BigInt>());
+        }
+        case 'B': {
+          uint32_t length = read<uint32_t>();
+          Local<ArrayBuffer> buffer = ArrayBuffer::New(_iso, length);
+          memcpy(buffer->GetBackingStore()->Data(), take(length), length);
+          return Uint8Array::New(buffer, 0, length);
+        }
+        case 'a': {
+          uint32_t count = read<uint32_t>();
+          std::vector<Local<Value>> elements(count);
+          for (auto& element : elements) {
+            if (!decode().ToLocal(&element)) return MaybeLocal<Value>();
+          }
+          return Array::New(_iso, elements.data(), count);
+        }
+        case 'o': {
+          uint32_t count = read<uint32_t>();
+          Local<Object> obj = Object::New(_iso);
+          for (uint32_t i = 0; i < count; i++) {
+            Local<String> key;
+            Local<Value> value;
+            if (!readString().ToLocal(&key) || !decode().ToLocal(&value) ||
+                obj->CreateDataProperty(_context, key, value).IsNothing()) {
+              return MaybeLocal<Value>();
+            }
+          }
+          return obj;
+        }
+        case 'r': return Deref(_refs[read<uint32_t>()]);
+        default: {
+          _iso->ThrowException(Exception::Error(
+              String::NewFromUtf8Literal(_iso, "Invalid encoded value")));
+          return MaybeLocal<Value>();
+        }
+      }
+    }
+
+   private:
+    const uint8_t* take(size_t n) {
+      const uint8_t* p = _pos;
+      _pos = (size_t(_end - _pos) >= n) ? _pos + n : _end;
+      return p;
+    }
+
+    uint8_t readByte() {
+      return (_pos < _end) ? *take(1) : 0;
+    }
+
+    template <typename T>
+    T read() {
+      T value = {};
+      if (size_t(_end - _pos) >= sizeof(T)) {
+        memcpy(&value, take(sizeof(T)), sizeof(T));
+      }
+      return value;
+    }
+
+    MaybeLocal<String> readString() {
+      uint32_t length = read<uint32_t>();
+      return String::NewFromUtf8(_iso, reinterpret_cast<const char*>(take(length)),
+                                 NewStringType::kNormal, length);
+    }
+
+    Local<Context> _context;
+    Isolate* _iso;
+    const uint8_t* _pos;
+    const uint8_t* const _end;
+    ValuePtr* _refs;
+  };
+}
+
+RtnValue NewValueFromEncoded(ContextPtr ctx, const void* data, size_t length, ValuePtr* refs) {
+  WithContext _with(ctx);
+  ValueDecoder decoder(_with.local_ctx, static_cast<const uint8_t*>(data), length, refs);
+  return _with.returnValue(decoder.decode());
+}
+
+// Encodes a JS value in the same format, for valueDecoder in value_unmarshal.go. Values that
+// have no counterpart in the format, such as functions, are encoded as references ('r')
+// followed by the value's index in the refs array, so that only the values Go actually
+// uses are added to the Context; Maps are encoded as 'm' followed by a count and the keys
+// and values, and Sets as arrays.
+namespace {
+  class ValueEncoder {
+   public:
+    explicit ValueEncoder(WithContext& with)
+    :_with(with), _context(with.local_ctx), _iso(with.iso())
+    { }
+
+    bool encode(Local<Value> value, int depth = 0) {
+      if (value->IsUndefined()) {
+        _out.push_back('u');
+      } else if (value->IsNull()) {
+        _out.push_back('n');
+      } else if (value->IsBoolean()) {
+        _out.push_back(value->IsTrue() ? 't' : 'f');
+      } else if (value->IsNumber()) {
+        _out.push_back('d');
+        write(value.As<Number>()->Value());
+      } else if (value->IsString()) {
+        _out.push_back('s');
+        writeString(value.As<String>());
+      } else if (value->IsBigInt()) {
+        Local<BigInt> bigint = value.As<BigInt>();
+        int sign = 0, count = bigint->WordCount();
+        std::vector<uint64_t> words(count);
+        bigint->ToWordsArray(&sign, &count, words.data());
+        _out.push_back('b');
+        _out.push_back(char(sign));
+        write(uint32_t(count));
+        for (int i = 0; i < count; i++) write(words[i]);
+      } else if (value->IsDate()) {
+        _out.push_back('D');
+        write(value.As<Date>()->ValueOf());
+      } else if (value->IsUint8Array()) {
+        Local<Uint8Array> array = value.As<Uint8Array>();
+        _out.push_back('B');
+        write(uint32_t(array->ByteLength()));
+        size_t pos = _out.size();
+        _out.resize(pos + array->ByteLength());
+        array->CopyContents(&_out[pos], array->ByteLength());
+      } else if (!value->IsObject() || value->IsFunction() || value->IsArrayBuffer() ||
+                 value->IsArrayBufferView() || value->IsSharedArrayBuffer() ||
+                 value->IsPromise() || value->IsRegExp()) {
+        if (_refs.IsEmpty()) {
+          _refs = Array::New(_iso);
+        }
+        uint32_t index = _refs->Length();
+        if (_refs->Set(_context, index, value).IsNothing()) {
+          return false;
+        }
+        _out.push_back('r');
+        write(index);
+      } else {
+        if (++depth > kMaxDepth) {
+          _iso->ThrowException(Exception::TypeError(String::NewFromUtf8Literal(
+              _iso, "Value is nested too deeply, or contains a circular reference")));
+          return false;
+        }
+        if (value->IsArray()) {
+          return encodeArray('a', value.As<Array>(), depth);
+        } else if (value->IsSet()) {
+          return encodeArray('a', value.As<Set>()->AsArray(), depth);
+        } else if (value->IsMap()) {
+          Local<Array> pairs = value.As<Map>()->AsArray();
+          _out.push_back('m');
+          write(uint32_t(pairs->Length() / 2));
+          return encodeElements(pairs, depth);
+        } else {
+          return encodeObject(value.As<Object>(), depth);
+        }
+      }
+      return true;
+    }
+
+    std::string const& output() const {return _out;}
+    Local<Array> refs() const {return _refs;}
+
+   private:
+    static constexpr int kMaxDepth = 1000;
+
+    template <typename T>
+    void write(T value) {
+      _out.append(reinterpret_cast<const char*>(&value), sizeof(T));
+    }
+
+    void writeString(Local<String> str) {
+      uint32_t length = str->Utf8Length(_iso);
+      write(length);
+      size_t pos = _out.size();
+      _out.resize(pos + length);
+      str->WriteUtf8(_iso, &_out[pos], length, nullptr, String::NO_NULL_TERMINATION);
+    }
+
+    bool encodeArray(char tag, Local<Array> array, int depth) {
+      _out.push_back(tag);
+      write(uint32_t(array->Length()));
+      return encodeElements(array, depth);
+    }
+
+    bool encodeElements(Local<Array> array, int depth) {
+      for (uint32_t i = 0; i < array->Length(); i++) {
+        Local<Value> element;
+        if (!array->Get(_context, i).ToLocal(&element) || !encode(element, depth)) {
+          return false;
+        }
+      }
+      return true;
+    }
+
+    bool encodeObject(Local<Object> obj, int depth) {
+      Local<Array> keys;
+      auto filter = static_cast<PropertyFilter>(ONLY_ENUMERABLE | SKIP_SYMBOLS);
+      if (!obj->GetOwnPropertyNames(_context, filter,
+                                    KeyConversionMode::kConvertToString).ToLocal(&keys)) {
+        return false;
+      }
+      _out.push_back('o');
+      write(uint32_t(keys->Length()));
+      for (uint32_t i = 0; i < keys->Length(); i++) {
+        Local<Value> key, value;
+        if (!keys->Get(_context, i).ToLocal(&key) || !obj->Get(_context, key).ToLocal(&value)) {
+          return false;
+        }
+        writeString(key.As<String>());
+        if (!encode(value, depth)) {
+          return false;
+        }
+      }
+      return true;
+    }
+
+    WithContext& _with;
+    Local<Context> _context;
+    Isolate* _iso;
+    std::string _out;
+    Local<Array> _refs;
+  };
+}
+
+RtnBytes ValueEncode(ValuePtr ptr, ValueRef* refs, Bool* hasRefs) {
+  WithValue _with(ptr);
+  RtnBytes rtn = {};
+  ValueEncoder encoder(_with);
+  if (!encoder.encode(_with.value)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  *hasRefs = !encoder.refs().IsEmpty();
+  if (*hasRefs) {
+    *refs = _with.ctx->addValue(encoder.refs());
+  }
+  // Go frees the buffer with free.
+  std::string const& output = encoder.output();
+  rtn.data = malloc(output.size());
+  rtn.length = output.size();
+  memcpy(rtn.data, output.data(), output.size());
+  return rtn;
+}
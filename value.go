@@ -16,8 +16,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
+	"reflect"
 	"strconv"
+	"time"
 	"unsafe"
 )
 
@@ -55,6 +58,21 @@ func (val *Value) valuePtr() C.ValuePtr {
 	}
 }
 
+// Release frees the value's handle immediately, so that its JS counterpart can be garbage
+// collected, rather than when its Context is closed or the WithTemporaryValues scope it
+// was created in ends. The Value, and any other Value wrapping the same handle, such as an
+// Object it was converted to, must not be used afterwards. Releasing a value twice, or
+// releasing the Isolate's shared `undefined`, `null`, `true` and `false` values, does nothing.
+func (v *Value) Release() {
+	iso := v.ctx.iso
+	if v.ctx.ptr == nil || (v.ctx == iso.internalContext &&
+		(v.ref == iso.undefined.ref || v.ref == iso.null.ref ||
+			v.ref == iso.falseVal.ref || v.ref == iso.trueVal.ref)) {
+		return
+	}
+	C.ReleaseValue(v.valuePtr())
+}
+
 // NewValue will create a primitive value; see Context.NewValue for details.
 // The Value is not associated with any particular
 // Context and will remain in memory until the Isolate is closed.
@@ -65,16 +83,47 @@ func NewValue(iso *Isolate, val interface{}) (*Value, error) {
 	return iso.internalContext.NewValue(val)
 }
 
+// NewValueBigInt creates a BigInt with the value of the given big.Int, which must not be nil.
+// The Value is associated with the Isolate's internal Context, like NewValue's.
+func NewValueBigInt(iso *Isolate, v *big.Int) (*Value, error) {
+	if iso == nil {
+		return nil, errors.New("v8go: failed to create new Value: Isolate cannot be <nil>")
+	}
+	ref, err := newValueFromBigInt(iso.internalContext.ptr, v)
+	if err != nil {
+		return nil, err
+	}
+	return &Value{ref, iso.internalContext}, nil
+}
+
+// maxDateMillis is the largest magnitude of a JS Date's time value, in milliseconds
+// since the Unix epoch; see https://tc39.es/ecma262/#sec-time-values-and-time-range.
+const maxDateMillis = 8.64e15
+
+// NewValueTime creates a JS Date in the given Context for the time t, truncated to
+// milliseconds. It returns an error if t is outside the range of a Date, which is
+// 100,000,000 days either side of the Unix epoch.
+func NewValueTime(ctx *Context, t time.Time) (*Value, error) {
+	sec := t.Unix()
+	ms := sec*1000 + int64(t.Nanosecond()/1e6)
+	if sec > maxDateMillis/1000 || sec < -maxDateMillis/1000-1 || ms > maxDateMillis || ms < -maxDateMillis {
+		return nil, fmt.Errorf("v8go: time %v is out of range for a Date", t)
+	}
+	return valueResult(ctx, C.NewValueDate(ctx.ptr, C.double(ms)))
+}
+
 // NewValue will create a primitive value. The Value is associated with this Context;
 // it becomes invalid and must not be used after this Context or its Isolate is closed.
 //
 // Go types recognized are: bool, int, uint, int32, uint32, int64, uint64, *big.Int,
-// float32, float64, json.Number, string, *v8.Value, *v8.Object.
+// float32, float64, json.Number, string, time.Time, *v8.Value, *v8.Object.
 //
-// If given an integer outside the range ±2^53, or a big.Int, it will create a BigInt.
+// If given an integer outside the range ±2^53, or a big.Int, it will create a BigInt;
+// Isolate.SetInt64Conversion changes how int, uint, int64 and uint64 are converted.
+// A time.Time creates a Date, as NewValueTime does.
 //
 // As a convenience, if passed a *v8.Value it returns the same Value,
-// and if passed a *v8.Object it returns the object's Value.
+// and if passed a *v8.Object, or any other type that embeds a Value, it returns that Value.
 func (c *Context) NewValue(val interface{}) (*Value, error) {
 	ctxPtr := c.ptr
 	var ref C.ValueRef
@@ -94,13 +143,13 @@ func (c *Context) NewValue(val interface{}) (*Value, error) {
 	case uint32:
 		ref = C.NewValueIntegerFromUnsigned(ctxPtr, C.uint(v))
 	case int64:
-		ref = newValueFromInt64(ctxPtr, v)
+		ref, err = newValueFromInt64(ctxPtr, c.iso.int64Conversion, v)
 	case uint64:
-		ref = newValueFromUint64(ctxPtr, v)
+		ref, err = newValueFromUint64(ctxPtr, c.iso.int64Conversion, v)
 	case int:
-		ref = newValueFromInt64(ctxPtr, int64(v))
+		ref, err = newValueFromInt64(ctxPtr, c.iso.int64Conversion, int64(v))
 	case uint:
-		ref = newValueFromUint64(ctxPtr, uint64(v))
+		ref, err = newValueFromUint64(ctxPtr, c.iso.int64Conversion, uint64(v))
 	case float32:
 		ref = C.NewValueNumber(ctxPtr, C.double(v))
 	case float64:
@@ -109,12 +158,16 @@ func (c *Context) NewValue(val interface{}) (*Value, error) {
 		ref, err = newValueFromBigInt(ctxPtr, v)
 	case json.Number:
 		ref, err = newValueFromJSONNumber(ctxPtr, v)
+	case time.Time:
+		return NewValueTime(c, v)
 	case *Value:
 		return v, nil
 	case *Object:
 		return v.Value, nil
 	case *Array:
 		return v.Value, nil
+	case Valuer:
+		return v.value(), nil
 	default:
 		err = ErrUnsupportedValueType
 	}
@@ -130,23 +183,63 @@ var ErrUnsupportedValueType = fmt.Errorf("v8go: unsupported value type")
 const kMaxFloat64SafeInt = 1<<53 - 1
 const kMinFloat64SafeInt = -kMaxFloat64SafeInt
 
-func newValueFromInt64(ctxPtr C.ContextPtr, v int64) C.ValueRef {
-	if v >= kMinFloat64SafeInt && v <= kMaxFloat64SafeInt {
-		return C.NewValueNumber(ctxPtr, C.double(v))
+// Int64Conversion selects how Go 64-bit integers (int, uint, int64 and uint64) are
+// converted to JS values by NewValue and NewValueOf.
+type Int64Conversion int
+
+const (
+	// Int64Auto converts integers within ±2^53 to a Number and any others to a BigInt.
+	// This is the default.
+	Int64Auto Int64Conversion = iota
+	// Int64AlwaysBigInt converts every 64-bit integer to a BigInt, so that JS code sees
+	// the same type regardless of its magnitude.
+	Int64AlwaysBigInt
+	// Int64NumberStrict converts integers to a Number, and fails with an error if one is
+	// outside ±2^53 and so can't be represented exactly.
+	Int64NumberStrict
+)
+
+// SetInt64Conversion sets how Go 64-bit integers are converted to JS values in this
+// Isolate's Contexts. Smaller integer types such as int32 are always converted to a Number.
+func (i *Isolate) SetInt64Conversion(conv Int64Conversion) {
+	i.int64Conversion = conv
+}
+
+func checkSafeInt(conv Int64Conversion, safe bool, v interface{}) error {
+	if conv == Int64NumberStrict && !safe {
+		return fmt.Errorf("v8go: integer %d can't be represented exactly as a Number", v)
+	}
+	return nil
+}
+
+func newValueFromInt64(ctxPtr C.ContextPtr, conv Int64Conversion, v int64) (C.ValueRef, error) {
+	safe := v >= kMinFloat64SafeInt && v <= kMaxFloat64SafeInt
+	if err := checkSafeInt(conv, safe, v); err != nil {
+		return C.ValueRef{}, err
+	}
+	if safe && conv != Int64AlwaysBigInt {
+		return C.NewValueNumber(ctxPtr, C.double(v)), nil
 	} else {
-		return C.NewValueBigInt(ctxPtr, C.int64_t(v))
+		return C.NewValueBigInt(ctxPtr, C.int64_t(v)), nil
 	}
 }
 
-func newValueFromUint64(ctxPtr C.ContextPtr, v uint64) C.ValueRef {
-	if v <= kMaxFloat64SafeInt {
-		return C.NewValueNumber(ctxPtr, C.double(v))
+func newValueFromUint64(ctxPtr C.ContextPtr, conv Int64Conversion, v uint64) (C.ValueRef, error) {
+	safe := v <= kMaxFloat64SafeInt
+	if err := checkSafeInt(conv, safe, v); err != nil {
+		return C.ValueRef{}, err
+	}
+	if safe && conv != Int64AlwaysBigInt {
+		return C.NewValueNumber(ctxPtr, C.double(v)), nil
 	} else {
-		return C.NewValueBigIntFromUnsigned(ctxPtr, C.uint64_t(v))
+		return C.NewValueBigIntFromUnsigned(ctxPtr, C.uint64_t(v)), nil
 	}
 }
 
 func newValueFromBigInt(ctxPtr C.ContextPtr, v *big.Int) (C.ValueRef, error) {
+	if v == nil {
+		return C.ValueRef{}, errors.New("v8go: nil *big.Int")
+	}
 	if v.IsInt64() {
 		return C.NewValueBigInt(ctxPtr, C.int64_t(v.Int64())), nil
 	}
@@ -169,14 +262,14 @@ func newValueFromBigInt(ctxPtr C.ContextPtr, v *big.Int) (C.ValueRef, error) {
 
 	rtn := C.NewValueBigIntFromWords(ctxPtr, C.int(sign), C.int(count), &words[0])
 	if rtn.error.msg != nil {
-		return C.ValueRef{}, newJSError(rtn.error)
+		return C.ValueRef{}, newJSError(nil, rtn.error)
 	}
 	return rtn.value, nil
 }
 
 func newValueFromJSONNumber(ctxPtr C.ContextPtr, val json.Number) (C.ValueRef, error) {
 	if i, err := val.Int64(); err == nil {
-		return newValueFromInt64(ctxPtr, i), nil
+		return newValueFromInt64(ctxPtr, Int64Auto, i)
 	} else if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
 		// if int conversion failed because it's too large, try a big.Int, which will be
 		// converted to a JS bigint:
@@ -244,6 +337,20 @@ func (v *Value) BigInt() *big.Int {
 	return b
 }
 
+// Time returns the time of a Date, in the local time zone, with millisecond precision.
+// It returns an error if the value is not a Date or is an invalid Date.
+func (v *Value) Time() (time.Time, error) {
+	if !v.IsDate() {
+		return time.Time{}, errors.New("v8go: value is not a Date")
+	}
+	ms := float64(C.ValueDateValue(v.valuePtr()))
+	if math.IsNaN(ms) {
+		return time.Time{}, errors.New("v8go: invalid Date")
+	}
+	sec := math.Floor(ms / 1000)
+	return time.Unix(int64(sec), int64(ms-sec*1000)*1e6), nil
+}
+
 // Boolean perform the equivalent of `Boolean(value)` in JS. This can never fail.
 func (v *Value) Boolean() bool {
 	return C.ValueToBoolean(v.valuePtr()) != 0
@@ -254,7 +361,7 @@ func (v *Value) DetailString() string {
 	rtn := C.ValueToDetailString(v.valuePtr())
 	if rtn.data == nil {
 		if rtn.error.msg != nil {
-			err := newJSError(rtn.error)
+			err := newJSError(v.ctx.iso, rtn.error)
 			panic(err) // TODO: Return a fallback value
 		}
 		return ""
@@ -263,6 +370,24 @@ func (v *Value) DetailString() string {
 	return C.GoStringN(rtn.data, rtn.length)
 }
 
+// BigIntInt64 returns the value of a BigInt as an int64, and whether it fits in an int64.
+// If it doesn't, the result is the BigInt truncated to 64 bits, like `BigInt.asIntN(64, value)`.
+// If the value is not a BigInt, it returns 0 and false.
+func (v *Value) BigIntInt64() (int64, bool) {
+	var lossless C.Bool
+	i := C.ValueBigIntToInt64(v.valuePtr(), &lossless)
+	return int64(i), lossless != 0
+}
+
+// BigIntUint64 returns the value of a BigInt as a uint64, and whether it fits in a uint64.
+// If it doesn't, the result is the BigInt truncated to 64 bits, like `BigInt.asUintN(64, value)`.
+// If the value is not a BigInt, it returns 0 and false.
+func (v *Value) BigIntUint64() (uint64, bool) {
+	var lossless C.Bool
+	u := C.ValueBigIntToUint64(v.valuePtr(), &lossless)
+	return uint64(u), lossless != 0
+}
+
 // Int32 perform the equivalent of `Number(value)` in JS and convert the result to a
 // signed 32-bit integer by performing the steps in https://tc39.es/ecma262/#sec-toint32.
 func (v *Value) Int32() int32 {
@@ -271,11 +396,31 @@ func (v *Value) Int32() int32 {
 
 // Integer perform the equivalent of `Number(value)` in JS and convert the result to an integer.
 // Negative values are rounded up, positive values are rounded down. NaN is converted to 0.
-// Infinite values yield undefined results.
+// Infinite values yield undefined results. A BigInt is converted without going through a
+// Number, so it is exact if it fits in an int64; see BigIntInt64.
 func (v *Value) Integer() int64 {
 	return int64(C.ValueToInteger(v.valuePtr()))
 }
 
+// Int64 returns the value as an int64 without any loss of precision, unlike Integer. The
+// value must be a BigInt that fits in an int64, or a Number that is an integer within ±2^53;
+// otherwise it returns an error.
+func (v *Value) Int64() (int64, error) {
+	if v.IsBigInt() {
+		if i, ok := v.BigIntInt64(); ok {
+			return i, nil
+		}
+		return 0, fmt.Errorf("v8go: BigInt %s overflows an int64", v.String())
+	} else if v.IsNumber() {
+		f := v.Number()
+		if f == math.Trunc(f) && f >= kMinFloat64SafeInt && f <= kMaxFloat64SafeInt {
+			return int64(f), nil
+		}
+		return 0, fmt.Errorf("v8go: Number %v is not an exact integer", f)
+	}
+	return 0, errors.New("v8go: value is not a Number or BigInt")
+}
+
 // Number perform the equivalent of `Number(value)` in JS.
 func (v *Value) Number() float64 {
 	return float64(C.ValueToNumber(v.valuePtr()))
@@ -322,6 +467,25 @@ func (v *Value) SameValue(other *Value) bool {
 	return C.ValueSameValue(v.valuePtr(), other.valuePtr()) != 0
 }
 
+// StrictEquals returns true if the other value is strictly equal to this one.
+// This is equivalent to `v === other` in JS, which differs from SameValue in that
+// NaN is not equal to itself and 0 is equal to -0.
+func (v *Value) StrictEquals(other *Value) bool {
+	return C.ValueStrictEquals(v.valuePtr(), other.valuePtr()) != 0
+}
+
+// InstanceOf returns true if the value is an instance of the constructor, i.e. the
+// equivalent of `v instanceof ctor` in JS. error will be of type `JSError` if the check
+// throws, for example if ctor is not callable.
+func (v *Value) InstanceOf(ctor *Object) (bool, error) {
+	rtn := C.ValueInstanceOf(v.valuePtr(), ctor.valuePtr())
+	result, err := valueResult(v.ctx, rtn)
+	if err != nil {
+		return false, err
+	}
+	return result.Boolean(), nil
+}
+
 // Enumeration returned by Value.GetType to distinguish between common types of Values.
 type ValueType int8
 
@@ -344,6 +508,33 @@ func (v *Value) GetType() ValueType {
 	return ValueType(C.ValueGetType(v.valuePtr()))
 }
 
+// Enumeration returned by Value.ArrayBufferViewKind to distinguish between the kinds of
+// ArrayBufferView: the typed arrays and DataView.
+type ArrayBufferViewKind int8
+
+const (
+	Int8ArrayKind ArrayBufferViewKind = iota
+	Uint8ArrayKind
+	Int16ArrayKind
+	Uint16ArrayKind
+	Int32ArrayKind
+	Uint32ArrayKind
+	Float32ArrayKind
+	Float64ArrayKind
+	BigInt64ArrayKind
+	BigUint64ArrayKind
+	Uint8ClampedArrayKind
+	DataViewKind
+
+	NotArrayBufferView ArrayBufferViewKind = -1
+)
+
+// ArrayBufferViewKind returns which kind of typed array or DataView this value is, or
+// NotArrayBufferView if it is neither, in a single call.
+func (v *Value) ArrayBufferViewKind() ArrayBufferViewKind {
+	return ArrayBufferViewKind(C.ValueGetArrayBufferViewType(v.valuePtr()))
+}
+
 // IsUndefined returns true if this value is the undefined value. See ECMA-262 4.3.10.
 func (v *Value) IsUndefined() bool {
 	return C.ValueIsUndefined(v.valuePtr()) != 0
@@ -673,3 +864,105 @@ func (v *Value) MarshalJSON() ([]byte, error) {
 	}
 	return []byte(jsonStr), nil
 }
+
+// Export converts the value to a Go value, deeply:
+//   - undefined and null become nil
+//   - booleans become bool, numbers float64, BigInts *big.Int and strings string
+//   - Dates become time.Time
+//   - Arrays and Sets become []interface{}
+//   - Maps become map[interface{}]interface{}
+//   - other objects become map[string]interface{}, of their own enumerable properties
+//
+// It returns an error if the value is or contains a function or symbol, or an object
+// that contains itself.
+func (v *Value) Export() (interface{}, error) {
+	return v.export(nil)
+}
+
+// export converts the value to a Go value; ancestors are the objects it is within.
+func (v *Value) export(ancestors []*Value) (interface{}, error) {
+	switch {
+	case v.IsNullOrUndefined():
+		return nil, nil
+	case v.IsBoolean():
+		return v.Boolean(), nil
+	case v.IsNumber():
+		return v.Number(), nil
+	case v.IsBigInt():
+		return v.BigInt(), nil
+	case v.IsString():
+		return v.String(), nil
+	case v.IsDate():
+		return v.Time()
+	case v.IsSymbol():
+		return nil, errors.New("v8go: can't convert a symbol to a Go value")
+	case v.IsFunction():
+		return nil, errors.New("v8go: can't convert a function to a Go value")
+	case !v.IsObject():
+		return nil, fmt.Errorf("v8go: can't convert %s to a Go value", v.DetailString())
+	}
+
+	for _, a := range ancestors {
+		if a.SameValue(v) {
+			return nil, errors.New("v8go: can't convert an object that contains itself to a Go value")
+		}
+	}
+	ancestors = append(ancestors, v)
+
+	switch {
+	case v.IsArray():
+		vals, err := (&Array{Object{v}}).Values()
+		if err != nil {
+			return nil, err
+		}
+		return exportValues(vals, ancestors)
+	case v.IsSet():
+		return exportValues((&Set{&Object{v}}).Values(), ancestors)
+	case v.IsMap():
+		pairs := (&Map{&Object{v}}).asArray()
+		m := make(map[interface{}]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, err := pairs[i].export(ancestors)
+			if err != nil {
+				return nil, err
+			}
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return nil, fmt.Errorf("v8go: can't convert a Map with a %T key to a Go map", key)
+			}
+			if m[key], err = pairs[i+1].export(ancestors); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	default:
+		rtn := C.ObjectGetOwnPropertyNames(v.valuePtr())
+		names, err := valueResult(v.ctx, rtn)
+		if err != nil {
+			return nil, err
+		}
+		obj := &Object{v}
+		keys := (&Array{Object{names}}).values()
+		m := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			prop, err := obj.GetKey(key)
+			if err != nil {
+				return nil, err
+			}
+			if m[key.String()], err = prop.export(ancestors); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+}
+
+func exportValues(vals []*Value, ancestors []*Value) ([]interface{}, error) {
+	s := make([]interface{}, len(vals))
+	for i, val := range vals {
+		var err error
+		if s[i], err = val.export(ancestors); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
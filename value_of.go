@@ -0,0 +1,557 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// NewValueOf converts a Go value, deeply, to a JS value in the given Context. The whole
+// value is created in a single call into V8, which is much faster than creating objects
+// and setting their properties one by one. The conversion is like encoding/json's:
+//   - nil pointers, interfaces, maps and slices become null
+//   - bools, numbers and strings become booleans, numbers and strings; integers outside
+//     the range ±2^53 become BigInts, as do big.Ints, and json.Numbers become either;
+//     Isolate.SetInt64Conversion changes how int, uint, int64 and uint64 are converted
+//   - time.Time becomes a Date
+//   - []byte becomes a Uint8Array, with a copy of the bytes
+//   - other slices and arrays become Arrays
+//   - maps with string or integer keys, and structs, become objects
+//   - Values, Objects, etc. are used as they are, so they can be embedded in Go values
+//
+// The exported fields of a struct become properties named after the field, which can be
+// changed with a `v8` or, failing that, a `json` field tag: `v8:"name"` names the property,
+// `v8:",omitempty"` omits it if the field is empty, and `v8:"-"` omits the field. The fields
+// of embedded structs are treated as fields of the outer struct.
+//
+// It returns an error for values that can't be converted, such as funcs and channels, and
+// for values that are nested too deeply, which is the case for circular references.
+func NewValueOf(ctx *Context, v interface{}) (*Value, error) {
+	e := valueEncoder{ctx: ctx}
+	if err := e.encodeAny(v, 0); err != nil {
+		return nil, err
+	}
+	return e.newValue()
+}
+
+// NewObjectFromMap creates an object with the entries of m as its properties, converting
+// them deeply as NewValueOf does. It's intended for documents decoded by encoding/json,
+// whose maps, slices, strings, float64s and bools are converted without using reflection.
+// A nil map creates an empty object.
+func NewObjectFromMap(ctx *Context, m map[string]interface{}) (*Object, error) {
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	val, err := NewValueOf(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{val}, nil
+}
+
+// NewArrayFromSlice creates an array of the items of s, converting them deeply as
+// NewValueOf does; see NewObjectFromMap. A nil slice creates an empty array.
+func NewArrayFromSlice(ctx *Context, s []interface{}) (*Array, error) {
+	if s == nil {
+		s = []interface{}{}
+	}
+	val, err := NewValueOf(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return &Array{Object{val}}, nil
+}
+
+func (e *valueEncoder) newValue() (*Value, error) {
+	var refs *C.ValuePtr
+	if len(e.refs) > 0 {
+		refs = &e.refs[0]
+	}
+	rtn := C.NewValueFromEncoded(e.ctx.ptr, unsafe.Pointer(&e.buf[0]), C.size_t(len(e.buf)), refs)
+	return valueResult(e.ctx, rtn)
+}
+
+// maxEncodingDepth limits how deeply values are nested, so that circular references are
+// detected, as they would otherwise be encoded endlessly.
+const maxEncodingDepth = 1000
+
+var errEncodingTooDeep = errors.New("v8go: value is nested too deeply, or contains a circular reference")
+
+var (
+	bigIntType     = reflect.TypeOf(big.Int{})
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	timeType       = reflect.TypeOf(time.Time{})
+	valuerType     = reflect.TypeOf((*Valuer)(nil)).Elem()
+)
+
+// valueEncoder encodes a Go value as a description of the JS value to create, which
+// is decoded by NewValueFromEncoded in value.cc.
+type valueEncoder struct {
+	ctx  *Context
+	buf  []byte
+	refs []C.ValuePtr // Values referred to by the encoding
+}
+
+// encodeAny encodes the types that encoding/json decodes into interface{} values directly,
+// and other types using reflection.
+func (e *valueEncoder) encodeAny(x interface{}, depth int) error {
+	switch x := x.(type) {
+	case nil:
+		e.buf = append(e.buf, 'n')
+	case bool:
+		if x {
+			e.buf = append(e.buf, 't')
+		} else {
+			e.buf = append(e.buf, 'f')
+		}
+	case float64:
+		e.encodeNumber(x)
+	case string:
+		e.buf = append(e.buf, 's')
+		return e.encodeString(x)
+	case []interface{}:
+		if x == nil {
+			e.buf = append(e.buf, 'n')
+			return nil
+		}
+		if depth++; depth > maxEncodingDepth {
+			return errEncodingTooDeep
+		}
+		e.buf = append(e.buf, 'a')
+		if err := e.encodeLength(len(x)); err != nil {
+			return err
+		}
+		for _, item := range x {
+			if err := e.encodeAny(item, depth); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		if x == nil {
+			e.buf = append(e.buf, 'n')
+			return nil
+		}
+		if depth++; depth > maxEncodingDepth {
+			return errEncodingTooDeep
+		}
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		e.buf = append(e.buf, 'o')
+		if err := e.encodeLength(len(keys)); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := e.encodeString(key); err != nil {
+				return err
+			}
+			if err := e.encodeAny(x[key], depth); err != nil {
+				return err
+			}
+		}
+	default:
+		return e.encode(reflect.ValueOf(x), depth)
+	}
+	return nil
+}
+
+func (e *valueEncoder) encode(rv reflect.Value, depth int) error {
+	if depth > maxEncodingDepth {
+		return errEncodingTooDeep
+	}
+	depth++
+
+	if !rv.IsValid() {
+		e.buf = append(e.buf, 'n')
+		return nil
+	}
+	switch rv.Type() {
+	case timeType:
+		return e.encodeTime(rv.Interface().(time.Time))
+	case bigIntType:
+		b := rv.Interface().(big.Int)
+		e.encodeBigInt(&b)
+		return nil
+	case jsonNumberType:
+		return e.encodeJSONNumber(json.Number(rv.String()))
+	}
+	if rv.Type().Implements(valuerType) && !(rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return e.encodeValue(rv.Interface().(Valuer).value())
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			e.buf = append(e.buf, 't')
+		} else {
+			e.buf = append(e.buf, 'f')
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		e.encodeNumber(float64(rv.Int()))
+	case reflect.Int, reflect.Int64:
+		return e.encodeInt(rv.Int(), e.ctx.iso.int64Conversion)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		e.encodeNumber(float64(rv.Uint()))
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		safe := u <= kMaxFloat64SafeInt
+		if err := checkSafeInt(e.ctx.iso.int64Conversion, safe, u); err != nil {
+			return err
+		}
+		if safe && e.ctx.iso.int64Conversion != Int64AlwaysBigInt {
+			e.encodeNumber(float64(u))
+		} else {
+			e.encodeBigInt(new(big.Int).SetUint64(u))
+		}
+	case reflect.Float32, reflect.Float64:
+		e.encodeNumber(rv.Float())
+	case reflect.String:
+		e.buf = append(e.buf, 's')
+		return e.encodeString(rv.String())
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return nil
+		} else if rv.Kind() == reflect.Interface && rv.CanInterface() {
+			return e.encodeAny(rv.Elem().Interface(), depth)
+		}
+		return e.encode(rv.Elem(), depth)
+	case reflect.Slice:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			e.buf = append(e.buf, 'B')
+			if err := e.encodeLength(rv.Len()); err != nil {
+				return err
+			}
+			e.buf = append(e.buf, rv.Bytes()...)
+			return nil
+		}
+		return e.encodeArray(rv, depth)
+	case reflect.Array:
+		return e.encodeArray(rv, depth)
+	case reflect.Map:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return nil
+		}
+		return e.encodeMap(rv, depth)
+	case reflect.Struct:
+		return e.encodeStruct(rv, depth)
+	default:
+		return fmt.Errorf("v8go: can't convert a value of type %s to a JS value", rv.Type())
+	}
+	return nil
+}
+
+func (e *valueEncoder) encodeNumber(f float64) {
+	e.buf = append(e.buf, 'd')
+	e.buf = appendUint64(e.buf, math.Float64bits(f))
+}
+
+func (e *valueEncoder) encodeInt(i int64, conv Int64Conversion) error {
+	safe := i >= kMinFloat64SafeInt && i <= kMaxFloat64SafeInt
+	if err := checkSafeInt(conv, safe, i); err != nil {
+		return err
+	}
+	if safe && conv != Int64AlwaysBigInt {
+		e.encodeNumber(float64(i))
+	} else {
+		e.encodeBigInt(big.NewInt(i))
+	}
+	return nil
+}
+
+func (e *valueEncoder) encodeBigInt(b *big.Int) {
+	words := b.Bits()
+	e.buf = append(e.buf, 'b')
+	if b.Sign() < 0 {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+	e.buf = appendUint32(e.buf, uint32(len(words)))
+	for _, w := range words {
+		e.buf = appendUint64(e.buf, uint64(w))
+	}
+}
+
+func (e *valueEncoder) encodeJSONNumber(n json.Number) error {
+	if i, err := n.Int64(); err == nil {
+		return e.encodeInt(i, Int64Auto)
+	} else if b, ok := new(big.Int).SetString(string(n), 10); ok {
+		e.encodeBigInt(b)
+	} else if f, err := n.Float64(); err == nil {
+		e.encodeNumber(f)
+	} else {
+		return fmt.Errorf("v8go: invalid json.Number %q", string(n))
+	}
+	return nil
+}
+
+func (e *valueEncoder) encodeTime(t time.Time) error {
+	sec := t.Unix()
+	ms := sec*1000 + int64(t.Nanosecond()/1e6)
+	if sec > maxDateMillis/1000 || sec < -maxDateMillis/1000-1 || ms > maxDateMillis || ms < -maxDateMillis {
+		return fmt.Errorf("v8go: time %v is out of range for a Date", t)
+	}
+	e.buf = append(e.buf, 'D')
+	e.buf = appendUint64(e.buf, math.Float64bits(float64(ms)))
+	return nil
+}
+
+func (e *valueEncoder) encodeValue(v *Value) error {
+	if v.ctx.iso != e.ctx.iso {
+		return errors.New("v8go: value belongs to a different isolate")
+	}
+	e.buf = append(e.buf, 'r')
+	e.buf = appendUint32(e.buf, uint32(len(e.refs)))
+	e.refs = append(e.refs, v.valuePtr())
+	return nil
+}
+
+func (e *valueEncoder) encodeLength(n int) error {
+	if uint64(n) > math.MaxUint32 {
+		return errors.New("v8go: value is too large to convert to a JS value")
+	}
+	e.buf = appendUint32(e.buf, uint32(n))
+	return nil
+}
+
+func (e *valueEncoder) encodeString(s string) error {
+	if err := e.encodeLength(len(s)); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, s...)
+	return nil
+}
+
+func (e *valueEncoder) encodeArray(rv reflect.Value, depth int) error {
+	e.buf = append(e.buf, 'a')
+	n := rv.Len()
+	if err := e.encodeLength(n); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := e.encode(rv.Index(i), depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *valueEncoder) encodeMap(rv reflect.Value, depth int) error {
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+	switch rv.Type().Key().Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return fmt.Errorf("v8go: can't convert a map with %s keys to a JS value", rv.Type().Key())
+	}
+	entries := make([]entry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		var key string
+		switch k.Kind() {
+		case reflect.String:
+			key = k.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			key = strconv.FormatUint(k.Uint(), 10)
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	// Sort the keys so that the order of the object's properties is deterministic.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	e.buf = append(e.buf, 'o')
+	if err := e.encodeLength(len(entries)); err != nil {
+		return err
+	}
+	for _, en := range entries {
+		if err := e.encodeString(en.key); err != nil {
+			return err
+		}
+		if err := e.encode(en.val, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *valueEncoder) encodeStruct(rv reflect.Value, depth int) error {
+	fields := structFields(rv.Type())
+	e.buf = append(e.buf, 'o')
+	countPos := len(e.buf)
+	e.buf = appendUint32(e.buf, 0)
+	count := 0
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		if err := e.encodeString(f.name); err != nil {
+			return err
+		}
+		if err := e.encode(fv, depth); err != nil {
+			return err
+		}
+		count++
+	}
+	*(*uint32)(unsafe.Pointer(&e.buf[countPos])) = uint32(count)
+	return nil
+}
+
+// The encoding uses the byte order of the machine, as it is decoded in the same process.
+func appendUint32(buf []byte, u uint32) []byte {
+	var b [4]byte
+	*(*uint32)(unsafe.Pointer(&b[0])) = u
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, u uint64) []byte {
+	var b [8]byte
+	*(*uint64)(unsafe.Pointer(&b[0])) = u
+	return append(buf, b[:]...)
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns false rather than
+// panicking if the field is in an embedded struct that a nil pointer points to.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// structField is a field of a struct that is converted to or from a JS property.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	readOnly  bool // (only used by BindStruct)
+}
+
+var structFieldCache sync.Map // map[reflect.Type][]structField
+
+// structFields returns the fields of a struct type that correspond to JS properties,
+// in the order they are declared.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	collectStructFields(t, nil, &fields)
+
+	// Where fields have the same name, the one least deeply embedded wins, as with Go's
+	// promoted fields; if there is a tie, neither is used.
+	byName := map[string][]int{}
+	for i, f := range fields {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	result := make([]structField, 0, len(fields))
+	for i, f := range fields {
+		winner := -1
+		for _, j := range byName[f.name] {
+			if winner < 0 || len(fields[j].index) < len(fields[winner].index) {
+				winner = j
+			} else if len(fields[j].index) == len(fields[winner].index) {
+				winner = -2
+				break
+			}
+		}
+		if winner == i {
+			result = append(result, f)
+		}
+	}
+	structFieldCache.Store(t, result)
+	return result
+}
+
+func collectStructFields(t reflect.Type, index []int, fields *[]structField) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("v8")
+		if !hasTag {
+			tag, hasTag = sf.Tag.Lookup("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, opts = tag[:comma], tag[comma+1:]
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			collectStructFields(ft, fieldIndex, fields)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		field := structField{name: name, index: fieldIndex}
+		for _, opt := range strings.Split(opts, ",") {
+			field.omitEmpty = field.omitEmpty || opt == "omitempty"
+			field.readOnly = field.readOnly || opt == "readonly"
+		}
+		*fields = append(*fields, field)
+	}
+}
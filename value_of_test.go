@@ -0,0 +1,164 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+type valueOfInner struct {
+	Z string `json:"zed"`
+}
+
+type valueOfStruct struct {
+	valueOfInner
+	Name     string            `v8:"name" json:"ignored"`
+	Count    int               `json:"count"`
+	Skip     bool              `v8:"-"`
+	Empty    []int             `v8:"empty,omitempty"`
+	Tags     []string          `json:"tags"`
+	Attrs    map[string]uint64 `json:"attrs"`
+	Bytes    []byte            `json:"bytes"`
+	When     time.Time         `json:"when"`
+	Big      *big.Int          `json:"big"`
+	Nil      *valueOfInner     `json:"nil"`
+	JS       *v8.Value         `json:"js"`
+	internal int
+}
+
+func TestNewValueOf(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	js, err := ctx.RunScript("[1, 2]", "")
+	fatalIf(t, err)
+
+	when := time.Date(2021, time.March, 4, 5, 6, 7, 8e6, time.UTC)
+	in := valueOfStruct{
+		valueOfInner: valueOfInner{Z: "z"},
+		Name:         "thing",
+		Count:        12,
+		Skip:         true,
+		Tags:         []string{"a", "b"},
+		Attrs:        map[string]uint64{"x": 1, "y": 1 << 60},
+		Bytes:        []byte{1, 2, 3},
+		When:         when,
+		Big:          big.NewInt(-5),
+		JS:           js,
+		internal:     99,
+	}
+	val, err := v8.NewValueOf(ctx, &in)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("v", val))
+
+	tests := [...]struct {
+		script   string
+		expected string
+	}{
+		{"JSON.stringify(Object.keys(v))", `["zed","name","count","tags","attrs","bytes","when","big","nil","js"]`},
+		{"v.zed", "z"},
+		{"v.name", "thing"},
+		{"v.count === 12", "true"},
+		{"v.tags.join('+')", "a+b"},
+		{"typeof v.attrs.y", "bigint"},
+		{"v.attrs.y === 1n << 60n", "true"},
+		{"v.bytes instanceof Uint8Array && v.bytes.join()", "1,2,3"},
+		{"v.when.toISOString()", "2021-03-04T05:06:07.008Z"},
+		{"v.big === -5n", "true"},
+		{"v.nil === null", "true"},
+		{"v.js[1]", "2"},
+	}
+	for _, tt := range tests {
+		result, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if s := result.String(); s != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.expected, s)
+		}
+	}
+
+	// Map keys are sorted.
+	val, err = v8.NewValueOf(ctx, map[int]string{10: "b", 2: "a"})
+	fatalIf(t, err)
+	if s, _ := v8.JSONStringify(ctx, val); s != `{"2":"a","10":"b"}` {
+		t.Errorf("unexpected map conversion %s", s)
+	}
+	val, err = v8.NewValueOf(ctx, nil)
+	fatalIf(t, err)
+	if !val.IsNull() {
+		t.Errorf("expected null, got %v", val)
+	}
+
+	if _, err := v8.NewValueOf(ctx, map[bool]int{}); err == nil {
+		t.Error("expected an error for a map with bool keys")
+	}
+	if _, err := v8.NewValueOf(ctx, []interface{}{func() {}}); err == nil {
+		t.Error("expected an error for a func")
+	}
+	cyclic := []interface{}{nil}
+	cyclic[0] = cyclic
+	if _, err := v8.NewValueOf(ctx, cyclic); err == nil {
+		t.Error("expected an error for a cyclic value")
+	}
+
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+	if _, err := v8.NewValueOf(ctx2, []*v8.Value{js}); err == nil {
+		t.Error("expected an error for a value from another isolate")
+	}
+}
+
+func TestNewObjectFromMap(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	var doc map[string]interface{}
+	fatalIf(t, json.Unmarshal([]byte(`{"id": "doc1", "n": 1.5, "ok": true, "none": null,
+		"tags": ["a", {"b": [2]}], "nested": {"deep": {"deeper": "yes"}}}`), &doc))
+	obj, err := v8.NewObjectFromMap(ctx, doc)
+	fatalIf(t, err)
+	s, err := v8.JSONStringify(ctx, obj)
+	fatalIf(t, err)
+	expected := `{"id":"doc1","n":1.5,"nested":{"deep":{"deeper":"yes"}},"none":null,"ok":true,"tags":["a",{"b":[2]}]}`
+	if s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+
+	arr, err := v8.NewArrayFromSlice(ctx, []interface{}{1.0, "two", []interface{}{int64(3)}, time.Unix(0, 0)})
+	fatalIf(t, err)
+	if arr.Length() != 4 {
+		t.Errorf("unexpected length %d", arr.Length())
+	}
+	if s, _ := v8.JSONStringify(ctx, arr); s != `[1,"two",[3],"1970-01-01T00:00:00.000Z"]` {
+		t.Errorf("unexpected array %s", s)
+	}
+
+	obj, err = v8.NewObjectFromMap(ctx, nil)
+	fatalIf(t, err)
+	arr, err = v8.NewArrayFromSlice(ctx, nil)
+	fatalIf(t, err)
+	if s, _ := v8.JSONStringify(ctx, obj); s != "{}" {
+		t.Errorf("expected an empty object, got %s", s)
+	}
+	if arr.Length() != 0 {
+		t.Errorf("expected an empty array, got length %d", arr.Length())
+	}
+
+	if _, err := v8.NewArrayFromSlice(ctx, []interface{}{make(chan int)}); err == nil {
+		t.Error("expected an error for a channel")
+	}
+}
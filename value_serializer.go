@@ -0,0 +1,48 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"unsafe"
+)
+
+// Serialize serializes the value with the structured clone algorithm, which `postMessage`
+// uses: unlike JSON, it preserves undefined, BigInts, Dates, RegExps, Maps, Sets, typed
+// arrays and ArrayBuffers, whose contents are copied, and objects that are referenced more
+// than once, including circular references. The data can be deserialized, in any Isolate,
+// with Context.DeserializeValue, and may be persisted, as later versions of V8 can read it.
+// error will be of type `JSError` if the value can't be cloned, e.g. because it is or
+// contains a function, a symbol or a SharedArrayBuffer.
+func (v *Value) Serialize() ([]byte, error) {
+	rtn := C.ValueSerialize(v.valuePtr())
+	if rtn.data == nil {
+		return nil, newJSError(v.ctx.iso, rtn.error)
+	}
+	defer C.free(rtn.data)
+	return C.GoBytes(rtn.data, C.int(rtn.length)), nil
+}
+
+// DeserializeValue creates a value in this Context from data returned by Value.Serialize.
+// error will be of type `JSError` if the data is invalid.
+func (c *Context) DeserializeValue(data []byte) (*Value, error) {
+	var ptr unsafe.Pointer
+	if len(data) > 0 {
+		ptr = unsafe.Pointer(&data[0])
+	}
+	rtn := C.ValueDeserialize(c.ptr, ptr, C.size_t(len(data)))
+	return valueResult(c, rtn)
+}
+
+// CloneInto copies the value into another Context, which may belong to another Isolate,
+// with the structured clone algorithm, as Serialize and DeserializeValue would, but without
+// copying the serialized data into Go. error will be of type `JSError` if the value can't
+// be cloned.
+func (v *Value) CloneInto(dst *Context) (*Value, error) {
+	rtn := C.ValueCloneInto(v.valuePtr(), dst.ptr)
+	return valueResult(dst, rtn)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestValueSerialize(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		const obj = {
+			u: undefined, big: 12345678901234567890n, date: new Date(0), re: /a+/gi,
+			map: new Map([[1, 'one']]), set: new Set(['x']), bytes: new Uint8Array([1, 2, 3]),
+		};
+		obj.self = obj;
+		obj`, "test.js")
+	fatalIf(t, err)
+	data, err := val.Serialize()
+	fatalIf(t, err)
+
+	// Deserialize it in another Isolate.
+	ctx2 := v8.NewContext()
+	defer ctx2.Isolate().Dispose()
+	defer ctx2.Close()
+	clone, err := ctx2.DeserializeValue(data)
+	fatalIf(t, err)
+	fatalIf(t, ctx2.Global().Set("obj", clone))
+	val, err = ctx2.RunScript(`
+		'u' in obj && obj.u === undefined && obj.big === 12345678901234567890n &&
+		obj.date.getTime() === 0 && obj.re.source === 'a+' && obj.re.flags === 'gi' &&
+		obj.map.get(1) === 'one' && obj.set.has('x') && obj.bytes instanceof Uint8Array &&
+		obj.bytes.join() === '1,2,3' && obj.self === obj`, "test.js")
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Error("deserialized value differs from the original")
+	}
+}
+
+func TestValueSerializeErrors(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	for _, source := range []string{"({f() {}})", "Symbol()", "new SharedArrayBuffer(1)"} {
+		val, err := ctx.RunScript(source, "test.js")
+		fatalIf(t, err)
+		if _, err := val.Serialize(); err == nil {
+			t.Errorf("%s: expected an error", source)
+		} else if _, ok := err.(*v8.JSError); !ok {
+			t.Errorf("%s: expected a JSError, got %T", source, err)
+		}
+	}
+
+	for _, data := range [][]byte{nil, {0xff}, {0xff, 0x0d, 0x99}} {
+		if _, err := ctx.DeserializeValue(data); err == nil {
+			t.Errorf("%v: expected an error", data)
+		}
+	}
+}
+
+func TestValueCloneInto(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+
+	val, err := ctx.RunScript(`let o = {n: 1, list: [1n, "two"], m: new Map([["k", new Date(5)]])}; o.self = o; o`, "")
+	fatalIf(t, err)
+	clone, err := val.CloneInto(ctx2)
+	fatalIf(t, err)
+	fatalIf(t, ctx2.Global().Set("c", clone))
+	result, err := ctx2.RunScript(`c.self === c && c.n === 1 && c.list[0] === 1n && c.m.get("k").getTime() === 5`, "")
+	fatalIf(t, err)
+	if !result.Boolean() {
+		t.Error("clone doesn't match the original")
+	}
+
+	fn, err := ctx.RunScript("(function() {})", "")
+	fatalIf(t, err)
+	if _, err := fn.CloneInto(ctx2); err == nil {
+		t.Error("expected an error cloning a function")
+	} else if _, ok := err.(*v8.JSError); !ok {
+		t.Errorf("expected a JSError, got %T", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"reflect"
 	"runtime"
 	"testing"
+	"time"
 
 	v8 "github.com/couchbasedeps/v8go"
 )
@@ -528,6 +529,214 @@ func TestValueBigInt(t *testing.T) {
 	}
 }
 
+func TestValueInt64Conversion(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	typeOf := func(x interface{}) string {
+		t.Helper()
+		val, err := ctx.NewValue(x)
+		fatalIf(t, err)
+		fatalIf(t, ctx.Global().Set("x", val))
+		result, err := ctx.RunScript("typeof x", "")
+		fatalIf(t, err)
+		return result.String()
+	}
+
+	if s := typeOf(int64(12)); s != "number" {
+		t.Errorf("expected a number by default, got %s", s)
+	}
+	if s := typeOf(uint64(1 << 60)); s != "bigint" {
+		t.Errorf("expected a bigint by default, got %s", s)
+	}
+
+	iso.SetInt64Conversion(v8.Int64AlwaysBigInt)
+	for _, x := range []interface{}{int64(12), uint64(12), 12, uint(12)} {
+		if s := typeOf(x); s != "bigint" {
+			t.Errorf("%T: expected a bigint, got %s", x, s)
+		}
+	}
+	if s := typeOf(int32(12)); s != "number" {
+		t.Errorf("expected int32 to stay a number, got %s", s)
+	}
+	val, err := v8.NewValueOf(ctx, map[string]interface{}{"id": int64(7), "n": 1.5})
+	fatalIf(t, err)
+	obj, err := val.AsObject()
+	fatalIf(t, err)
+	id, _ := obj.Get("id")
+	if !id.IsBigInt() {
+		t.Errorf("expected NewValueOf to create a bigint, got %s", id.DetailString())
+	}
+
+	iso.SetInt64Conversion(v8.Int64NumberStrict)
+	if s := typeOf(int64(1<<53 - 1)); s != "number" {
+		t.Errorf("expected a number, got %s", s)
+	}
+	if _, err := ctx.NewValue(int64(1 << 53)); err == nil {
+		t.Error("expected an error for a lossy int64")
+	}
+	if _, err := ctx.NewValue(uint64(1 << 63)); err == nil {
+		t.Error("expected an error for a lossy uint64")
+	}
+	if _, err := v8.NewValueOf(ctx, []int64{1, -1 << 60}); err == nil {
+		t.Error("expected NewValueOf to fail for a lossy int64")
+	}
+}
+
+func TestValueInt64(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		source   string
+		expected int64
+		ok       bool
+	}{
+		{"42", 42, true},
+		{"-9007199254740991", -(1<<53 - 1), true},
+		{"9223372036854775807n", 1<<63 - 1, true},
+		{"-5n", -5, true},
+		{"1.5", 0, false},
+		{"9007199254740992", 0, false},
+		{"NaN", 0, false},
+		{"9223372036854775808n", 0, false},
+		{"'42'", 0, false},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.source, "")
+		fatalIf(t, err)
+		i, err := val.Int64()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: unexpected error %v", tt.source, err)
+		} else if i != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.source, tt.expected, i)
+		}
+	}
+}
+
+func TestNewValueBigInt(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	x, _ := new(big.Int).SetString("-36893488147419099136", 10)
+	val, err := v8.NewValueBigInt(iso, x)
+	fatalIf(t, err)
+	if !val.IsBigInt() {
+		t.Fatalf("expected a BigInt, got %v", val)
+	}
+	if b := val.BigInt(); b.Cmp(x) != 0 {
+		t.Errorf("unexpected value: expected %v, got %v", x, b)
+	}
+	ctx.Global().Set("x", val)
+	res, err := ctx.RunScript("x === -36893488147419099136n", "test.js")
+	fatalIf(t, err)
+	if !res.Boolean() {
+		t.Error("expected the BigInt to equal its JS literal")
+	}
+
+	if _, err := v8.NewValueBigInt(iso, nil); err == nil {
+		t.Error("expected an error for a nil *big.Int")
+	}
+}
+
+func TestValueBigIntWords(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		source  string
+		i64     int64
+		i64ok   bool
+		u64     uint64
+		u64ok   bool
+		integer int64
+		number  float64
+	}{
+		{"0n", 0, true, 0, true, 0, 0},
+		{"-1n", -1, true, 1<<64 - 1, false, -1, -1},
+		{"9223372036854775807n", 1<<63 - 1, true, 1<<63 - 1, true, 1<<63 - 1, 9223372036854775807},
+		{"18446744073709551615n", -1, false, 1<<64 - 1, true, -1, 18446744073709551615},
+		{"18446744073709551617n", 1, false, 1, false, 1, 18446744073709551617},
+		{"42", 0, false, 0, false, 42, 42},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.source, func(t *testing.T) {
+			val, err := ctx.RunScript(tt.source, "test.js")
+			fatalIf(t, err)
+			if i, ok := val.BigIntInt64(); i != tt.i64 || ok != tt.i64ok {
+				t.Errorf("BigIntInt64: expected %v, %v, got %v, %v", tt.i64, tt.i64ok, i, ok)
+			}
+			if u, ok := val.BigIntUint64(); u != tt.u64 || ok != tt.u64ok {
+				t.Errorf("BigIntUint64: expected %v, %v, got %v, %v", tt.u64, tt.u64ok, u, ok)
+			}
+			if i := val.Integer(); i != tt.integer {
+				t.Errorf("Integer: expected %v, got %v", tt.integer, i)
+			}
+			if n := val.Number(); n != tt.number {
+				t.Errorf("Number: expected %v, got %v", tt.number, n)
+			}
+		})
+	}
+}
+
+func TestValueTime(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	want := time.Date(2021, time.November, 3, 14, 5, 6, 789123456, time.UTC)
+	val, err := v8.NewValueTime(ctx, want)
+	fatalIf(t, err)
+	if !val.IsDate() {
+		t.Fatalf("expected a Date, got %v", val)
+	}
+	ctx.Global().Set("d", val)
+	iso, err := ctx.RunScript("d instanceof Date && d.toISOString()", "test.js")
+	fatalIf(t, err)
+	if s := iso.String(); s != "2021-11-03T14:05:06.789Z" {
+		t.Errorf("unexpected ISO string: %s", s)
+	}
+
+	got, err := val.Time()
+	fatalIf(t, err)
+	if !got.Equal(want.Truncate(time.Millisecond)) {
+		t.Errorf("expected %v, got %v", want.Truncate(time.Millisecond), got)
+	}
+
+	val, err = ctx.NewValue(time.Unix(-1, 5e8))
+	fatalIf(t, err)
+	if got, _ := val.Time(); !got.Equal(time.Unix(-1, 5e8)) {
+		t.Errorf("unexpected time before the epoch: %v", got)
+	}
+
+	val, _ = ctx.RunScript("new Date(-8.64e15)", "test.js")
+	if got, err := val.Time(); err != nil || !got.Equal(time.Unix(-8.64e12, 0)) {
+		t.Errorf("unexpected earliest Date: %v, %v", got, err)
+	}
+
+	if _, err := v8.NewValueTime(ctx, time.Date(300000, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected an error for a time out of range")
+	}
+	for _, source := range []string{"new Date(NaN)", "Date.now()"} {
+		val, _ := ctx.RunScript(source, "test.js")
+		if _, err := val.Time(); err == nil {
+			t.Errorf("%s: expected an error", source)
+		}
+	}
+}
+
 func TestValueObject(t *testing.T) {
 	t.Parallel()
 
@@ -603,6 +812,74 @@ func TestValueSameValue(t *testing.T) {
 	}
 }
 
+func TestValueInstanceOf(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("class Foo {}; new Foo()", "")
+	fatalIf(t, err)
+	for _, tt := range []struct {
+		ctor     string
+		expected bool
+	}{
+		{"Foo", true},
+		{"Object", true},
+		{"Array", false},
+	} {
+		ctor, err := ctx.RunScript(tt.ctor, "")
+		fatalIf(t, err)
+		obj, err := ctor.AsObject()
+		fatalIf(t, err)
+		if ok, err := val.InstanceOf(obj); err != nil || ok != tt.expected {
+			t.Errorf("instanceof %s: expected %v, got %v, %v", tt.ctor, tt.expected, ok, err)
+		}
+	}
+
+	notCallable, err := ctx.RunScript("({})", "")
+	fatalIf(t, err)
+	obj, _ := notCallable.AsObject()
+	if _, err := val.InstanceOf(obj); err == nil {
+		t.Error("expected an error for a non-callable constructor")
+	} else if _, ok := err.(*v8.JSError); !ok {
+		t.Errorf("expected a JSError, got %T", err)
+	}
+}
+
+func TestValueStrictEquals(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		a, b         string
+		strict, same bool
+	}{
+		{"1", "1", true, true},
+		{"1", "'1'", false, false},
+		{"'abc'", "'ab' + 'c'", true, true},
+		{"NaN", "NaN", false, true},
+		{"0", "-0", true, false},
+		{"1n", "1n", true, true},
+		{"({})", "({})", false, false},
+		{"null", "undefined", false, false},
+	}
+	for _, tt := range tests {
+		a, err := ctx.RunScript(tt.a, "")
+		fatalIf(t, err)
+		b, err := ctx.RunScript(tt.b, "")
+		fatalIf(t, err)
+		if got := a.StrictEquals(b); got != tt.strict {
+			t.Errorf("%s === %s: expected %v, got %v", tt.a, tt.b, tt.strict, got)
+		}
+		if got := a.SameValue(b); got != tt.same {
+			t.Errorf("Object.is(%s, %s): expected %v, got %v", tt.a, tt.b, tt.same, got)
+		}
+	}
+}
+
 func TestValueIsXXX(t *testing.T) {
 	t.Parallel()
 	iso := v8.NewIsolate()
@@ -723,6 +1000,41 @@ func TestValueIsXXX(t *testing.T) {
 	}
 }
 
+func TestValueArrayBufferViewKind(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		source   string
+		expected v8.ArrayBufferViewKind
+	}{
+		{"new Int8Array", v8.Int8ArrayKind},
+		{"new Uint8Array", v8.Uint8ArrayKind},
+		{"new Int16Array", v8.Int16ArrayKind},
+		{"new Uint16Array", v8.Uint16ArrayKind},
+		{"new Int32Array", v8.Int32ArrayKind},
+		{"new Uint32Array", v8.Uint32ArrayKind},
+		{"new Float32Array", v8.Float32ArrayKind},
+		{"new Float64Array", v8.Float64ArrayKind},
+		{"new BigInt64Array", v8.BigInt64ArrayKind},
+		{"new BigUint64Array", v8.BigUint64ArrayKind},
+		{"new Uint8ClampedArray", v8.Uint8ClampedArrayKind},
+		{"new DataView(new ArrayBuffer(1))", v8.DataViewKind},
+		{"new ArrayBuffer(1)", v8.NotArrayBufferView},
+		{"[1, 2]", v8.NotArrayBufferView},
+		{"8", v8.NotArrayBufferView},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.source, "test.js")
+		fatalIf(t, err)
+		if kind := val.ArrayBufferViewKind(); kind != tt.expected {
+			t.Errorf("%s: expected kind %v, got %v", tt.source, tt.expected, kind)
+		}
+	}
+}
+
 func TestValueMarshalJSON(t *testing.T) {
 	t.Parallel()
 	iso := v8.NewIsolate()
@@ -0,0 +1,616 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// Unmarshal stores the value, deeply, in the Go value that target points to, the reverse of
+// NewValueOf. The JS value is read in a single call into V8, without going through JSON.
+// The conversion is like encoding/json's, with struct fields matched to properties using
+// `v8` or `json` field tags, and:
+//   - BigInts can be stored in integers, if they fit, and in big.Ints
+//   - Dates can be stored in time.Times
+//   - Uint8Arrays can be stored in []bytes
+//   - Maps can be stored in Go maps, and Sets in slices
+//   - any value can be stored in a *Value, and objects and functions in an *Object or
+//     *Function. Functions, symbols and other values with no Go equivalent are stored as they
+//     are; other values are copies.
+//
+// When target is an interface{}, values are stored as with Value.Export, except that values
+// with no Go equivalent are stored as *Values. A null or undefined value leaves a
+// non-pointer target unchanged. It returns an error if a value can't be stored in the
+// corresponding Go value, in which case error will be of type `*UnmarshalTypeError`, or if
+// reading it throws an exception, in which case error will be of type `JSError`.
+func (v *Value) Unmarshal(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("v8go: Unmarshal target must be a non-nil pointer")
+	}
+	var refs C.ValueRef
+	var hasRefs C.Bool
+	rtn := C.ValueEncode(v.valuePtr(), &refs, &hasRefs)
+	if rtn.data == nil {
+		return newJSError(v.ctx.iso, rtn.error)
+	}
+	defer C.free(rtn.data)
+	d := valueDecoder{ctx: v.ctx, buf: (*[1 << 34]byte)(rtn.data)[:rtn.length:rtn.length]}
+	if hasRefs != 0 {
+		d.refs = &Object{&Value{refs, v.ctx}}
+		defer d.refs.Release()
+	}
+	return d.decode(rv.Elem())
+}
+
+// As returns the value as a T, checking its type and converting it in one step, as
+// Value.Unmarshal does. For example:
+//
+//	n, err := v8go.As[int64](val)
+//
+// If the value can't be stored in a T, error will be of type `*UnmarshalTypeError`.
+func As[T any](val *Value) (T, error) {
+	var t T
+	err := val.Unmarshal(&t)
+	return t, err
+}
+
+// UnmarshalTypeError is returned by Value.Unmarshal when a JS value can't be stored in
+// a Go value of the given type.
+type UnmarshalTypeError struct {
+	Value string       // Description of the JS value, e.g. "string" or "number 1.5"
+	Type  reflect.Type // Type of the Go value it could not be stored in
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("v8go: can't store a JS %s in a Go value of type %s", e.Value, e.Type)
+}
+
+var (
+	valueType    = reflect.TypeOf((*Value)(nil))
+	objectType   = reflect.TypeOf((*Object)(nil))
+	functionType = reflect.TypeOf((*Function)(nil))
+)
+
+// valueDecoder decodes a JS value encoded by ValueEncode in value.cc, which uses the same
+// format as valueEncoder, except that a reference is followed by an index in refs.
+type valueDecoder struct {
+	ctx      *Context
+	buf      []byte
+	pos      int
+	refs     *Object // The values referenced by the encoding, if any
+	skipping bool    // Whether references are being skipped rather than read
+}
+
+var errInvalidEncoding = errors.New("v8go: invalid encoded value")
+
+func (d *valueDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, errInvalidEncoding
+	}
+	d.pos++
+	return d.buf[d.pos-1], nil
+}
+
+func (d *valueDecoder) take(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return nil, errInvalidEncoding
+	}
+	d.pos += n
+	return d.buf[d.pos-n : d.pos], nil
+}
+
+func (d *valueDecoder) readUint32() (int, error) {
+	b, err := d.take(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(*(*uint32)(unsafe.Pointer(&b[0]))), nil
+}
+
+func (d *valueDecoder) readFloat64() (float64, error) {
+	b, err := d.take(8)
+	if err != nil {
+		return 0, err
+	}
+	return *(*float64)(unsafe.Pointer(&b[0])), nil
+}
+
+func (d *valueDecoder) readString() (string, error) {
+	n, err := d.readUint32()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.take(n)
+	return string(b), err
+}
+
+func (d *valueDecoder) readBigInt() (*big.Int, error) {
+	sign, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.take(n * 8)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]big.Word, n)
+	for i := range words {
+		words[i] = big.Word(*(*uint64)(unsafe.Pointer(&b[i*8])))
+	}
+	x := new(big.Int).SetBits(words)
+	if sign != 0 {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+// readValue reads a reference, and gets the referenced value unless it is being skipped,
+// so that only the values that are used take up a slot in the Context.
+func (d *valueDecoder) readValue() (*Value, error) {
+	i, err := d.readUint32()
+	if err != nil || d.skipping {
+		return nil, err
+	}
+	if d.refs == nil {
+		return nil, errInvalidEncoding
+	}
+	return d.refs.GetIdx(uint32(i))
+}
+
+func (d *valueDecoder) readTime() (time.Time, error) {
+	ms, err := d.readFloat64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if math.IsNaN(ms) {
+		return time.Time{}, errors.New("v8go: invalid Date")
+	}
+	sec := math.Floor(ms / 1000)
+	return time.Unix(int64(sec), int64(ms-sec*1000)*1e6), nil
+}
+
+// decode decodes the next value and stores it in rv, which must be settable.
+func (d *valueDecoder) decode(rv reflect.Value) error {
+	start := d.pos
+	tag, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	switch rv.Type() {
+	case valueType, objectType, functionType:
+		var val *Value
+		if tag == 'r' {
+			val, err = d.readValue()
+		} else {
+			d.pos = start
+			var x interface{}
+			if err = d.decodeInterface(&x); err == nil {
+				val, err = NewValueOf(d.ctx, x)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		var target interface{} = val
+		switch rv.Type() {
+		case objectType:
+			target, err = val.AsObject()
+		case functionType:
+			target, err = val.AsFunction()
+		}
+		if err != nil {
+			val.Release()
+			return err
+		}
+		rv.Set(reflect.ValueOf(target))
+		return nil
+	}
+
+	if tag == 'n' || tag == 'u' {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		d.pos = start
+		return d.decode(rv.Elem())
+	}
+	if rv.Kind() == reflect.Interface {
+		if rv.NumMethod() > 0 {
+			return d.typeError(tag, rv.Type())
+		}
+		d.pos = start
+		var x interface{}
+		if err := d.decodeInterface(&x); err != nil {
+			return err
+		}
+		if x == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+
+	switch tag {
+	case 't', 'f':
+		if rv.Kind() != reflect.Bool {
+			return d.typeError(tag, rv.Type())
+		}
+		rv.SetBool(tag == 't')
+	case 'd':
+		f, err := d.readFloat64()
+		if err != nil {
+			return err
+		}
+		return d.storeNumber(f, rv)
+	case 'b':
+		x, err := d.readBigInt()
+		if err != nil {
+			return err
+		}
+		return d.storeBigInt(x, rv)
+	case 's':
+		s, err := d.readString()
+		if err != nil {
+			return err
+		}
+		if rv.Kind() != reflect.String {
+			return d.typeError(tag, rv.Type())
+		}
+		rv.SetString(s)
+	case 'D':
+		t, err := d.readTime()
+		if err != nil {
+			return err
+		}
+		if rv.Type() != timeType {
+			return d.typeError(tag, rv.Type())
+		}
+		rv.Set(reflect.ValueOf(t))
+	case 'B':
+		n, err := d.readUint32()
+		if err != nil {
+			return err
+		}
+		b, err := d.take(n)
+		if err != nil {
+			return err
+		}
+		if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Uint8 {
+			return d.typeError(tag, rv.Type())
+		}
+		rv.SetBytes(append([]byte{}, b...))
+	case 'a':
+		return d.decodeArray(rv)
+	case 'o':
+		return d.decodeObject(rv)
+	case 'm':
+		return d.decodeMap(rv)
+	case 'r':
+		if _, err := d.readUint32(); err != nil {
+			return err
+		}
+		return d.typeError(tag, rv.Type())
+	default:
+		return errInvalidEncoding
+	}
+	return nil
+}
+
+func (d *valueDecoder) storeNumber(f float64, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := int64(f); float64(i) == f && !rv.OverflowInt(i) {
+			rv.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := uint64(f); f >= 0 && float64(u) == f && !rv.OverflowUint(u) {
+			rv.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(f)
+		return nil
+	case reflect.String:
+		if rv.Type() == jsonNumberType {
+			rv.SetString(strconv.FormatFloat(f, 'g', -1, 64))
+			return nil
+		}
+	case reflect.Struct:
+		if rv.Type() == bigIntType && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			x, _ := big.NewFloat(f).Int(nil)
+			rv.Set(reflect.ValueOf(*x))
+			return nil
+		}
+	}
+	return &UnmarshalTypeError{Value: fmt.Sprintf("number %v", f), Type: rv.Type()}
+}
+
+func (d *valueDecoder) storeBigInt(x *big.Int, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if x.IsInt64() && !rv.OverflowInt(x.Int64()) {
+			rv.SetInt(x.Int64())
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if x.IsUint64() && !rv.OverflowUint(x.Uint64()) {
+			rv.SetUint(x.Uint64())
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		f, _ := new(big.Float).SetInt(x).Float64()
+		rv.SetFloat(f)
+		return nil
+	case reflect.String:
+		if rv.Type() == jsonNumberType {
+			rv.SetString(x.String())
+			return nil
+		}
+	case reflect.Struct:
+		if rv.Type() == bigIntType {
+			rv.Set(reflect.ValueOf(*x))
+			return nil
+		}
+	}
+	return &UnmarshalTypeError{Value: fmt.Sprintf("BigInt %s", x), Type: rv.Type()}
+}
+
+func (d *valueDecoder) decodeArray(rv reflect.Value) error {
+	n, err := d.readUint32()
+	if err != nil {
+		return err
+	}
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.Set(reflect.MakeSlice(rv.Type(), n, n))
+	case reflect.Array:
+	default:
+		return d.typeError('a', rv.Type())
+	}
+	for i := 0; i < n; i++ {
+		if i < rv.Len() {
+			err = d.decode(rv.Index(i))
+		} else {
+			err = d.skip() // Excess elements of a Go array are ignored, as in encoding/json
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for i := n; i < rv.Len(); i++ {
+		rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+	}
+	return nil
+}
+
+func (d *valueDecoder) decodeObject(rv reflect.Value) error {
+	n, err := d.readUint32()
+	if err != nil {
+		return err
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		for i := 0; i < n; i++ {
+			key, err := d.readString()
+			if err != nil {
+				return err
+			}
+			kv := reflect.New(rv.Type().Key()).Elem()
+			if err := storeMapKey(key, kv); err != nil {
+				return err
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.decode(elem); err != nil {
+				return err
+			}
+			rv.SetMapIndex(kv, elem)
+		}
+		return nil
+	case reflect.Struct:
+		fields := structFields(rv.Type())
+		for i := 0; i < n; i++ {
+			key, err := d.readString()
+			if err != nil {
+				return err
+			}
+			f := findStructField(fields, key)
+			if f == nil {
+				if err := d.skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			field, err := allocFieldByIndex(rv, f.index)
+			if err != nil {
+				return err
+			}
+			if err := d.decode(field); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return d.typeError('o', rv.Type())
+	}
+}
+
+func (d *valueDecoder) decodeMap(rv reflect.Value) error {
+	n, err := d.readUint32()
+	if err != nil {
+		return err
+	}
+	if rv.Kind() != reflect.Map {
+		return d.typeError('m', rv.Type())
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	}
+	for i := 0; i < n; i++ {
+		kv := reflect.New(rv.Type().Key()).Elem()
+		if err := d.decode(kv); err != nil {
+			return err
+		}
+		if !kv.Type().Comparable() || (kv.Kind() == reflect.Interface && !kv.IsNil() && !kv.Elem().Type().Comparable()) {
+			return fmt.Errorf("v8go: can't use a value of type %s as a Go map key", kv.Type())
+		}
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := d.decode(elem); err != nil {
+			return err
+		}
+		rv.SetMapIndex(kv, elem)
+	}
+	return nil
+}
+
+// decodeInterface decodes the next value into the Go type it most naturally converts to.
+func (d *valueDecoder) decodeInterface(x *interface{}) error {
+	tag, err := d.readByte()
+	if err != nil {
+		return err
+	}
+	switch tag {
+	case 'u', 'n':
+		*x = nil
+	case 't', 'f':
+		*x = tag == 't'
+	case 'd':
+		*x, err = d.readFloat64()
+	case 'b':
+		*x, err = d.readBigInt()
+	case 's':
+		*x, err = d.readString()
+	case 'D':
+		*x, err = d.readTime()
+	case 'B':
+		var n int
+		if n, err = d.readUint32(); err == nil {
+			var b []byte
+			b, err = d.take(n)
+			*x = append([]byte{}, b...)
+		}
+	case 'r':
+		*x, err = d.readValue()
+	case 'a':
+		var s []interface{}
+		d.pos--
+		err = d.decode(reflect.ValueOf(&s).Elem())
+		*x = s
+	case 'o':
+		var m map[string]interface{}
+		d.pos--
+		err = d.decode(reflect.ValueOf(&m).Elem())
+		*x = m
+	case 'm':
+		var m map[interface{}]interface{}
+		d.pos--
+		err = d.decode(reflect.ValueOf(&m).Elem())
+		*x = m
+	default:
+		err = errInvalidEncoding
+	}
+	return err
+}
+
+// skip skips over the next value.
+func (d *valueDecoder) skip() error {
+	skipping := d.skipping
+	d.skipping = true
+	defer func() { d.skipping = skipping }()
+	var x interface{}
+	return d.decodeInterface(&x)
+}
+
+func (d *valueDecoder) typeError(tag byte, t reflect.Type) error {
+	kinds := map[byte]string{
+		't': "boolean", 'f': "boolean", 's': "string", 'D': "Date", 'B': "Uint8Array",
+		'a': "array", 'o': "object", 'm': "Map", 'r': "value",
+	}
+	return &UnmarshalTypeError{Value: kinds[tag], Type: t}
+}
+
+func storeMapKey(key string, kv reflect.Value) error {
+	switch kv.Kind() {
+	case reflect.String:
+		kv.SetString(key)
+		return nil
+	case reflect.Interface:
+		if kv.NumMethod() == 0 {
+			kv.Set(reflect.ValueOf(key))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(key, 10, 64); err == nil && !kv.OverflowInt(i) {
+			kv.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(key, 10, 64); err == nil && !kv.OverflowUint(u) {
+			kv.SetUint(u)
+			return nil
+		}
+	}
+	return fmt.Errorf("v8go: can't store the property name %q in a Go map key of type %s", key, kv.Type())
+}
+
+// findStructField returns the field with the given name, preferring an exact match but
+// otherwise accepting a case-insensitive one, as encoding/json does.
+func findStructField(fields []structField, name string) *structField {
+	var fold *structField
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		} else if fold == nil && strings.EqualFold(fields[i].name, name) {
+			fold = &fields[i]
+		}
+	}
+	return fold
+}
+
+// allocFieldByIndex is like reflect.Value.FieldByIndex, but allocates the structs that
+// nil pointers to embedded structs would point to. Like encoding/json, it returns an
+// error if such a pointer is nil and can't be set because its type is unexported.
+func allocFieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("v8go: can't set embedded pointer to unexported struct %s", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+type unmarshalInner struct{ X int }
+
+type unmarshalOuter struct {
+	*unmarshalInner
+	Y int
+}
+
+func TestValueUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`({
+		zed: "z", name: "thing", count: 12, skip: true, tags: ["a", "b"],
+		attrs: {x: 1, y: 1n << 60n}, bytes: new Uint8Array([1, 2, 3]),
+		when: new Date(Date.UTC(2021, 2, 4, 5, 6, 7, 8)), big: -5n, nil: null,
+		js: function() { return 7 }, extra: [1, {a: 2}], ignored: "ignored",
+		ignoredFn: function() {}
+	})`, "")
+	fatalIf(t, err)
+
+	var out valueOfStruct
+	fatalIf(t, val.Unmarshal(&out))
+
+	if out.Z != "z" || out.Name != "thing" || out.Count != 12 || out.Skip {
+		t.Errorf("unexpected scalar fields %+v", out)
+	}
+	if !reflect.DeepEqual(out.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected tags %v", out.Tags)
+	}
+	if !reflect.DeepEqual(out.Attrs, map[string]uint64{"x": 1, "y": 1 << 60}) {
+		t.Errorf("unexpected attrs %v", out.Attrs)
+	}
+	if !reflect.DeepEqual(out.Bytes, []byte{1, 2, 3}) {
+		t.Errorf("unexpected bytes %v", out.Bytes)
+	}
+	if want := time.Date(2021, time.March, 4, 5, 6, 7, 8e6, time.UTC); !out.When.Equal(want) {
+		t.Errorf("expected %v, got %v", want, out.When)
+	}
+	if out.Big == nil || out.Big.Cmp(big.NewInt(-5)) != 0 {
+		t.Errorf("unexpected big %v", out.Big)
+	}
+	if out.Nil != nil {
+		t.Errorf("expected nil, got %v", out.Nil)
+	}
+	if out.JS == nil || !out.JS.IsFunction() {
+		t.Fatalf("expected a function, got %v", out.JS)
+	}
+	fn, _ := out.JS.AsFunction()
+	if res, err := fn.Call(v8.Undefined(ctx.Isolate())); err != nil || res.Int32() != 7 {
+		t.Errorf("unexpected result of calling function: %v, %v", res, err)
+	}
+
+	// Round trip through NewValueOf.
+	val2, err := v8.NewValueOf(ctx, &out)
+	fatalIf(t, err)
+	var out2 valueOfStruct
+	fatalIf(t, val2.Unmarshal(&out2))
+	if out2.Name != out.Name || !out2.When.Equal(out.When) || out2.Attrs["y"] != out.Attrs["y"] {
+		t.Errorf("round trip failed: %+v", out2)
+	}
+
+	var generic interface{}
+	val, err = ctx.RunScript(`({a: [1, "x", true, null], m: new Map([[1, "one"]]), s: new Set([2])})`, "")
+	fatalIf(t, err)
+	fatalIf(t, val.Unmarshal(&generic))
+	expected := map[string]interface{}{
+		"a": []interface{}{float64(1), "x", true, nil},
+		"m": map[interface{}]interface{}{float64(1): "one"},
+		"s": []interface{}{float64(2)},
+	}
+	if !reflect.DeepEqual(generic, expected) {
+		t.Errorf("expected %#v, got %#v", expected, generic)
+	}
+
+	var ints map[int][]int8
+	val, err = ctx.RunScript(`({1: [1, 2], 20: []})`, "")
+	fatalIf(t, err)
+	fatalIf(t, val.Unmarshal(&ints))
+	if !reflect.DeepEqual(ints, map[int][]int8{1: {1, 2}, 20: {}}) {
+		t.Errorf("unexpected map %v", ints)
+	}
+
+	errorTests := [...]struct {
+		script string
+		target interface{}
+	}{
+		{"'str'", new(int)},
+		{"1.5", new(int)},
+		{"300", new(int8)},
+		{"-1", new(uint)},
+		{"[1]", new(map[string]int)},
+		{"({a: 1})", new([]int)},
+		{"(function() {})", new(string)},
+		{"({get a() { throw new Error('oops') }})", new(map[string]int)},
+		{"let o = {}; o.o = o; o", new(interface{})},
+		{"({X: 1, Y: 2})", new(unmarshalOuter)},
+	}
+	for _, tt := range errorTests {
+		val, err := ctx.RunScript(tt.script, "")
+		fatalIf(t, err)
+		if err := val.Unmarshal(tt.target); err == nil {
+			t.Errorf("%s: expected an error unmarshaling into %T", tt.script, tt.target)
+		}
+	}
+	if err := val.Unmarshal(out); err == nil {
+		t.Error("expected an error for a non-pointer target")
+	}
+}